@@ -16,7 +16,9 @@ import (
 	"github.com/pushp314/devconnect-backend/internal/migrations"
 	"github.com/pushp314/devconnect-backend/internal/models"
 	"github.com/pushp314/devconnect-backend/internal/routes"
+	"github.com/pushp314/devconnect-backend/internal/services"
 	"github.com/pushp314/devconnect-backend/pkg/logger"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -82,11 +84,14 @@ func main() {
 		&models.RolePermission{},
 		&models.Playlist{},
 		&models.PlaylistSnippet{},
+		&models.PlaylistProgress{},
 		&models.UserLink{},
 		&models.SnippetReaction{},
 		&models.Comment{},
 		&models.Badge{},
 		&models.UserBadge{},
+		&models.UserMetric{},
+		&models.BadgeEvent{},
 		&models.LinkRequest{},
 		&models.UserBlock{},
 		&models.Report{},
@@ -96,6 +101,23 @@ func main() {
 		&models.Mention{},
 		&models.ShortLink{},
 		&models.UserActivity{},
+		&models.JudgeJob{},
+		&models.Language{},
+		&models.SchedulerLease{},
+		&models.ContestResult{},
+		&models.RatingHistory{},
+		&models.Team{},
+		&models.TeamMember{},
+		&models.TeamInvite{},
+		&models.VirtualParticipation{},
+		&models.PlagiarismReport{},
+		&models.AntiCheatRule{},
+		&models.EventAntiCheatRule{},
+		&models.XPLedgerEntry{},
+		&models.StoreItem{},
+		&models.DailyProblem{},
+		&models.DailySolve{},
+		&models.DailyStreak{},
 	}
 
 	for _, m := range tableModels {
@@ -130,6 +152,8 @@ func main() {
 		models.SettingFeatureSidebarRoadmaps:      "true",
 		models.SettingFeatureSidebarTrophyRoom:    "true",
 		models.SettingFeatureSidebarXPStore:       "true",
+		models.SettingFeatureStoreThemes:          "true",
+		models.SettingFeatureStorePowerups:        "true",
 	}
 	for k, v := range defaultSettings {
 		var count int64
@@ -144,11 +168,71 @@ func main() {
 		}
 	}
 
+	// Language registry: seeded from the built-in defaults on first boot
+	if err := services.LoadLanguages(database.DB); err != nil {
+		logger.Error().Err(err).Msg("Failed to load language registry, using built-in defaults")
+	}
+
+	// Anti-cheat rules: seeded with the built-in heuristics on first boot
+	if err := services.SeedAntiCheatRules(database.DB); err != nil {
+		logger.Error().Err(err).Msg("Failed to seed anti-cheat rules")
+	}
+
+	// Playlist progress: carry over snippet views from before progress was stored
+	if err := services.BackfillPlaylistProgress(database.DB); err != nil {
+		logger.Error().Err(err).Msg("Failed to backfill playlist progress")
+	}
+
+	// XP ledger: open an entry for balances earned before the ledger existed
+	if err := services.BackfillXPLedger(database.DB); err != nil {
+		logger.Error().Err(err).Msg("Failed to backfill XP ledger")
+	}
+
+	// Badges: legacy condition strings become rules, metrics are counted once
+	if err := services.MigrateLegacyBadges(database.DB); err != nil {
+		logger.Error().Err(err).Msg("Failed to migrate legacy badges")
+	}
+	if err := services.BackfillBadgeMetrics(database.DB); err != nil {
+		logger.Error().Err(err).Msg("Failed to backfill badge metrics")
+	}
+
+	// Store catalog: keep items bought before the catalog existed equippable
+	if err := services.BackfillStoreCatalog(database.DB); err != nil {
+		logger.Error().Err(err).Msg("Failed to backfill store catalog")
+	}
+	if err := services.SeedStoreCatalog(database.DB); err != nil {
+		logger.Error().Err(err).Msg("Failed to seed store catalog")
+	}
+
 	logger.Info().Msg("✅ Database Migrations Complete")
 
 	// 3. Init OAuth
 	handlers.InitOAuthConfig()
 
+	redisUp := database.Redis != nil && database.Redis.Ping(context.Background()).Err() == nil
+
+	// 3b. Code Execution Backend (Piston / local / fake), results shared across replicas via Redis
+	handlers.SetExecutor(services.NewExecutor(config.AppConfig))
+	services.InitExecutionCache(config.AppConfig.ExecCacheSize, redisUp)
+	executorHealth := services.NewExecutorHealth(handlers.GetExecutor(), 0)
+	executorHealth.Start()
+
+	// 3c. Judge Queue (durable in Postgres, Redis only for cross-replica wake-ups)
+	var queueRedis *redis.Client
+	if redisUp {
+		queueRedis = database.Redis
+	}
+	judgeQueue := services.NewJudgeQueue(database.DB, queueRedis, services.JudgeQueueOptions{
+		Workers: config.AppConfig.JudgeWorkers,
+	})
+	handlers.InitJudgeQueue(judgeQueue)
+	judgeQueue.Start()
+
+	// 3d. Contest Scheduler (lease-elected: one replica fires start/freeze/end transitions)
+	contestScheduler := services.NewContestScheduler(database.DB, services.ContestSchedulerOptions{})
+	handlers.InitContestScheduler(contestScheduler)
+	contestScheduler.Start()
+
 	// 4. Setup Router
 	r := gin.Default()
 
@@ -180,6 +264,7 @@ func main() {
 		api.GET("/system/status", handlers.PublicGetSystemStatus)
 		api.GET("/system/admins", handlers.PublicGetAdmins)
 		api.GET("/landing/stats", handlers.PublicGetLandingStats)
+		api.GET("/runtimes", handlers.GetRuntimes)
 		routes.RegisterShortenerAPIRoutes(api)
 
 		// Protected routes - apply maintenance mode check
@@ -192,6 +277,7 @@ func main() {
 		routes.RegisterUploadRoutes(protected)
 		routes.RegisterRegistrationRoutes(protected)
 		routes.RegisterPaymentRoutes(protected)
+		routes.RegisterTeamRoutes(protected)
 		routes.RegisterChatRoutes(protected)
 		routes.SetupChangelogRoutes(api)         // Public changelog - no maintenance check
 		routes.RegisterAdminRoutes(api)          // Admin routes bypass maintenance
@@ -200,6 +286,7 @@ func main() {
 		routes.RegisterPlaylistRoutes(protected) // v1.3: Playlist Tracks
 		routes.RegisterSocialRoutes(protected)   // v1.3: Social Graph (Link/Unlink)
 		routes.RegisterNotificationRoutes(protected)
+		routes.RegisterStoreRoutes(protected)
 		protected.GET("/activity/feed", handlers.GetActivityFeed)
 	}
 
@@ -223,8 +310,14 @@ func main() {
 			redisStatus = "not configured"
 		}
 
+		// Code execution backend, as of its last background check
+		executorStatus := "ok"
+		if _, err := executorHealth.Status(); err != nil {
+			executorStatus = "error"
+		}
+
 		status := "ok"
-		if dbStatus != "ok" || (redisStatus != "ok" && redisStatus != "not configured") {
+		if dbStatus != "ok" || executorStatus != "ok" || (redisStatus != "ok" && redisStatus != "not configured") {
 			status = "degraded"
 		}
 
@@ -234,6 +327,7 @@ func main() {
 			"checks": gin.H{
 				"database": dbStatus,
 				"redis":    redisStatus,
+				"executor": executorStatus,
 			},
 		})
 	})
@@ -291,5 +385,10 @@ func main() {
 		logger.Fatal().Err(err).Msg("Server forced to shutdown")
 	}
 
+	// Let in-flight judges finish; anything left is recovered on next start
+	judgeQueue.Stop(ctx)
+	contestScheduler.Stop(ctx)
+	executorHealth.Stop()
+
 	logger.Info().Msg("✅ Server exited gracefully")
 }
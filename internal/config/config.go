@@ -30,6 +30,14 @@ type Config struct {
 	R2SecretAccessKey string `mapstructure:"R2_SECRET_ACCESS_KEY"`
 	R2BucketName      string `mapstructure:"R2_BUCKET_NAME"`
 	R2PublicURL       string `mapstructure:"R2_PUBLIC_URL"` // Custom domain
+
+	// Code Execution
+	ExecutorBackend     string `mapstructure:"EXECUTOR_BACKEND"`       // piston (default), local, fake
+	PistonURL           string `mapstructure:"PISTON_URL"`             // Piston API root, defaults to public emkc.org
+	PistonMaxRunTimeout int    `mapstructure:"PISTON_MAX_RUN_TIMEOUT"` // The server's run_timeout (ms), bounds batched judging
+	PistonMaxOutput     int    `mapstructure:"PISTON_MAX_OUTPUT"`      // The server's output_max_size (bytes, default 1024)
+	JudgeWorkers        int    `mapstructure:"JUDGE_WORKERS"`          // Concurrent judge workers (default 4)
+	ExecCacheSize       int    `mapstructure:"EXECUTION_CACHE_SIZE"`   // Max cached execution results per process (default 1000)
 }
 
 var AppConfig *Config
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 	"github.com/lib/pq"
 	"github.com/pushp314/devconnect-backend/internal/database"
 	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
 	"gorm.io/gorm"
 )
 
@@ -38,6 +40,25 @@ func getAdminID(c *gin.Context) string {
 	return val.(string)
 }
 
+// isStaff reports whether the current user is an admin or moderator
+func isStaff(c *gin.Context) bool {
+	userID, exists := c.Get("userId")
+	if !exists {
+		return false
+	}
+	id, _ := userID.(string)
+	return isStaffUser(id)
+}
+
+// isStaffUser is isStaff for callers without a request context (e.g. sockets)
+func isStaffUser(userID string) bool {
+	var user models.User
+	if err := database.DB.Select("id", "role").First(&user, "id = ?", userID).Error; err != nil {
+		return false
+	}
+	return user.Role == models.RoleAdmin || user.Role == models.RoleModerator
+}
+
 // --- Contest Management ---
 // Moved to admin_contest.go
 
@@ -164,7 +185,7 @@ func AdminDisqualifyUser(c *gin.Context) {
 		tx.Model(&models.User{}).Where("id = ?", sub.UserID).Update("trust_score", 0)
 
 		// 3. Mark Registration as DQ (if exists)
-		tx.Model(&models.Registration{}).Where("user_id = ? AND event_id = ?", sub.UserID, sub.EventID).Update("status", "BANNED")
+		tx.Model(&models.Registration{}).Where("user_id = ? AND event_id = ?", sub.UserID, sub.EventID).Update("status", models.RegStatusBanned)
 
 		return logAdminAction(tx, adminID, models.ActionBanUser, sub.UserID, "user", "Banned via Flag Review")
 	})
@@ -221,7 +242,7 @@ func AdminBanContest(c *gin.Context) {
 		// Update registration
 		if err := tx.Model(&models.Registration{}).
 			Where("user_id = ? AND event_id = ?", userID, req.EventID).
-			Update("status", "BANNED").Error; err != nil {
+			Update("status", models.RegStatusBanned).Error; err != nil {
 			return err
 		}
 		return logAdminAction(tx, adminID, models.ActionBanUser, userID, "user", "Banned from contest "+req.EventID)
@@ -429,43 +450,47 @@ func AdminGrantUserXP(c *gin.Context) {
 		return
 	}
 
-	err := database.DB.Transaction(func(tx *gorm.DB) error {
-		var user models.User
-		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
-			return err
-		}
-
-		// Update XP
-		user.XP = int(float64(user.XP) + float64(req.Amount))
-		if user.XP < 0 {
-			user.XP = 0
-		}
-
-		// Recalculate Level
-		user.SyncLevelXP("XP")
+	if req.Amount == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Amount cannot be zero"})
+		return
+	}
 
-		if err := tx.Model(&user).Updates(map[string]interface{}{"xp": user.XP, "level": user.Level}).Error; err != nil {
+	var entry *models.XPLedgerEntry
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Deductions stop at 0 XP
+		var err error
+		entry, err = services.ApplyXP(tx, services.XPChange{
+			UserID:        userID,
+			Amount:        req.Amount,
+			Source:        models.XPSourceAdmin,
+			ReferenceType: "user",
+			ReferenceID:   userID,
+			Reason:        req.Reason,
+			CreatedBy:     adminID,
+			Clamp:         true,
+		})
+		if err != nil {
 			return err
 		}
 
 		// Log Action
-		action := "Granted " + strconv.Itoa(req.Amount) + " XP"
-		if req.Amount < 0 {
-			action = "Deducted " + strconv.Itoa(-req.Amount) + " XP"
+		action := "Granted " + strconv.Itoa(entry.Amount) + " XP"
+		if entry.Amount < 0 {
+			action = "Deducted " + strconv.Itoa(-entry.Amount) + " XP"
 		}
-
-		// Ideally we should also insert into an XP Ledger/Transaction table if we have one.
-		// For now, Admin Action Log is sufficient for audit.
-
 		return logAdminAction(tx, adminID, models.ActionUpdateUser, userID, "user", action+": "+req.Reason)
 	})
 
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "XP updated successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "XP updated successfully", "entry": entry})
 }
 
 // ============================================
@@ -841,13 +866,27 @@ func AdminUpdateUser(c *gin.Context) {
 			}
 		}
 
-		// Handle XP/Level Sync
+		// Handle XP/Level Sync: the difference goes through the ledger
+		target := user
 		if req.Level != user.Level {
-			user.Level = req.Level
-			user.SyncLevelXP("Level")
+			target.Level = req.Level
+			target.SyncLevelXP("Level", services.LevelCurveFromSettings(tx))
 		} else if req.XP != user.XP {
-			user.XP = req.XP
-			user.SyncLevelXP("XP")
+			target.XP = max(req.XP, 0)
+		}
+		if target.XP != user.XP {
+			if _, err := services.ApplyXP(tx, services.XPChange{
+				UserID:        user.ID,
+				Amount:        target.XP - user.XP,
+				Source:        models.XPSourceAdmin,
+				ReferenceType: "user",
+				ReferenceID:   user.ID,
+				Reason:        "Edited by admin",
+				CreatedBy:     adminID,
+				Clamp:         true,
+			}); err != nil {
+				return err
+			}
 		}
 
 		updates := map[string]interface{}{
@@ -858,8 +897,6 @@ func AdminUpdateUser(c *gin.Context) {
 			"role":         models.Role(req.Role),
 			"trust_score":  req.TrustScore,
 			"is_blocked":   req.IsBlocked,
-			"xp":           user.XP,
-			"level":        user.Level,
 			"equippedAura": req.EquippedAura,
 			"endorsements": req.Endorsements,
 		}
@@ -1032,11 +1069,18 @@ func AdminUpdateSystemSettings(c *gin.Context) {
 		models.SettingFeatureStoreThemes:          true,
 		models.SettingDockBadges:                  true,
 		models.SettingCustomAuras:                 true,
+		models.SettingXPLevelCurve:                true,
 	}
 	if !validKeys[req.Key] {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid setting key"})
 		return
 	}
+	if req.Key == models.SettingXPLevelCurve {
+		if _, err := services.ParseLevelCurve(req.Value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid level curve: " + err.Error()})
+			return
+		}
+	}
 
 	setting := models.SystemSettings{
 		Key:       req.Key,
@@ -1059,6 +1103,14 @@ func AdminUpdateSystemSettings(c *gin.Context) {
 		SocketServer.BroadcastToRoom("/", "", "maintenance_toggle", gin.H{"enabled": req.Value == "true"})
 	}
 
+	// A new level curve re-levels everyone
+	if req.Key == models.SettingXPLevelCurve {
+		if _, err := services.RecomputeLevels(database.DB); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Setting saved but levels failed to update: " + err.Error()})
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Setting updated", "setting": setting})
 }
 
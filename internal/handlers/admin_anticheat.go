@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// antiCheatRuleInput is the editable part of a rule; nil/empty fields are left unchanged
+type antiCheatRuleInput struct {
+	Name         string                    `json:"name"`
+	Condition    models.AntiCheatCondition `json:"condition"`
+	Operator     models.AntiCheatOperator  `json:"operator"`
+	Threshold    *float64                  `json:"threshold"`
+	FlagType     models.SubmissionFlagType `json:"flagType"`
+	TrustPenalty *int                      `json:"trustPenalty"`
+	Enabled      *bool                     `json:"enabled"`
+	DryRun       *bool                     `json:"dryRun"`
+}
+
+func (in *antiCheatRuleInput) validate() error {
+	if in.Condition != "" && !slices.Contains(models.AntiCheatConditions, in.Condition) {
+		return fmt.Errorf("unknown condition %q", in.Condition)
+	}
+	switch in.Operator {
+	case "", models.OperatorGT, models.OperatorGTE, models.OperatorLT, models.OperatorLTE:
+	default:
+		return fmt.Errorf("unknown operator %q", in.Operator)
+	}
+	switch in.FlagType {
+	case "", models.FlagTypePaste, models.FlagTypeBlur, models.FlagTypeHash, models.FlagTypeSuspicious:
+	default:
+		return fmt.Errorf("unknown flag type %q", in.FlagType)
+	}
+	if in.TrustPenalty != nil && (*in.TrustPenalty < 0 || *in.TrustPenalty > 100) {
+		return errors.New("trustPenalty must be between 0 and 100")
+	}
+	return nil
+}
+
+// AdminListAntiCheatRules handles GET /admin/anticheat/rules
+func AdminListAntiCheatRules(c *gin.Context) {
+	var rules []models.AntiCheatRule
+	if err := database.DB.Order("created_at asc, id asc").Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rules"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rules": rules, "conditions": models.AntiCheatConditions})
+}
+
+// AdminCreateAntiCheatRule handles POST /admin/anticheat/rules
+func AdminCreateAntiCheatRule(c *gin.Context) {
+	adminID := getAdminID(c)
+
+	var req struct {
+		ID string `json:"id" binding:"required"`
+		antiCheatRuleInput
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Condition == "" || req.Threshold == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "condition and threshold are required"})
+		return
+	}
+
+	rule := models.AntiCheatRule{
+		ID:        strings.ToLower(strings.TrimSpace(req.ID)),
+		Name:      req.Name,
+		Condition: req.Condition,
+		Operator:  req.Operator,
+		Threshold: *req.Threshold,
+		FlagType:  req.FlagType,
+		Enabled:   true,
+		DryRun:    true, // New rules prove themselves before they penalize anyone
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if rule.Name == "" {
+		rule.Name = string(rule.Condition)
+	}
+	if rule.Operator == "" {
+		rule.Operator = models.OperatorGT
+	}
+	if rule.FlagType == "" {
+		rule.FlagType = models.FlagTypeSuspicious
+	}
+	if req.TrustPenalty != nil {
+		rule.TrustPenalty = *req.TrustPenalty
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if req.DryRun != nil {
+		rule.DryRun = *req.DryRun
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		tx.Model(&models.AntiCheatRule{}).Where("id = ?", rule.ID).Count(&existing)
+		if existing > 0 {
+			return gorm.ErrDuplicatedKey
+		}
+		if err := tx.Create(&rule).Error; err != nil {
+			return err
+		}
+		return logAdminAction(tx, adminID, models.ActionCreateRule, rule.ID, "anticheat_rule", "Created Anti-Cheat Rule")
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Rule already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"rule": rule})
+}
+
+// AdminUpdateAntiCheatRule handles PUT /admin/anticheat/rules/:id
+func AdminUpdateAntiCheatRule(c *gin.Context) {
+	id := c.Param("id")
+	adminID := getAdminID(c)
+
+	var req antiCheatRuleInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var rule models.AntiCheatRule
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&rule, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		if req.Name != "" {
+			updates["name"] = req.Name
+		}
+		if req.Condition != "" {
+			updates["condition"] = req.Condition
+		}
+		if req.Operator != "" {
+			updates["operator"] = req.Operator
+		}
+		if req.Threshold != nil {
+			updates["threshold"] = *req.Threshold
+		}
+		if req.FlagType != "" {
+			updates["flag_type"] = req.FlagType
+		}
+		if req.TrustPenalty != nil {
+			updates["trust_penalty"] = *req.TrustPenalty
+		}
+		if req.Enabled != nil {
+			updates["enabled"] = *req.Enabled
+		}
+		if req.DryRun != nil {
+			updates["dry_run"] = *req.DryRun
+		}
+		updates["updated_at"] = time.Now()
+
+		if err := tx.Model(&rule).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&rule, "id = ?", id).Error; err != nil {
+			return err
+		}
+		return logAdminAction(tx, adminID, models.ActionUpdateRule, id, "anticheat_rule",
+			fmt.Sprintf("%s %s %g, penalty %d, enabled=%t, dryRun=%t", rule.Condition, rule.Operator, rule.Threshold, rule.TrustPenalty, rule.Enabled, rule.DryRun))
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rule not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rule": rule})
+}
+
+// AdminDeleteAntiCheatRule handles DELETE /admin/anticheat/rules/:id, along with the
+// events' overrides of it. Results already recorded on submissions are kept.
+func AdminDeleteAntiCheatRule(c *gin.Context) {
+	id := c.Param("id")
+	adminID := getAdminID(c)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.AntiCheatRule{}, "id = ?", id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Where("rule_id = ?", id).Delete(&models.EventAntiCheatRule{}).Error; err != nil {
+			return err
+		}
+		return logAdminAction(tx, adminID, models.ActionDeleteRule, id, "anticheat_rule", "Deleted Anti-Cheat Rule")
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rule not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rule Deleted"})
+}
+
+// AdminGetEventAntiCheat handles GET /admin/contests/:id/anticheat: every rule as it
+// applies to the contest, marking the ones the contest overrides
+func AdminGetEventAntiCheat(c *gin.Context) {
+	eventID := c.Param("id")
+
+	var rules []models.AntiCheatRule
+	if err := database.DB.Order("created_at asc, id asc").Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rules"})
+		return
+	}
+	var overrides []models.EventAntiCheatRule
+	database.DB.Where("event_id = ?", eventID).Find(&overrides)
+	byRule := make(map[string]models.EventAntiCheatRule, len(overrides))
+	for _, o := range overrides {
+		byRule[o.RuleID] = o
+	}
+
+	type eventRule struct {
+		models.AntiCheatRule
+		Overridden bool `json:"overridden"`
+	}
+	result := make([]eventRule, len(rules))
+	for i, rule := range rules {
+		o, ok := byRule[rule.ID]
+		if ok {
+			rule.Enabled, rule.DryRun = o.Enabled, o.DryRun
+		}
+		result[i] = eventRule{AntiCheatRule: rule, Overridden: ok}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"eventId": eventID, "rules": result})
+}
+
+// AdminSetEventAntiCheat handles PUT /admin/contests/:id/anticheat/:ruleId, enabling,
+// disabling or dry-running one rule for this contest only
+func AdminSetEventAntiCheat(c *gin.Context) {
+	eventID, ruleID := c.Param("id"), c.Param("ruleId")
+	adminID := getAdminID(c)
+
+	var req struct {
+		Enabled *bool `json:"enabled"`
+		DryRun  *bool `json:"dryRun"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var event models.Event
+	if err := database.DB.Select("id").First(&event, "id = ?", eventID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+	var rule models.AntiCheatRule
+	if err := database.DB.First(&rule, "id = ?", ruleID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rule not found"})
+		return
+	}
+
+	// Unset fields keep the current override, else the rule's own setting
+	override := models.EventAntiCheatRule{EventID: eventID, RuleID: ruleID, Enabled: rule.Enabled, DryRun: rule.DryRun}
+	database.DB.Where("event_id = ? AND rule_id = ?", eventID, ruleID).First(&override)
+	if req.Enabled != nil {
+		override.Enabled = *req.Enabled
+	}
+	if req.DryRun != nil {
+		override.DryRun = *req.DryRun
+	}
+	override.UpdatedAt = time.Now()
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "event_id"}, {Name: "rule_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "dry_run", "updated_at"}),
+		}).Create(&override).Error; err != nil {
+			return err
+		}
+		return logAdminAction(tx, adminID, models.ActionUpdateRule, eventID, "contest",
+			fmt.Sprintf("Rule %s: enabled=%t, dryRun=%t", ruleID, override.Enabled, override.DryRun))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"override": override})
+}
+
+// AdminResetEventAntiCheat handles DELETE /admin/contests/:id/anticheat/:ruleId: the
+// contest goes back to the rule's own settings
+func AdminResetEventAntiCheat(c *gin.Context) {
+	eventID, ruleID := c.Param("id"), c.Param("ruleId")
+	adminID := getAdminID(c)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("event_id = ? AND rule_id = ?", eventID, ruleID).Delete(&models.EventAntiCheatRule{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return logAdminAction(tx, adminID, models.ActionUpdateRule, eventID, "contest", "Reset rule "+ruleID)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No override for this rule"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Override removed"})
+}
@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// badgeInput is the editable part of a badge; nil/empty fields are left unchanged
+type badgeInput struct {
+	Name        string                 `json:"name"`
+	Description *string                `json:"description"`
+	Icon        string                 `json:"icon"`
+	Category    models.BadgeCategory   `json:"category"`
+	Type        models.BadgeType       `json:"type"`
+	ModelPath   *string                `json:"modelPath"`
+	Metric      models.BadgeMetric     `json:"metric"`
+	Comparator  models.BadgeComparator `json:"comparator"`
+	Threshold   *int                   `json:"threshold"`
+	WindowDays  *int                   `json:"windowDays"`
+}
+
+func (in *badgeInput) validate() error {
+	if in.Metric != "" && !slices.Contains(models.BadgeMetrics, in.Metric) {
+		return fmt.Errorf("unknown metric %q", in.Metric)
+	}
+	switch in.Comparator {
+	case "", models.ComparatorGTE, models.ComparatorLTE:
+	default:
+		return fmt.Errorf("unknown comparator %q", in.Comparator)
+	}
+	switch in.Category {
+	case "", models.BadgeCategorySystem, models.BadgeCategorySkill, models.BadgeCategoryTrust:
+	default:
+		return fmt.Errorf("unknown category %q", in.Category)
+	}
+	switch in.Type {
+	case "", models.BadgeType2D, models.BadgeType3D:
+	default:
+		return fmt.Errorf("unknown type %q", in.Type)
+	}
+	if in.Threshold != nil && *in.Threshold < 0 {
+		return errors.New("threshold cannot be negative")
+	}
+	if in.WindowDays != nil && *in.WindowDays < 0 {
+		return errors.New("windowDays cannot be negative")
+	}
+	return nil
+}
+
+// checkBadgeRule validates the rule a badge ends up with after an edit
+func checkBadgeRule(b *models.Badge) error {
+	if b.WindowDays > 0 && !b.Metric.Windowable() {
+		return fmt.Errorf("%s can't be limited to a time window", b.Metric)
+	}
+	if b.Metric.Ranked() && b.Comparator != models.ComparatorLTE {
+		return fmt.Errorf("%s is a rank, lower is better: use LTE", b.Metric)
+	}
+	return nil
+}
+
+// AdminListBadges handles GET /admin/badges
+func AdminListBadges(c *gin.Context) {
+	var badges []models.Badge
+	if err := database.DB.Order("metric asc, threshold asc, id asc").Find(&badges).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch badges"})
+		return
+	}
+
+	type badgeStats struct {
+		BadgeID  string
+		Unlocked int64
+	}
+	var stats []badgeStats
+	database.DB.Model(&models.UserBadge{}).Select("badge_id, COUNT(*) AS unlocked").
+		Where("unlocked_at IS NOT NULL").Group("badge_id").Scan(&stats)
+	unlocked := make(map[string]int64, len(stats))
+	for _, s := range stats {
+		unlocked[s.BadgeID] = s.Unlocked
+	}
+
+	type adminBadge struct {
+		models.Badge
+		Unlocked int64 `json:"unlocked"`
+	}
+	result := make([]adminBadge, len(badges))
+	for i, b := range badges {
+		result[i] = adminBadge{Badge: b, Unlocked: unlocked[b.ID]}
+	}
+	c.JSON(http.StatusOK, gin.H{"badges": result, "metrics": models.BadgeMetrics})
+}
+
+// AdminCreateBadge handles POST /admin/badges. Users' progress on the new badge is
+// filled in from their metrics on their next event or badge view.
+func AdminCreateBadge(c *gin.Context) {
+	adminID := getAdminID(c)
+
+	var req struct {
+		ID string `json:"id" binding:"required"`
+		badgeInput
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Name == "" || req.Metric == "" || req.Threshold == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name, metric and threshold are required"})
+		return
+	}
+
+	badge := models.Badge{
+		ID:         strings.ToLower(strings.TrimSpace(req.ID)),
+		Name:       req.Name,
+		Icon:       req.Icon,
+		Category:   req.Category,
+		Type:       req.Type,
+		Metric:     req.Metric,
+		Comparator: req.Comparator,
+		Threshold:  *req.Threshold,
+	}
+	if req.Description != nil {
+		badge.Description = *req.Description
+	}
+	if req.ModelPath != nil {
+		badge.ModelPath = *req.ModelPath
+	}
+	if req.WindowDays != nil {
+		badge.WindowDays = *req.WindowDays
+	}
+	if badge.Category == "" {
+		badge.Category = models.BadgeCategorySystem
+	}
+	if badge.Type == "" {
+		badge.Type = models.BadgeType2D
+	}
+	if badge.Comparator == "" {
+		badge.Comparator = models.ComparatorGTE
+		if badge.Metric.Ranked() {
+			badge.Comparator = models.ComparatorLTE
+		}
+	}
+	if err := checkBadgeRule(&badge); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		tx.Model(&models.Badge{}).Where("id = ?", badge.ID).Count(&existing)
+		if existing > 0 {
+			return gorm.ErrDuplicatedKey
+		}
+		if err := tx.Create(&badge).Error; err != nil {
+			return err
+		}
+		return logAdminAction(tx, adminID, models.ActionCreateBadge, badge.ID, "badge",
+			fmt.Sprintf("%s %s %d over %d days", badge.Metric, badge.Comparator, badge.Threshold, badge.WindowDays))
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Badge already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"badge": badge})
+}
+
+// AdminUpdateBadge handles PUT /admin/badges/:id. Badges already unlocked stay
+// unlocked when the rule changes; progress towards it is re-evaluated lazily.
+func AdminUpdateBadge(c *gin.Context) {
+	id := c.Param("id")
+	adminID := getAdminID(c)
+
+	var req badgeInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var badge models.Badge
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&badge, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		if req.Name != "" {
+			updates["name"] = req.Name
+		}
+		if req.Description != nil {
+			updates["description"] = *req.Description
+		}
+		if req.Icon != "" {
+			updates["icon"] = req.Icon
+		}
+		if req.Category != "" {
+			updates["category"] = req.Category
+		}
+		if req.Type != "" {
+			updates["type"] = req.Type
+		}
+		if req.ModelPath != nil {
+			updates["model_path"] = *req.ModelPath
+		}
+		if req.Metric != "" {
+			updates["metric"] = req.Metric
+		}
+		if req.Comparator != "" {
+			updates["comparator"] = req.Comparator
+		}
+		if req.Threshold != nil {
+			updates["threshold"] = *req.Threshold
+		}
+		if req.WindowDays != nil {
+			updates["window_days"] = *req.WindowDays
+		}
+
+		if err := tx.Model(&badge).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&badge, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := checkBadgeRule(&badge); err != nil {
+			return &badgeRuleError{err}
+		}
+		return logAdminAction(tx, adminID, models.ActionUpdateBadge, id, "badge",
+			fmt.Sprintf("%s %s %d over %d days", badge.Metric, badge.Comparator, badge.Threshold, badge.WindowDays))
+	})
+	var ruleErr *badgeRuleError
+	if errors.As(err, &ruleErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": ruleErr.Error()})
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Badge not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"badge": badge})
+}
+
+// badgeRuleError rolls back an edit that leaves a badge with an invalid rule
+type badgeRuleError struct{ error }
+
+// AdminDeleteBadge handles DELETE /admin/badges/:id, along with users' progress on it
+func AdminDeleteBadge(c *gin.Context) {
+	id := c.Param("id")
+	adminID := getAdminID(c)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("badge_id = ?", id).Delete(&models.UserBadge{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&models.Badge{}, "id = ?", id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return logAdminAction(tx, adminID, models.ActionDeleteBadge, id, "badge", "Deleted Badge")
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Badge not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Badge Deleted"})
+}
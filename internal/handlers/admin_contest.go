@@ -1,19 +1,38 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/pushp314/devconnect-backend/internal/database"
 	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
 	"github.com/pushp314/devconnect-backend/pkg/utils"
 	"gorm.io/gorm"
 )
 
 // --- Contest Management ---
 
+// contestScheduler fires scheduled status transitions; see InitContestScheduler
+var contestScheduler *services.ContestScheduler
+
+// InitContestScheduler makes the scheduler available to the contest admin handlers,
+// which wake it whenever a contest's times or status change
+func InitContestScheduler(s *services.ContestScheduler) {
+	contestScheduler = s
+}
+
+// wakeContestScheduler re-plans the scheduler after an admin edit (no-op without one)
+func wakeContestScheduler() {
+	if contestScheduler != nil {
+		contestScheduler.Wake()
+	}
+}
+
 func AdminListContests(c *gin.Context) {
 	var events []models.Event
 	// Order by most recent first
@@ -41,6 +60,16 @@ func AdminCreateContest(c *gin.Context) {
 		Platform    string     `json:"platform"`
 		JoinURL     string     `json:"joinUrl"`
 		VisibleAt   *time.Time `json:"visibleAt"`
+
+		AllowedLanguages []string           `json:"allowedLanguages"` // Empty = every enabled language
+		ScoringMode      models.ScoringMode `json:"scoringMode"`      // ICPC (default), IOI or CODEFORCES
+
+		Rated     bool `json:"rated"`
+		RatingMin *int `json:"ratingMin"` // Eligible rating range, inclusive; omit for open
+		RatingMax *int `json:"ratingMax"`
+
+		TeamSizeMin int `json:"teamSizeMin"`
+		TeamSizeMax int `json:"teamSizeMax"` // 0 = individual contest
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -54,6 +83,33 @@ func AdminCreateContest(c *gin.Context) {
 		return
 	}
 
+	allowedLanguages, err := normalizeAllowedLanguages(req.AllowedLanguages)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.ScoringMode == "" {
+		req.ScoringMode = models.ScoringICPC
+	}
+	if !req.ScoringMode.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scoringMode must be ICPC, IOI or CODEFORCES"})
+		return
+	}
+
+	if err := validateRatingRange(req.RatingMin, req.RatingMax); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.TeamSizeMax > 0 && req.TeamSizeMin == 0 {
+		req.TeamSizeMin = 1
+	}
+	if err := validateTeamSize(req.TeamSizeMin, req.TeamSizeMax); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	event := models.Event{
 		ID:               uuid.New().String(),
 		Title:            req.Title,
@@ -71,10 +127,22 @@ func AdminCreateContest(c *gin.Context) {
 		IsExternal:       req.IsExternal,
 		ExternalPlatform: req.Platform,
 		ExternalJoinURL:  req.JoinURL,
+		AllowedLanguages: allowedLanguages,
+		ScoringMode:      req.ScoringMode,
+		Rated:            req.Rated,
+		TeamSizeMin:      req.TeamSizeMin,
+		TeamSizeMax:      req.TeamSizeMax,
 		CreatedAt:        time.Now(),
 		UpdatedAt:        time.Now(),
 	}
 
+	if req.RatingMin != nil && *req.RatingMin > 0 {
+		event.RatingMin = req.RatingMin
+	}
+	if req.RatingMax != nil && *req.RatingMax > 0 {
+		event.RatingMax = req.RatingMax
+	}
+
 	// Auto-calculate visible time if not provided
 	if req.VisibleAt == nil {
 		event.ExternalJoinVisibleAt = req.StartTime.Add(-15 * time.Minute)
@@ -92,10 +160,36 @@ func AdminCreateContest(c *gin.Context) {
 	}
 
 	logAdminAction(database.DB, adminID, models.ActionCreateContest, event.ID, "contest", "Created Contest: "+event.Title)
+	wakeContestScheduler()
 
 	c.JSON(http.StatusCreated, gin.H{"contest": event})
 }
 
+// validateRatingRange checks an eligibility range; 0 means "no bound" on update
+func validateRatingRange(lo, hi *int) error {
+	if (lo != nil && *lo < 0) || (hi != nil && *hi < 0) {
+		return errors.New("rating bounds must be positive")
+	}
+	if lo != nil && hi != nil && *lo > 0 && *hi > 0 && *lo > *hi {
+		return errors.New("ratingMin must not exceed ratingMax")
+	}
+	return nil
+}
+
+// validateTeamSize checks team size limits; a max of 0 makes an individual contest
+func validateTeamSize(lo, hi int) error {
+	if lo < 0 || hi < 0 {
+		return errors.New("team sizes must be positive")
+	}
+	if hi > 0 && (lo < 1 || lo > hi) {
+		return errors.New("teamSizeMin must be between 1 and teamSizeMax")
+	}
+	if hi == 0 && lo > 0 {
+		return errors.New("teamSizeMax is required for team contests")
+	}
+	return nil
+}
+
 func AdminUpdateContest(c *gin.Context) {
 	eventID := c.Param("id")
 	adminID := getAdminID(c)
@@ -114,6 +208,16 @@ func AdminUpdateContest(c *gin.Context) {
 		Platform    string     `json:"platform"`
 		JoinURL     string     `json:"joinUrl"`
 		VisibleAt   *time.Time `json:"visibleAt"`
+
+		AllowedLanguages []string           `json:"allowedLanguages"` // Omit to keep, [] to allow all
+		ScoringMode      models.ScoringMode `json:"scoringMode"`
+
+		Rated     *bool `json:"rated"`
+		RatingMin *int  `json:"ratingMin"` // Omit to keep, 0 to remove the bound
+		RatingMax *int  `json:"ratingMax"`
+
+		TeamSizeMin *int `json:"teamSizeMin"` // Omit to keep
+		TeamSizeMax *int `json:"teamSizeMax"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -121,6 +225,25 @@ func AdminUpdateContest(c *gin.Context) {
 		return
 	}
 
+	if req.ScoringMode != "" && !req.ScoringMode.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scoringMode must be ICPC, IOI or CODEFORCES"})
+		return
+	}
+
+	if err := validateRatingRange(req.RatingMin, req.RatingMax); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var allowedLanguages pq.StringArray
+	if req.AllowedLanguages != nil {
+		var err error
+		if allowedLanguages, err = normalizeAllowedLanguages(req.AllowedLanguages); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	err := database.DB.Transaction(func(tx *gorm.DB) error {
 		var event models.Event
 		if err := tx.First(&event, "id = ?", eventID).Error; err != nil {
@@ -169,6 +292,48 @@ func AdminUpdateContest(c *gin.Context) {
 		if req.VisibleAt != nil {
 			updates["externalJoinVisibleAt"] = *req.VisibleAt
 		}
+		if allowedLanguages != nil {
+			updates["allowed_languages"] = allowedLanguages
+		}
+		if req.ScoringMode != "" {
+			updates["scoring_mode"] = req.ScoringMode
+		}
+		if req.Rated != nil {
+			if event.RatedAt != nil && !*req.Rated {
+				return services.ErrRatingsApplied
+			}
+			updates["rated"] = *req.Rated
+		}
+		if req.TeamSizeMin != nil || req.TeamSizeMax != nil {
+			lo, hi := event.TeamSizeMin, event.TeamSizeMax
+			if req.TeamSizeMin != nil {
+				lo = *req.TeamSizeMin
+			}
+			if req.TeamSizeMax != nil {
+				hi = *req.TeamSizeMax
+			}
+			if err := validateTeamSize(lo, hi); err != nil {
+				return err
+			}
+			// Registrations are per user or per team; they can't be converted
+			if (hi > 0) != event.IsTeamEvent() {
+				var registered int64
+				tx.Model(&models.Registration{}).Where("event_id = ?", eventID).Count(&registered)
+				if registered > 0 {
+					return errors.New("cannot switch between individual and team contest after registrations opened")
+				}
+			}
+			updates["team_size_min"], updates["team_size_max"] = lo, hi
+		}
+		for column, bound := range map[string]*int{"rating_min": req.RatingMin, "rating_max": req.RatingMax} {
+			switch {
+			case bound == nil:
+			case *bound == 0:
+				updates[column] = nil
+			default:
+				updates[column] = *bound
+			}
+		}
 		updates["price"] = req.Price
 		updates["updated_at"] = time.Now()
 
@@ -184,6 +349,7 @@ func AdminUpdateContest(c *gin.Context) {
 		return
 	}
 
+	wakeContestScheduler()
 	c.JSON(200, gin.H{"message": "Contest Updated"})
 }
 
@@ -244,6 +410,8 @@ func AdminStartContest(c *gin.Context) {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	services.InvalidateLeaderboardCache(eventID)
+	wakeContestScheduler()
 	c.JSON(200, gin.H{"message": "Contest Started"})
 }
 
@@ -261,6 +429,7 @@ func AdminFreezeContest(c *gin.Context) {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	services.InvalidateLeaderboardCache(eventID)
 	c.JSON(200, gin.H{"message": "Contest Frozen"})
 }
 
@@ -281,6 +450,8 @@ func AdminEndContest(c *gin.Context) {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	services.InvalidateLeaderboardCache(eventID)
+	wakeContestScheduler() // Runs the post-contest jobs
 	c.JSON(200, gin.H{"message": "Contest Ended"})
 }
 
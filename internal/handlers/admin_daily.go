@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
+	"gorm.io/gorm"
+)
+
+// dailyErrorStatus maps daily calendar errors to HTTP statuses
+func dailyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrDailyDate), errors.Is(err, services.ErrDailyPast):
+		return http.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, services.ErrNoPracticeProblem):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// AdminListDailyProblems handles GET /admin/contests/daily-problems?from=&to=, future
+// days included (defaults to the coming 30 days)
+func AdminListDailyProblems(c *gin.Context) {
+	now := time.Now()
+	from := c.DefaultQuery("from", services.DailyDate(now))
+	to := c.DefaultQuery("to", services.DailyDate(now.AddDate(0, 0, 30)))
+	if _, err := services.ParseDailyDate(from); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := services.ParseDailyDate(to); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	days, err := services.DailyCalendar(database.DB, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch calendar"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"days": days})
+}
+
+// AdminPlanDailyProblem handles PUT /admin/contests/daily-problems/:date
+func AdminPlanDailyProblem(c *gin.Context) {
+	date := c.Param("date")
+	adminID := getAdminID(c)
+
+	var req struct {
+		ProblemID string `json:"problemId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var entry *models.DailyProblem
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if entry, err = services.PlanDailyProblem(tx, date, req.ProblemID, adminID); err != nil {
+			return err
+		}
+		return logAdminAction(tx, adminID, models.ActionPlanDailyProblem, date, "daily_problem", "Planned problem "+req.ProblemID)
+	})
+	if err != nil {
+		c.JSON(dailyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"day": entry})
+}
+
+// AdminUnplanDailyProblem handles DELETE /admin/contests/daily-problems/:date; the day
+// is auto-filled again when it comes
+func AdminUnplanDailyProblem(c *gin.Context) {
+	date := c.Param("date")
+	adminID := getAdminID(c)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.UnplanDailyProblem(tx, date); err != nil {
+			return err
+		}
+		return logAdminAction(tx, adminID, models.ActionUnplanDailyProblem, date, "daily_problem", "Cleared Daily Problem")
+	})
+	if err != nil {
+		c.JSON(dailyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Day cleared"})
+}
+
+// AdminAutoFillDailyProblems handles POST /admin/contests/daily-problems/autofill: fills
+// the empty days among the next ?days= (default 14, max 90) starting today
+func AdminAutoFillDailyProblems(c *gin.Context) {
+	adminID := getAdminID(c)
+
+	var req struct {
+		Days int `json:"days"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Days == 0 {
+		req.Days = 14
+	}
+	if req.Days < 1 || req.Days > 90 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 90"})
+		return
+	}
+
+	filled, err := services.AutoFillDailyCalendar(database.DB, time.Now(), req.Days)
+	if err != nil {
+		c.JSON(dailyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	logAdminAction(database.DB, adminID, models.ActionAutoFillDaily, services.DailyDate(time.Now()), "daily_problem",
+		fmt.Sprintf("Filled %d of the next %d days", filled, req.Days))
+
+	c.JSON(http.StatusOK, gin.H{"filled": filled})
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
+	"gorm.io/gorm"
+)
+
+// PlagiarismDiffLine is one line of a submission in the side-by-side view
+type PlagiarismDiffLine struct {
+	Number  int    `json:"number"`
+	Text    string `json:"text"`
+	Matched bool   `json:"matched"` // Inside a range shared with the other side
+}
+
+// PlagiarismDiffSide is one submission of a report in the side-by-side view
+type PlagiarismDiffSide struct {
+	SubmissionID string                  `json:"submissionId"`
+	UserID       string                  `json:"userId"`
+	Username     string                  `json:"username"`
+	Language     string                  `json:"language"`
+	Status       models.SubmissionStatus `json:"status"`
+	CreatedAt    time.Time               `json:"createdAt"`
+	Flags        []models.SubmissionFlag `json:"flags"`
+	Lines        []PlagiarismDiffLine    `json:"lines"`
+}
+
+// AdminCheckPlagiarism handles POST /admin/contests/:id/plagiarism: an on-demand check
+// (one also runs when the contest is finalized)
+func AdminCheckPlagiarism(c *gin.Context) {
+	eventID := c.Param("id")
+	adminID := getAdminID(c)
+
+	var req struct {
+		Threshold float64 `json:"threshold"` // 0..1, default services.DefaultPlagiarismThreshold
+	}
+	_ = c.ShouldBindJSON(&req)
+	if req.Threshold == 0 {
+		req.Threshold = services.DefaultPlagiarismThreshold
+	}
+	if req.Threshold < 0 || req.Threshold > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "threshold must be between 0 and 1"})
+		return
+	}
+
+	summary, err := services.CheckPlagiarism(database.DB, eventID, req.Threshold)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Plagiarism check failed: " + err.Error()})
+		return
+	}
+
+	services.InvalidateLeaderboardCache(eventID)
+	logAdminAction(database.DB, adminID, models.ActionCheckPlagiarism, eventID, "contest",
+		fmt.Sprintf("Plagiarism check at %.0f%%: %d reports, %d flagged", req.Threshold*100, summary.Reports, summary.Flagged))
+
+	c.JSON(http.StatusOK, gin.H{"summary": summary})
+}
+
+// AdminListPlagiarismReports handles GET /admin/contests/:id/plagiarism?problemId=&flagged=true,
+// most similar pairs first
+func AdminListPlagiarismReports(c *gin.Context) {
+	query := database.DB.Preload("UserA").Preload("UserB").Where("event_id = ?", c.Param("id"))
+	if problemID := c.Query("problemId"); problemID != "" {
+		query = query.Where("problem_id = ?", problemID)
+	}
+	if c.Query("flagged") == "true" {
+		query = query.Where("flagged = ?", true)
+	}
+
+	var reports []models.PlagiarismReport
+	if err := query.Order("similarity desc").Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reports"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reports": reports})
+}
+
+// AdminGetPlagiarismDiff handles GET /admin/plagiarism/:id/diff, both submissions of a
+// report side by side with the shared ranges marked
+func AdminGetPlagiarismDiff(c *gin.Context) {
+	var report models.PlagiarismReport
+	if err := database.DB.First(&report, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		return
+	}
+
+	var subs []models.Submission
+	if err := database.DB.Preload("User").Preload("Flags").
+		Where("id IN ?", []string{report.SubmissionAID, report.SubmissionBID}).
+		Find(&subs).Error; err != nil || len(subs) != 2 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Submissions not found"})
+		return
+	}
+	if subs[0].ID != report.SubmissionAID {
+		subs[0], subs[1] = subs[1], subs[0]
+	}
+
+	aRanges := make([][2]int, 0, len(report.Matches))
+	bRanges := make([][2]int, 0, len(report.Matches))
+	for _, m := range report.Matches {
+		aRanges = append(aRanges, [2]int{m.AStart, m.AEnd})
+		bRanges = append(bRanges, [2]int{m.BStart, m.BEnd})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"report": report,
+		"a":      plagiarismDiffSide(subs[0], aRanges),
+		"b":      plagiarismDiffSide(subs[1], bRanges),
+	})
+}
+
+// plagiarismDiffSide splits a submission into lines, marking those in the shared ranges
+func plagiarismDiffSide(sub models.Submission, ranges [][2]int) PlagiarismDiffSide {
+	text := strings.Split(strings.ReplaceAll(sub.Code, "\r\n", "\n"), "\n")
+	lines := make([]PlagiarismDiffLine, len(text))
+	for i, line := range text {
+		matched := false
+		for _, r := range ranges {
+			if i+1 >= r[0] && i+1 <= r[1] {
+				matched = true
+				break
+			}
+		}
+		lines[i] = PlagiarismDiffLine{Number: i + 1, Text: line, Matched: matched}
+	}
+
+	return PlagiarismDiffSide{
+		SubmissionID: sub.ID,
+		UserID:       sub.UserID,
+		Username:     sub.User.Username,
+		Language:     sub.Language,
+		Status:       sub.Status,
+		CreatedAt:    sub.CreatedAt,
+		Flags:        sub.Flags,
+		Lines:        lines,
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/judge"
 	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
 	"gorm.io/gorm"
 )
 
@@ -32,7 +34,7 @@ func AdminGetPracticeProblem(c *gin.Context) {
 		c.JSON(404, gin.H{"error": "Problem not found"})
 		return
 	}
-	c.JSON(200, gin.H{"problem": problem})
+	c.JSON(200, gin.H{"problem": problem, "checkerCode": problem.CheckerCode})
 }
 
 // AdminCreatePracticeProblem creates a new practice problem
@@ -47,6 +49,7 @@ func AdminCreatePracticeProblem(c *gin.Context) {
 		Solution    string `json:"solutionCode"`
 		TestCases   string `json:"testCases"` // JSON string
 		Language    string `json:"language"`
+		checkerInput
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -54,20 +57,31 @@ func AdminCreatePracticeProblem(c *gin.Context) {
 		return
 	}
 
+	if _, err := judge.PracticeCases(req.TestCases); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	checker, err := req.checkerInput.resolve(models.CheckerConfig{})
+	if err != nil {
+		c.JSON(checkerErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
 	problem := models.PracticeProblem{
-		ID:           uuid.New().String(),
-		Title:        req.Title,
-		Description:  req.Description,
-		Difficulty:   req.Difficulty,
-		Category:     req.Category,
-		StarterCode:  req.StarterCode,
-		SolutionCode: req.Solution, // Only admins see this
-		TestCases:    req.TestCases,
-		Language:     req.Language,
-		TimeLimit:    2.0, // Default
-		MemoryLimit:  128, // Default
-		CreatorID:    adminID,
-		CreatedAt:    time.Now(),
+		ID:            uuid.New().String(),
+		Title:         req.Title,
+		Description:   req.Description,
+		Difficulty:    req.Difficulty,
+		Category:      req.Category,
+		StarterCode:   req.StarterCode,
+		SolutionCode:  req.Solution, // Only admins see this
+		TestCases:     req.TestCases,
+		Language:      req.Language,
+		TimeLimit:     2.0, // Default
+		MemoryLimit:   128, // Default
+		CheckerConfig: checker,
+		CreatorID:     adminID,
+		CreatedAt:     time.Now(),
 	}
 
 	if err := database.DB.Create(&problem).Error; err != nil {
@@ -94,6 +108,7 @@ func AdminUpdatePracticeProblem(c *gin.Context) {
 		TestCases   string `json:"testCases"`
 		Language    string `json:"language"`
 		IsDaily     *bool  `json:"isDailyProblem"`
+		checkerInput
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -101,6 +116,27 @@ func AdminUpdatePracticeProblem(c *gin.Context) {
 		return
 	}
 
+	if _, err := judge.PracticeCases(req.TestCases); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	checker, ok := resolveCheckerUpdate(c, req.checkerInput, &models.PracticeProblem{}, id)
+	if !ok {
+		return
+	}
+	// Users may already have solved today's problem for their streak, so it can't be
+	// taken back; plan another problem for today instead
+	if req.IsDaily != nil && !*req.IsDaily {
+		var featured int64
+		database.DB.Model(&models.DailyProblem{}).
+			Where("date = ? AND problem_id = ?", services.DailyDate(time.Now()), id).
+			Count(&featured)
+		if featured > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "This is today's daily problem; plan another problem for today to replace it"})
+			return
+		}
+	}
+
 	err := database.DB.Transaction(func(tx *gorm.DB) error {
 		var problem models.PracticeProblem
 		if err := tx.First(&problem, "id = ?", id).Error; err != nil {
@@ -132,8 +168,14 @@ func AdminUpdatePracticeProblem(c *gin.Context) {
 		if req.Language != "" {
 			updates["language"] = req.Language
 		}
-		if req.IsDaily != nil {
-			updates["is_daily_problem"] = *req.IsDaily
+		// The daily calendar decides the flag; setting it features the problem today
+		if req.IsDaily != nil && *req.IsDaily {
+			if _, err := services.PlanDailyProblem(tx, services.DailyDate(time.Now()), id, adminID); err != nil {
+				return err
+			}
+		}
+		if checker != nil {
+			checkerUpdates(updates, *checker)
 		}
 
 		if err := tx.Model(&problem).Updates(updates).Error; err != nil {
@@ -1,17 +1,118 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/judge"
 	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
 	"gorm.io/gorm"
 )
 
 // --- Problem Management ---
 
+// checkerInput is the output-checker configuration accepted by the problem admin endpoints
+// (contest and practice). Omitting checkerType leaves the current checker unchanged.
+type checkerInput struct {
+	CheckerType     string  `json:"checkerType"`     // EXACT, TOKEN, FLOAT, PROGRAM
+	CheckerEpsilon  float64 `json:"checkerEpsilon"`  // FLOAT only
+	CheckerLanguage string  `json:"checkerLanguage"` // PROGRAM only
+	CheckerCode     string  `json:"checkerCode"`     // PROGRAM only
+}
+
+// resolve validates the input on top of the current config; a PROGRAM update
+// without new code keeps the existing checker program. PROGRAM checkers must compile.
+func (in checkerInput) resolve(current models.CheckerConfig) (models.CheckerConfig, error) {
+	cfg := models.CheckerConfig{
+		CheckerType:     models.CheckerType(in.CheckerType),
+		CheckerEpsilon:  in.CheckerEpsilon,
+		CheckerLanguage: in.CheckerLanguage,
+		CheckerCode:     in.CheckerCode,
+	}
+	if cfg.CheckerCode == "" {
+		cfg.CheckerCode = current.CheckerCode
+		if cfg.CheckerLanguage == "" {
+			cfg.CheckerLanguage = current.CheckerLanguage
+		}
+	}
+	if err := judge.ValidateChecker(&cfg); err != nil {
+		return cfg, err
+	}
+	if cfg.CheckerType != models.CheckerProgram {
+		cfg.CheckerLanguage, cfg.CheckerCode = "", ""
+	}
+	return cfg, judge.VerifyChecker(executor, cfg)
+}
+
+// checkerErrorStatus is the response code for a resolve error: the checker can't be
+// verified while the executor is down, anything else is a bad checker
+func checkerErrorStatus(err error) int {
+	if errors.Is(err, services.ErrExecutorUnavailable) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadRequest
+}
+
+// resolveCheckerUpdate validates a checker change against the stored problem (a
+// *models.Problem or *models.PracticeProblem). It returns nil if the checker isn't
+// being changed, and writes the error response itself when ok is false.
+func resolveCheckerUpdate(c *gin.Context, in checkerInput, problem interface{}, id string) (*models.CheckerConfig, bool) {
+	if in.CheckerType == "" {
+		return nil, true
+	}
+	if err := database.DB.First(problem, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Problem not found"})
+		return nil, false
+	}
+
+	var current models.CheckerConfig
+	switch p := problem.(type) {
+	case *models.Problem:
+		current = p.CheckerConfig
+	case *models.PracticeProblem:
+		current = p.CheckerConfig
+	}
+
+	cfg, err := in.resolve(current)
+	if err != nil {
+		c.JSON(checkerErrorStatus(err), gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return &cfg, true
+}
+
+// checkerUpdates maps a checker config to column updates
+func checkerUpdates(updates map[string]interface{}, cfg models.CheckerConfig) {
+	updates["checker_type"] = cfg.CheckerType
+	updates["checker_epsilon"] = cfg.CheckerEpsilon
+	updates["checker_language"] = cfg.CheckerLanguage
+	updates["checker_code"] = cfg.CheckerCode
+}
+
+// validateTestGroups checks IOI subtasks: positive, unique group numbers and non-negative points
+func validateTestGroups(groups []models.TestGroup) error {
+	seen := make(map[int]bool)
+	for _, g := range groups {
+		if g.Group <= 0 {
+			return fmt.Errorf("test group numbers must be positive, got %d", g.Group)
+		}
+		if seen[g.Group] {
+			return fmt.Errorf("test group %d is defined twice", g.Group)
+		}
+		if g.Points < 0 {
+			return fmt.Errorf("test group %d has negative points", g.Group)
+		}
+		seen[g.Group] = true
+	}
+	return nil
+}
+
 // AdminGetProblem returns full problem details including hidden test cases and private fields
 func AdminGetProblem(c *gin.Context) {
 	problemID := c.Param("id")
@@ -21,22 +122,25 @@ func AdminGetProblem(c *gin.Context) {
 		c.JSON(404, gin.H{"error": "Problem not found"})
 		return
 	}
-	c.JSON(200, gin.H{"problem": problem})
+	// CheckerCode is hidden from the public JSON, so it's returned alongside
+	c.JSON(200, gin.H{"problem": problem, "checkerCode": problem.CheckerCode})
 }
 
 func AdminCreateProblem(c *gin.Context) {
 	adminID := getAdminID(c)
 	var req struct {
-		EventID     string  `json:"eventId" binding:"required"`
-		Title       string  `json:"title" binding:"required"`
-		Description string  `json:"description"`
-		Difficulty  string  `json:"difficulty"`
-		Points      int     `json:"points"`
-		TimeLimit   float64 `json:"timeLimit"`
-		MemoryLimit int     `json:"memoryLimit"`
-		Penalty     int     `json:"penalty"`
-		StarterCode string  `json:"starterCode"` // JSON string map[lang]code
-		Order       int     `json:"order"`
+		EventID     string             `json:"eventId" binding:"required"`
+		Title       string             `json:"title" binding:"required"`
+		Description string             `json:"description"`
+		Difficulty  string             `json:"difficulty"`
+		Points      int                `json:"points"`
+		TimeLimit   float64            `json:"timeLimit"`
+		MemoryLimit int                `json:"memoryLimit"`
+		Penalty     int                `json:"penalty"`
+		StarterCode string             `json:"starterCode"` // JSON string map[lang]code
+		Order       int                `json:"order"`
+		TestGroups  []models.TestGroup `json:"testGroups"` // IOI subtasks
+		checkerInput
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -44,18 +148,30 @@ func AdminCreateProblem(c *gin.Context) {
 		return
 	}
 
+	checker, err := req.checkerInput.resolve(models.CheckerConfig{})
+	if err != nil {
+		c.JSON(checkerErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateTestGroups(req.TestGroups); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	problem := models.Problem{
-		ID:          uuid.New().String(),
-		EventID:     req.EventID,
-		Title:       req.Title,
-		Description: req.Description,
-		Difficulty:  req.Difficulty,
-		Points:      req.Points,
-		TimeLimit:   req.TimeLimit,
-		MemoryLimit: req.MemoryLimit,
-		Penalty:     req.Penalty,
-		StarterCode: req.StarterCode,
-		Order:       req.Order,
+		ID:            uuid.New().String(),
+		EventID:       req.EventID,
+		Title:         req.Title,
+		Description:   req.Description,
+		Difficulty:    req.Difficulty,
+		Points:        req.Points,
+		TimeLimit:     req.TimeLimit,
+		MemoryLimit:   req.MemoryLimit,
+		Penalty:       req.Penalty,
+		StarterCode:   req.StarterCode,
+		CheckerConfig: checker,
+		TestGroups:    req.TestGroups,
+		Order:         req.Order,
 	}
 
 	if err := database.DB.Create(&problem).Error; err != nil {
@@ -73,15 +189,17 @@ func AdminUpdateProblem(c *gin.Context) {
 	adminID := getAdminID(c)
 
 	var req struct {
-		Title       string  `json:"title"`
-		Description string  `json:"description"`
-		Difficulty  string  `json:"difficulty"`
-		Points      int     `json:"points"`
-		TimeLimit   float64 `json:"timeLimit"`
-		MemoryLimit int     `json:"memoryLimit"`
-		Penalty     int     `json:"penalty"`
-		StarterCode string  `json:"starterCode"`
-		Order       int     `json:"order"`
+		Title       string             `json:"title"`
+		Description string             `json:"description"`
+		Difficulty  string             `json:"difficulty"`
+		Points      int                `json:"points"`
+		TimeLimit   float64            `json:"timeLimit"`
+		MemoryLimit int                `json:"memoryLimit"`
+		Penalty     int                `json:"penalty"`
+		StarterCode string             `json:"starterCode"`
+		Order       int                `json:"order"`
+		TestGroups  []models.TestGroup `json:"testGroups"` // Omit to keep, [] to clear
+		checkerInput
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -89,6 +207,15 @@ func AdminUpdateProblem(c *gin.Context) {
 		return
 	}
 
+	checker, ok := resolveCheckerUpdate(c, req.checkerInput, &models.Problem{}, problemID)
+	if !ok {
+		return
+	}
+	if err := validateTestGroups(req.TestGroups); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	err := database.DB.Transaction(func(tx *gorm.DB) error {
 		var problem models.Problem
 		if err := tx.First(&problem, "id = ?", problemID).Error; err != nil {
@@ -113,6 +240,13 @@ func AdminUpdateProblem(c *gin.Context) {
 			updates["starter_code"] = req.StarterCode
 		}
 		updates["order"] = req.Order // Careful with 0, but acceptable
+		if checker != nil {
+			checkerUpdates(updates, *checker)
+		}
+		if req.TestGroups != nil {
+			groups, _ := json.Marshal(req.TestGroups) // Stored with the JSON serializer
+			updates["test_groups"] = string(groups)
+		}
 
 		if err := tx.Model(&problem).Updates(updates).Error; err != nil {
 			return err
@@ -194,6 +328,7 @@ func AdminCreateTestCase(c *gin.Context) {
 		Input    string `json:"input" binding:"required"`
 		Output   string `json:"output" binding:"required"`
 		IsHidden bool   `json:"isHidden"`
+		Group    int    `json:"group"` // IOI test group, 0 = none
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -206,6 +341,7 @@ func AdminCreateTestCase(c *gin.Context) {
 		Input:     req.Input,
 		Output:    req.Output,
 		IsHidden:  req.IsHidden,
+		Group:     req.Group,
 	}
 
 	if err := database.DB.Create(&tc).Error; err != nil {
@@ -225,6 +361,7 @@ func AdminUpdateTestCase(c *gin.Context) {
 		Input    string `json:"input"`
 		Output   string `json:"output"`
 		IsHidden *bool  `json:"isHidden"` // Pointer to handle false
+		Group    *int   `json:"group"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -241,6 +378,9 @@ func AdminUpdateTestCase(c *gin.Context) {
 	if req.IsHidden != nil {
 		updates["is_hidden"] = *req.IsHidden
 	}
+	if req.Group != nil {
+		updates["test_group"] = *req.Group
+	}
 
 	if err := database.DB.Model(&models.TestCase{}).Where("id = ?", tcID).Updates(updates).Error; err != nil {
 		c.JSON(500, gin.H{"error": "DB Error"})
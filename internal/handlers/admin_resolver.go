@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
+	"gorm.io/gorm"
+)
+
+// --- Scoreboard Resolver ---
+// After a frozen contest ends, staff reveal the hidden results one cell at a time.
+// The reveal is recomputed from the submissions on every call, so only the cursor
+// (Event.ResolverStep) is stored and every replica agrees on the public board.
+
+// errResolverMoved rejects a step when another admin advanced the cursor concurrently
+var errResolverMoved = errors.New("resolver cursor moved, reload and retry")
+
+// loadResolution builds the reveal and writes the matching error response on failure
+func loadResolution(c *gin.Context, eventID string) (*services.Resolution, *models.Event, bool) {
+	var event models.Event
+	if err := database.DB.First(&event, "id = ?", eventID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return nil, nil, false
+	}
+
+	res, err := services.ResolveContest(eventID)
+	switch {
+	case errors.Is(err, services.ErrNoFreeze), errors.Is(err, services.ErrContestNotEnded):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build resolver: " + err.Error()})
+		return nil, nil, false
+	}
+	return res, &event, true
+}
+
+// AdminGetResolver returns the whole reveal (initial board, ordered steps, final board)
+// and how far it has been played, so a frontend can animate from the current position
+func AdminGetResolver(c *gin.Context) {
+	res, event, ok := loadResolution(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"eventId":    res.EventID,
+		"cursor":     event.ResolverStep,
+		"total":      len(res.Steps),
+		"unfrozenAt": event.UnfrozenAt,
+		"initial":    res.Initial,
+		"steps":      res.Steps,
+		"final":      res.Final,
+	})
+}
+
+// AdminResolverStep reveals the next `count` cells (default 1) and broadcasts each
+// step to the contest rooms
+func AdminResolverStep(c *gin.Context) {
+	eventID := c.Param("id")
+	adminID := getAdminID(c)
+
+	var req struct {
+		Count int `json:"count"`
+	}
+	_ = c.ShouldBindJSON(&req) // Body is optional
+	if req.Count <= 0 {
+		req.Count = 1
+	}
+
+	res, event, ok := loadResolution(c, eventID)
+	if !ok {
+		return
+	}
+	if event.UnfrozenAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Scoreboard already unfrozen"})
+		return
+	}
+
+	from := event.ResolverStep
+	to := from + req.Count
+	if to > len(res.Steps) {
+		to = len(res.Steps)
+	}
+	if from >= to {
+		c.JSON(http.StatusOK, gin.H{"cursor": from, "total": len(res.Steps), "steps": []services.ResolverStep{}, "done": true})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		moved := tx.Model(&models.Event{}).
+			Where("id = ? AND resolver_step = ? AND unfrozen_at IS NULL", eventID, from).
+			Update("resolver_step", to)
+		if moved.Error != nil {
+			return moved.Error
+		}
+		if moved.RowsAffected == 0 {
+			return errResolverMoved
+		}
+		if from == 0 {
+			return logAdminAction(tx, adminID, models.ActionResolveContest, eventID, "contest", "Started scoreboard resolver")
+		}
+		return nil
+	})
+	if errors.Is(err, errResolverMoved) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	services.InvalidateLeaderboardCache(eventID)
+
+	steps := res.Steps[from:to]
+	broadcastResolverSteps(eventID, steps)
+
+	c.JSON(http.StatusOK, gin.H{
+		"cursor": to,
+		"total":  len(res.Steps),
+		"steps":  steps,
+		"done":   to == len(res.Steps),
+	})
+}
+
+// AdminResolverFinish reveals whatever is left and persists the unfrozen final
+// standings: the public board stops applying the freeze and the results snapshot
+// (ContestResult, registration score and rank) is rewritten from the revealed board
+func AdminResolverFinish(c *gin.Context) {
+	eventID := c.Param("id")
+	adminID := getAdminID(c)
+
+	res, event, ok := loadResolution(c, eventID)
+	if !ok {
+		return
+	}
+	if event.UnfrozenAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Scoreboard already unfrozen"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		done := tx.Model(&models.Event{}).
+			Where("id = ? AND unfrozen_at IS NULL", eventID).
+			Updates(map[string]interface{}{"unfrozen_at": now, "resolver_step": len(res.Steps), "updated_at": now})
+		if done.Error != nil {
+			return done.Error
+		}
+		if done.RowsAffected == 0 {
+			return errResolverMoved
+		}
+
+		if _, err := services.SnapshotResults(tx, eventID, res.Final); err != nil {
+			return err
+		}
+		reason := fmt.Sprintf("Unfrozen after %d of %d resolver steps", event.ResolverStep, len(res.Steps))
+		return logAdminAction(tx, adminID, models.ActionUnfreezeContest, eventID, "contest", reason)
+	})
+	if errors.Is(err, errResolverMoved) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Scoreboard already unfrozen"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	services.InvalidateLeaderboardCache(eventID)
+
+	broadcastResolverDone(eventID, res.Final)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Scoreboard Unfrozen", "leaderboard": res.Final})
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// storeItemInput is the editable part of a store item; nil/empty fields are left unchanged
+type storeItemInput struct {
+	Name           string                 `json:"name"`
+	Description    *string                `json:"description"`
+	Category       models.StoreCategory   `json:"category"`
+	Price          *int                   `json:"price"`
+	MinLevel       *int                   `json:"minLevel"`
+	AvailableFrom  *time.Time             `json:"availableFrom"`
+	AvailableUntil *time.Time             `json:"availableUntil"`
+	ClearWindow    bool                   `json:"clearWindow"` // Remove both availability bounds
+	Enabled        *bool                  `json:"enabled"`
+	Data           map[string]interface{} `json:"data"`
+}
+
+func (in *storeItemInput) validate() error {
+	switch in.Category {
+	case "", models.StoreCategoryAura, models.StoreCategoryTheme, models.StoreCategoryComponent, models.StoreCategoryPowerup:
+	default:
+		return fmt.Errorf("unknown category %q", in.Category)
+	}
+	if in.Price != nil && *in.Price < 0 {
+		return errors.New("price cannot be negative")
+	}
+	if in.MinLevel != nil && *in.MinLevel < 0 {
+		return errors.New("minLevel cannot be negative")
+	}
+	if in.AvailableFrom != nil && in.AvailableUntil != nil && !in.AvailableUntil.After(*in.AvailableFrom) {
+		return errors.New("availableUntil must be after availableFrom")
+	}
+	return nil
+}
+
+// AdminListStoreItems handles GET /admin/store/items: the whole catalog, including
+// items that are disabled or outside their availability window
+func AdminListStoreItems(c *gin.Context) {
+	query := database.DB.Model(&models.StoreItem{})
+	if category := c.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+	var items []models.StoreItem
+	if err := query.Order("category asc, price asc, id asc").Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch store items"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// AdminCreateStoreItem handles POST /admin/store/items
+func AdminCreateStoreItem(c *gin.Context) {
+	adminID := getAdminID(c)
+
+	var req struct {
+		ID string `json:"id" binding:"required"`
+		storeItemInput
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Category == "" || req.Price == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category and price are required"})
+		return
+	}
+
+	item := models.StoreItem{
+		ID:             strings.TrimSpace(req.ID),
+		Name:           req.Name,
+		Category:       req.Category,
+		Price:          *req.Price,
+		AvailableFrom:  req.AvailableFrom,
+		AvailableUntil: req.AvailableUntil,
+		Enabled:        true,
+		Data:           req.Data,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	if item.Name == "" {
+		item.Name = item.ID
+	}
+	if req.Description != nil {
+		item.Description = *req.Description
+	}
+	if req.MinLevel != nil {
+		item.MinLevel = *req.MinLevel
+	}
+	if req.Enabled != nil {
+		item.Enabled = *req.Enabled
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		tx.Model(&models.StoreItem{}).Where("id = ?", item.ID).Count(&existing)
+		if existing > 0 {
+			return gorm.ErrDuplicatedKey
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		return logAdminAction(tx, adminID, models.ActionCreateStoreItem, item.ID, "store_item",
+			fmt.Sprintf("Created %s item for %d XP", item.Category, item.Price))
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Item already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"item": item})
+}
+
+// AdminUpdateStoreItem handles PUT /admin/store/items/:id. Price changes only affect
+// future purchases.
+func AdminUpdateStoreItem(c *gin.Context) {
+	id := c.Param("id")
+	adminID := getAdminID(c)
+
+	var req storeItemInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var item models.StoreItem
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&item, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		if req.Name != "" {
+			updates["name"] = req.Name
+		}
+		if req.Description != nil {
+			updates["description"] = *req.Description
+		}
+		if req.Category != "" {
+			updates["category"] = req.Category
+			updates["legacy"] = false // The category is no longer a guess
+		}
+		if req.Price != nil {
+			updates["price"] = *req.Price
+		}
+		if req.MinLevel != nil {
+			updates["min_level"] = *req.MinLevel
+		}
+		if req.ClearWindow {
+			updates["available_from"] = nil
+			updates["available_until"] = nil
+		}
+		if req.AvailableFrom != nil {
+			updates["available_from"] = *req.AvailableFrom
+		}
+		if req.AvailableUntil != nil {
+			updates["available_until"] = *req.AvailableUntil
+		}
+		if req.Enabled != nil {
+			updates["enabled"] = *req.Enabled
+		}
+		if req.Data != nil {
+			item.Data = req.Data
+			if err := tx.Model(&item).Select("data").Updates(&item).Error; err != nil {
+				return err
+			}
+		}
+		updates["updated_at"] = time.Now()
+
+		if err := tx.Model(&item).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&item, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if item.AvailableFrom != nil && item.AvailableUntil != nil && !item.AvailableUntil.After(*item.AvailableFrom) {
+			return errStoreWindow
+		}
+		return logAdminAction(tx, adminID, models.ActionUpdateStoreItem, id, "store_item",
+			fmt.Sprintf("%s, %d XP, min level %d, enabled=%t", item.Category, item.Price, item.MinLevel, item.Enabled))
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	}
+	if errors.Is(err, errStoreWindow) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"item": item})
+}
+
+var errStoreWindow = errors.New("availableUntil must be after availableFrom")
+
+// AdminDeleteStoreItem handles DELETE /admin/store/items/:id. Items someone owns can
+// only be disabled, so they stay equippable.
+func AdminDeleteStoreItem(c *gin.Context) {
+	id := c.Param("id")
+	adminID := getAdminID(c)
+
+	var owners int64
+	database.DB.Model(&models.User{}).Where("? = ANY(purchased_component_ids)", id).Count(&owners)
+	if owners > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Item is owned by users; disable it instead", "owners": owners})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.StoreItem{}, "id = ?", id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return logAdminAction(tx, adminID, models.ActionDeleteStoreItem, id, "store_item", "Deleted Store Item")
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item Deleted"})
+}
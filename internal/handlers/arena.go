@@ -84,23 +84,9 @@ func ListEvents(c *gin.Context) {
 	}
 
 	var response []EventListResponse
-	now := time.Now()
 
+	// Status is kept current by the contest scheduler (services.ContestScheduler)
 	for _, event := range events {
-		// Lazy Status Update: Check if event should be ENDED or LIVE
-		updated := false
-		if event.Status != models.EventStatusEnded && now.After(event.EndTime) {
-			event.Status = models.EventStatusEnded
-			updated = true
-		} else if event.Status == models.EventStatusUpcoming && now.After(event.StartTime) && now.Before(event.EndTime) {
-			event.Status = models.EventStatusLive
-			updated = true
-		}
-
-		if updated {
-			database.DB.Model(&models.Event{}).Where("id = ?", event.ID).Update("status", event.Status)
-		}
-
 		// 1. Participant Count from map
 		participantCount := countMap[event.ID]
 
@@ -142,21 +128,6 @@ func GetEvent(c *gin.Context) {
 		return
 	}
 
-	// Lazy Status Update
-	now := time.Now()
-	updated := false
-	if event.Status != models.EventStatusEnded && now.After(event.EndTime) {
-		event.Status = models.EventStatusEnded
-		updated = true
-	} else if event.Status == models.EventStatusUpcoming && now.After(event.StartTime) && now.Before(event.EndTime) {
-		event.Status = models.EventStatusLive
-		updated = true
-	}
-
-	if updated {
-		database.DB.Model(&event).Update("status", event.Status)
-	}
-
 	// Calculate Metadata
 	problemCount := int64(len(event.Problems))
 	totalPoints := 0
@@ -214,6 +185,10 @@ func RegisterForEvent(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 		return
 	}
+	if event.IsTeamEvent() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This is a team contest, register with your team"})
+		return
+	}
 
 	// Check existing
 	var existing models.Registration
@@ -222,6 +197,24 @@ func RegisterForEvent(c *gin.Context) {
 		return
 	}
 
+	// Rating range (rated divisions)
+	if event.RatingMin != nil || event.RatingMax != nil {
+		var user models.User
+		if err := database.DB.Select("id", "rating").First(&user, "id = ?", userID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if !event.RatingEligible(user.Rating) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":     "Your rating is outside this contest's eligible range",
+				"rating":    user.Rating,
+				"ratingMin": event.RatingMin,
+				"ratingMax": event.RatingMax,
+			})
+			return
+		}
+	}
+
 	// Payment Logic
 	status := models.RegStatusPaid // Default for free events
 	if event.Price > 0 {
@@ -267,6 +260,7 @@ func RegisterForEvent(c *gin.Context) {
 	// Increment User's Contest Count
 	strUserID := userID.(string)
 	database.DB.Model(&models.User{ID: strUserID}).Update("wrapped_contest_count", gorm.Expr("wrapped_contest_count + ?", 1))
+	recordBadgeEvent(strUserID, models.MetricContestsJoined, 1)
 
 	c.JSON(http.StatusOK, registration)
 }
@@ -329,6 +323,7 @@ func AcceptRules(c *gin.Context) {
 
 		// Increment User's Contest Count
 		database.DB.Model(&models.User{ID: uid}).Update("wrapped_contest_count", gorm.Expr("wrapped_contest_count + ?", 1))
+		recordBadgeEvent(uid, models.MetricContestsJoined, 1)
 
 		c.JSON(http.StatusOK, gin.H{"message": "Joined contest and accepted rules", "joined": true})
 		return
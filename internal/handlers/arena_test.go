@@ -2,20 +2,27 @@ package handlers
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pushp314/devconnect-backend/internal/database"
 	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
+var testQueueOnce sync.Once
+
 // SetupTestDB initializes an in-memory SQLite DB for testing
 func SetupTestDB() {
 	db, _ := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
@@ -27,7 +34,39 @@ func SetupTestDB() {
 		&models.TestCase{},
 		&models.Submission{},
 		&models.Registration{},
+		&models.SubmissionMetrics{},
+		&models.SubmissionFlag{},
+		&models.PracticeProblem{},
+		&models.PracticeSubmission{},
+		&models.JudgeJob{},
+		&models.Team{},
+		&models.TeamMember{},
+		&models.VirtualParticipation{},
+		&models.AntiCheatRule{},
+		&models.EventAntiCheatRule{},
+		&models.Playlist{},
+		&models.PlaylistSnippet{},
+		&models.PlaylistProgress{},
+		&models.XPLedgerEntry{},
+		&models.StoreItem{},
+		&models.Badge{},
+		&models.UserBadge{},
+		&models.UserMetric{},
+		&models.BadgeEvent{},
+		&models.DailyProblem{},
+		&models.DailySolve{},
+		&models.DailyStreak{},
 	)
+
+	// One judge worker shared by all tests, polling fast so verdicts arrive quickly
+	testQueueOnce.Do(func() {
+		q := services.NewJudgeQueue(database.DB, nil, services.JudgeQueueOptions{
+			Workers:      1,
+			PollInterval: 10 * time.Millisecond,
+		})
+		InitJudgeQueue(q)
+		q.Start()
+	})
 }
 
 func TestSubmitSolution_Accepted(t *testing.T) {
@@ -62,39 +101,53 @@ func TestSubmitSolution_Accepted(t *testing.T) {
 	database.DB.Create(&problem)
 	database.DB.Create(&models.TestCase{ID: "tc1", ProblemID: "prob1", Input: "1 2", Output: "3"})
 
-	// Mock Piston Execution Service (Replace function variable if possible, or key off language)
-	// Since we can't easily mock the 'services' package function without an interface,
-	// checking 'SubmitSolution' logic might fail if it calls real Piston.
-	// However, if we assume Piston call fails (network), we can check error handling.
-	// ideally, we refactor services.ExecuteCode to be an interface.
-	// For MVP test, let's verify Auth and validation logic primarily, OR use a Integration test mode.
-	// But wait, the `SubmitSolution` calls `services.ExecuteCode`.
-
-	// Implementation Note: Without dependency injection, this unit test will try to hit Piston API.
-	// If that fails, it returns RE. We can assert that behavior.
-
-	/*
-		// Create Request
-		body := map[string]string{
-			"code":     "print(1+2)",
-			"language": "python",
-		}
-		jsonVal, _ := json.Marshal(body)
-		req, _ := http.NewRequest("POST", "/api/contests/event1/problems/prob1/submit", bytes.NewBuffer(jsonVal))
-		req.Header.Set("Content-Type", "application/json")
+	// Shared in-memory DB: drop earlier runs so the submission cooldown doesn't trigger
+	database.DB.Where("user_id = ? AND problem_id = ?", "user1", "prob1").Delete(&models.Submission{})
 
-		w := httptest.NewRecorder()
-		c, _ := gin.CreateTestContext(w)
-		c.Request = req
-		c.Params = gin.Params{{Key: "problemId", Value: "prob1"}}
-		c.Set("userId", "user1")
+	// Inject a deterministic executor instead of hitting Piston
+	fake := services.NewFakeExecutor()
+	fake.Handle = func(req services.ExecutionRequest) (*services.ExecutionResult, error) {
+		return &services.ExecutionResult{Language: req.Language, Run: services.StageResult{Stdout: "3\n"}}, nil
+	}
+	SetExecutor(fake)
 
-		SubmitSolution(c)
+	body, _ := json.Marshal(map[string]string{
+		"code":     "a, b = map(int, input().split())\nprint(a + b)",
+		"language": "python",
+	})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/uri", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "problemId", Value: "prob1"}}
+	c.Set("userId", "user1")
+
+	SubmitSolution(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Submission models.Submission `json:"submission"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
 
-		// Check
-		assert.Equal(t, http.StatusCreated, w.Code)
-		// Logic would likely be RE if Piston down, or AC if Piston works.
-	*/
+	// Judging is asynchronous; wait for the verdict
+	var sub models.Submission
+	assert.Eventually(t, func() bool {
+		database.DB.First(&sub, "id = ?", resp.Submission.ID)
+		return sub.Status != models.SubStatusPending
+	}, 2*time.Second, 20*time.Millisecond)
+
+	assert.Equal(t, models.SubStatusAC, sub.Status)
+	assert.Equal(t, 1, sub.TestCasesPassed)
+	if assert.Len(t, sub.CaseResults, 1) {
+		assert.Equal(t, models.SubStatusAC, sub.CaseResults[0].Status)
+	}
+
+	// Contest judging bypasses the execution cache, so every run reaches the executor
+	if assert.Len(t, fake.Requests(), 1) {
+		assert.Equal(t, "1 2", fake.Requests()[0].Stdin)
+	}
 }
 
 // Test validation logic which doesn't require Piston
@@ -151,3 +204,278 @@ func TestSubmitSolution_ContestNotLive(t *testing.T) {
 	assert.Equal(t, http.StatusForbidden, w.Code)
 	assert.Contains(t, w.Body.String(), "not live")
 }
+
+func TestSubmitSolution_LanguageNotAllowed(t *testing.T) {
+	SetupTestDB()
+	gin.SetMode(gin.TestMode)
+
+	database.DB.Create(&models.User{ID: "user_lang", Email: "user_lang@example.com", Username: "user_lang"})
+	database.DB.Create(&models.Event{ID: "event_cpp_only", Status: models.EventStatusLive, Slug: "slug-cpp-only", AllowedLanguages: []string{"cpp"}})
+	database.DB.Create(&models.Registration{ID: "reg_lang", UserID: "user_lang", EventID: "event_cpp_only", Status: models.RegStatusPaid})
+	database.DB.Create(&models.Problem{ID: "prob_cpp_only", EventID: "event_cpp_only"})
+
+	submit := func(language string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"code": "x", "language": language})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/uri", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "problemId", Value: "prob_cpp_only"}}
+		c.Set("userId", "user_lang")
+		SubmitSolution(c)
+		return w
+	}
+
+	w := submit("python")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "not allowed in this contest")
+
+	// Aliases resolve to the registry ID, so "c++" passes the language rule
+	w = submit("c++")
+	assert.NotContains(t, w.Body.String(), "not allowed")
+}
+
+func TestRegisterForEvent_RatingRange(t *testing.T) {
+	SetupTestDB()
+	gin.SetMode(gin.TestMode)
+
+	maxRating := 1899
+	database.DB.Create(&models.Event{ID: "event_div2", Status: models.EventStatusUpcoming, Slug: "slug-div2", RatingMax: &maxRating})
+	database.DB.Create(&models.User{ID: "user_expert", Email: "expert@example.com", Username: "expert", Rating: 2100})
+	database.DB.Create(&models.User{ID: "user_newbie", Email: "newbie@example.com", Username: "newbie", Rating: 1500})
+
+	register := func(userID string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/uri", nil)
+		c.Params = gin.Params{{Key: "id", Value: "event_div2"}}
+		c.Set("userId", userID)
+		RegisterForEvent(c)
+		return w
+	}
+
+	w := register("user_expert")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "outside this contest's eligible range")
+
+	w = register("user_newbie")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRegisterTeamForEvent(t *testing.T) {
+	SetupTestDB()
+	gin.SetMode(gin.TestMode)
+
+	database.DB.Create(&models.Event{ID: "event_team", Status: models.EventStatusUpcoming, Slug: "slug-team", TeamSizeMin: 2, TeamSizeMax: 3})
+	for _, id := range []string{"captain", "mate"} {
+		database.DB.Create(&models.User{ID: "user_" + id, Email: id + "@example.com", Username: id, Rating: 1500})
+	}
+	database.DB.Create(&models.Team{ID: "team_a", Name: "A", OwnerID: "user_captain"})
+	database.DB.Create(&models.TeamMember{TeamID: "team_a", UserID: "user_captain", Role: models.TeamRoleOwner})
+
+	register := func(userID string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/uri", bytes.NewBufferString(`{"teamId":"team_a"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: "event_team"}}
+		c.Set("userId", userID)
+		RegisterTeamForEvent(c)
+		return w
+	}
+
+	// Too small
+	w := register("user_captain")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "need 2 to 3 members")
+
+	database.DB.Create(&models.TeamMember{TeamID: "team_a", UserID: "user_mate", Role: models.TeamRoleMember})
+
+	w = register("user_mate")
+	assert.Equal(t, http.StatusForbidden, w.Code, "only the owner registers")
+
+	w = register("user_captain")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var regs []models.Registration
+	database.DB.Where("event_id = ?", "event_team").Find(&regs)
+	assert.Len(t, regs, 2)
+	for _, reg := range regs {
+		if assert.NotNil(t, reg.TeamID) {
+			assert.Equal(t, "team_a", *reg.TeamID)
+		}
+	}
+
+	w = register("user_captain")
+	assert.Equal(t, http.StatusBadRequest, w.Code, "already registered")
+	assert.True(t, teamRosterLocked(database.DB, "team_a"))
+}
+
+func TestRegisterTeamForEvent_PaidOrder(t *testing.T) {
+	SetupTestDB()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("RAZORPAY_KEY_SECRET", "test-secret")
+
+	database.DB.Create(&models.Event{ID: "event_paid_team", Status: models.EventStatusUpcoming, Slug: "slug-paid-team", TeamSizeMin: 1, TeamSizeMax: 2, Price: 499})
+	for _, id := range []string{"payer", "other"} {
+		database.DB.Create(&models.User{ID: "user_" + id, Email: id + "@example.com", Username: id, Rating: 1500})
+		database.DB.Create(&models.Team{ID: "team_" + id, Name: id, OwnerID: "user_" + id})
+		database.DB.Create(&models.TeamMember{TeamID: "team_" + id, UserID: "user_" + id, Role: models.TeamRoleOwner})
+	}
+
+	orders := map[string]map[string]interface{}{
+		"order_cheap": {"amount": float64(100), "notes": map[string]interface{}{"event_id": "event_paid_team", "team_id": "team_payer"}},
+		"order_payer": {"amount": float64(49900), "notes": map[string]interface{}{"event_id": "event_paid_team", "team_id": "team_payer"}},
+		"order_other": {"amount": float64(49900), "notes": map[string]interface{}{"event_id": "event_paid_team", "team_id": "team_other"}},
+	}
+	prev := fetchRazorpayOrder
+	fetchRazorpayOrder = func(orderID string) (map[string]interface{}, error) { return orders[orderID], nil }
+	t.Cleanup(func() { fetchRazorpayOrder = prev })
+
+	register := func(userID, teamID, orderID, paymentID string) *httptest.ResponseRecorder {
+		h := hmac.New(sha256.New, []byte("test-secret"))
+		h.Write([]byte(orderID + "|" + paymentID))
+		body, _ := json.Marshal(map[string]string{"teamId": teamID, "razorpayOrderId": orderID,
+			"razorpayPaymentId": paymentID, "razorpaySignature": hex.EncodeToString(h.Sum(nil))})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/uri", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: "event_paid_team"}}
+		c.Set("userId", userID)
+		RegisterTeamForEvent(c)
+		return w
+	}
+
+	w := register("user_payer", "team_payer", "order_cheap", "pay_cheap")
+	assert.Equal(t, http.StatusForbidden, w.Code, "order for another amount")
+	w = register("user_payer", "team_payer", "order_other", "pay_other")
+	assert.Equal(t, http.StatusForbidden, w.Code, "order for another team")
+
+	w = register("user_payer", "team_payer", "order_payer", "pay_1")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = register("user_other", "team_other", "order_other", "pay_1")
+	assert.Equal(t, http.StatusConflict, w.Code, "payment already used")
+}
+
+func TestVerifyPayment_RejectsTeamEvent(t *testing.T) {
+	SetupTestDB()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("RAZORPAY_KEY_SECRET", "test-secret")
+
+	database.DB.Create(&models.Event{ID: "event_solo_pay", Status: models.EventStatusUpcoming, Slug: "slug-solo-pay", TeamSizeMin: 2, TeamSizeMax: 3, Price: 499})
+	database.DB.Create(&models.User{ID: "user_solo", Email: "solo@example.com", Username: "solo"})
+
+	h := hmac.New(sha256.New, []byte("test-secret"))
+	h.Write([]byte("order_solo|pay_solo"))
+	body, _ := json.Marshal(map[string]string{"eventId": "event_solo_pay", "razorpay_order_id": "order_solo",
+		"razorpay_payment_id": "pay_solo", "razorpay_signature": hex.EncodeToString(h.Sum(nil))})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/uri", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userId", "user_solo")
+	VerifyPayment(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "a team contest can't be joined solo by leaving out teamId")
+	var count int64
+	database.DB.Model(&models.Registration{}).Where("event_id = ?", "event_solo_pay").Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestJudgeContestSubmission_VirtualFastAccept(t *testing.T) {
+	SetupTestDB()
+
+	now := time.Now()
+	start := now.Add(-5 * time.Hour)
+	database.DB.Create(&models.Event{ID: "event_vfa", Status: models.EventStatusEnded, Slug: "slug-vfa", StartTime: start, EndTime: start.Add(2 * time.Hour)})
+	database.DB.Create(&models.Problem{ID: "prob_vfa", EventID: "event_vfa", Points: 100, TimeLimit: 2.0})
+	database.DB.Create(&models.TestCase{ID: "tc_vfa", ProblemID: "prob_vfa", Input: "1 2", Output: "3"})
+	database.DB.Create(&models.User{ID: "user_vfa", Email: "vfa@example.com", Username: "vfa", TrustScore: 100})
+	database.DB.Create(&models.AntiCheatRule{ID: "fast-accept", Name: "Fast", Condition: models.ConditionFastAccept,
+		Operator: models.OperatorLT, Threshold: 60, FlagType: models.FlagTypeSuspicious, Enabled: true})
+	vpID := "vp_vfa"
+	database.DB.Create(&models.VirtualParticipation{ID: vpID, UserID: "user_vfa", EventID: "event_vfa", StartedAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)})
+	database.DB.Create(&models.Submission{ID: "sub_vfa", UserID: "user_vfa", EventID: "event_vfa", ProblemID: "prob_vfa", VirtualID: &vpID,
+		Language: "python", Code: "print(3)", Status: models.SubStatusPending, CreatedAt: now.Add(-50 * time.Second)})
+
+	fake := services.NewFakeExecutor()
+	fake.Handle = func(req services.ExecutionRequest) (*services.ExecutionResult, error) {
+		return &services.ExecutionResult{Language: req.Language, Run: services.StageResult{Stdout: "3\n"}}, nil
+	}
+	SetExecutor(fake)
+
+	assert.NoError(t, judgeContestSubmission(models.JudgeJob{SubmissionID: "sub_vfa"}))
+
+	// Solved 10s into the personal clock: the virtual run is checked like the real contest
+	var metrics models.SubmissionMetrics
+	database.DB.First(&metrics, "submission_id = ?", "sub_vfa")
+	if assert.Len(t, metrics.RuleResults, 1) {
+		assert.Equal(t, "fast-accept", metrics.RuleResults[0].RuleID)
+		assert.True(t, metrics.RuleResults[0].Triggered)
+	}
+}
+
+func TestJudgePracticeSubmission_RecordsNextProblem(t *testing.T) {
+	SetupTestDB()
+
+	cases := `[{"input": "5", "expected": "5"}]`
+	database.DB.Create(&models.User{ID: "user_np", Email: "np@example.com", Username: "np"})
+	database.DB.Create(&[]models.PracticeProblem{
+		{ID: "pp_np_a", Title: "A", Difficulty: "EASY", Category: "Arrays", Language: "python", TestCases: cases},
+		{ID: "pp_np_b", Title: "B", Difficulty: "EASY", Category: "Arrays", Language: "python", TestCases: cases},
+	})
+	database.DB.Create(&models.PracticeSubmission{ID: "ps_np", UserID: "user_np", ProblemID: "pp_np_a",
+		Language: "python", Code: "print(input())", Status: "PENDING"})
+	SetExecutor(services.NewFakeExecutor()) // Echoes stdin
+
+	assert.NoError(t, judgePracticeSubmission(models.JudgeJob{SubmissionID: "ps_np"}))
+
+	var sub models.PracticeSubmission
+	database.DB.First(&sub, "id = ?", "ps_np")
+	assert.Equal(t, string(models.SubStatusAC), sub.Status)
+	assert.Equal(t, "pp_np_b", sub.NextProblemID, "the solved problem is never suggested")
+}
+
+func TestJudgeContestSubmission_BrokenCheckerIsNotRetried(t *testing.T) {
+	SetupTestDB()
+
+	now := time.Now()
+	database.DB.Create(&models.Event{ID: "event_bc", Status: models.EventStatusLive, Slug: "slug-bc", StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour)})
+	database.DB.Create(&models.Problem{ID: "prob_bc", EventID: "event_bc", Points: 100, TimeLimit: 2.0, CheckerConfig: models.CheckerConfig{
+		CheckerType: models.CheckerProgram, CheckerLanguage: "c++", CheckerCode: "// broken checker"}})
+	database.DB.Create(&models.TestCase{ID: "tc_bc", ProblemID: "prob_bc", Input: "1", Output: "1"})
+	database.DB.Create(&models.User{ID: "user_bc", Email: "bc@example.com", Username: "bc"})
+	database.DB.Create(&models.Submission{ID: "sub_bc", UserID: "user_bc", EventID: "event_bc", ProblemID: "prob_bc",
+		Language: "python", Code: "print(input())", Status: models.SubStatusPending, CreatedAt: now})
+
+	fake := services.NewFakeExecutor()
+	fake.Handle = func(req services.ExecutionRequest) (*services.ExecutionResult, error) {
+		if req.Code == "// broken checker" {
+			return &services.ExecutionResult{Compile: &services.StageResult{Code: 1, Stderr: "expected ';'"}}, nil
+		}
+		return &services.ExecutionResult{Run: services.StageResult{Stdout: req.Stdin}}, nil
+	}
+	SetExecutor(fake)
+
+	assert.NoError(t, judgeContestSubmission(models.JudgeJob{SubmissionID: "sub_bc"}), "retrying can't fix the checker")
+	var sub models.Submission
+	database.DB.First(&sub, "id = ?", "sub_bc")
+	assert.Equal(t, models.SubStatusRE, sub.Status)
+	assert.Contains(t, sub.Verdict, models.JudgeErrorVerdict+"checker failed")
+
+	// Saving the same checker is refused
+	body, _ := json.Marshal(map[string]interface{}{"checkerType": "PROGRAM", "checkerLanguage": "c++", "checkerCode": "// broken checker"})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("PUT", "/uri", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: "prob_bc"}}
+	c.Set("userId", "admin_bc")
+	AdminUpdateProblem(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "expected ';'")
+}
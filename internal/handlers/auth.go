@@ -14,6 +14,7 @@ import (
 	"github.com/pushp314/devconnect-backend/internal/config"
 	"github.com/pushp314/devconnect-backend/internal/database"
 	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
 	"github.com/pushp314/devconnect-backend/pkg/logger"
 	"github.com/pushp314/devconnect-backend/pkg/utils"
 	"golang.org/x/crypto/bcrypt"
@@ -118,6 +119,7 @@ func Register(c *gin.Context) {
 		c.JSON(http.StatusConflict, gin.H{"error": "User with this email or username already exists"})
 		return
 	}
+	recordSignupRank(&user)
 
 	// Generate Token
 	token, err := utils.GenerateToken(user.ID)
@@ -528,6 +530,7 @@ func handleOAuthLogin(c *gin.Context, email, name, image string) *models.User {
 			return nil
 		}
 		logger.Info().Str("email", email).Str("user_id", user.ID).Msg("New user successfully registered via OAuth")
+		recordSignupRank(&user)
 		return &user
 	}
 
@@ -651,3 +654,13 @@ func ResetPassword(c *gin.Context) {
 	logger.Info().Str("user_id", user.ID).Msg("Password reset successfully")
 	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
 }
+
+// recordSignupRank feeds a new user's position in sign-up order to the badge engine
+func recordSignupRank(user *models.User) {
+	rank, err := services.JoinRank(database.DB, user)
+	if err != nil {
+		logger.Error().Err(err).Str("user", user.ID).Msg("Failed to compute join rank")
+		return
+	}
+	recordBadgeEvent(user.ID, models.MetricJoinRank, rank)
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
 	"github.com/pushp314/devconnect-backend/internal/services"
 )
 
@@ -14,12 +16,13 @@ func GetContestLeaderboard(c *gin.Context) {
 	// Optional: Check if user is registered?
 	// Leaderboards are often public, or at least public to platform users.
 
-	// Check if Admin (to bypass freeze)
-	asAdmin := false
-	if role, exists := c.Get("role"); exists {
-		if r, ok := role.(string); ok && r == "ADMIN" {
-			asAdmin = true
-		}
+	// Staff see through the freeze
+	asAdmin := isStaff(c)
+
+	var event models.Event
+	if err := database.DB.Preload("Problems").First(&event, "id = ?", eventID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
 	}
 
 	leaderboard, err := services.GetLeaderboard(eventID, asAdmin)
@@ -28,8 +31,34 @@ func GetContestLeaderboard(c *gin.Context) {
 		return
 	}
 
+	scoringMode := event.ScoringMode
+	if !scoringMode.Valid() {
+		scoringMode = models.ScoringICPC
+	}
+
+	// Column headers: the maximum each problem is worth under this mode
+	type problemColumn struct {
+		ID        string `json:"id"`
+		Title     string `json:"title"`
+		Order     int    `json:"order"`
+		MaxPoints int    `json:"maxPoints"`
+	}
+	problems := make([]problemColumn, 0, len(event.Problems))
+	for _, p := range event.Problems {
+		maxPoints := p.Points
+		if scoringMode == models.ScoringIOI && len(p.TestGroups) > 0 {
+			maxPoints = 0
+			for _, g := range p.TestGroups {
+				maxPoints += g.Points
+			}
+		}
+		problems = append(problems, problemColumn{ID: p.ID, Title: p.Title, Order: p.Order, MaxPoints: maxPoints})
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"eventId":     eventID,
+		"scoringMode": scoringMode,
+		"problems":    problems,
 		"leaderboard": leaderboard,
 	})
 }
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
+	"gorm.io/gorm"
+)
+
+// ContestResultRow is a ContestResult as shown publicly
+type ContestResultRow struct {
+	Rank        int                             `json:"rank"`
+	UserID      string                          `json:"userId"`
+	Username    string                          `json:"username"`
+	Name        string                          `json:"name"`
+	Avatar      string                          `json:"avatar"`
+	TeamID      string                          `json:"teamId,omitempty"`
+	TeamName    string                          `json:"teamName,omitempty"`
+	Score       int                             `json:"score"`
+	SolvedCount int                             `json:"solvedCount"`
+	Penalty     float64                         `json:"penalty"`
+	Status      string                          `json:"status"`
+	Problems    map[string]models.ProblemResult `json:"problems"`
+}
+
+// loadPublishedResults returns an event and its final standings, writing the error
+// response when they are not published: before finalization, and for frozen contests
+// until the resolver has unfrozen the scoreboard (staff can always see them)
+func loadPublishedResults(c *gin.Context, eventID string) (*models.Event, []ContestResultRow, int, bool) {
+	var event models.Event
+	if err := database.DB.Preload("Problems").First(&event, "id = ?", eventID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return nil, nil, 0, false
+	}
+	if event.FinalizedAt == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Results are not published yet"})
+		return nil, nil, 0, false
+	}
+	if resultsHidden(c, &event) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Results are published once the scoreboard is unfrozen"})
+		return nil, nil, 0, false
+	}
+
+	results, err := services.ContestResults(database.DB, eventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch results"})
+		return nil, nil, 0, false
+	}
+
+	rows, version := toResultRows(results)
+	return &event, rows, version, true
+}
+
+// resultsHidden reports whether the event's scores are still behind its freeze: from
+// the freeze time until the resolver unfreezes the scoreboard, for everyone but staff
+func resultsHidden(c *gin.Context, event *models.Event) bool {
+	return event.FreezeTime != nil && !time.Now().Before(*event.FreezeTime) && event.UnfrozenAt == nil && !isStaff(c)
+}
+
+// toResultRows converts a snapshot for display and returns its version
+func toResultRows(results []models.ContestResult) ([]ContestResultRow, int) {
+	rows := make([]ContestResultRow, 0, len(results))
+	version := 0
+	for _, r := range results {
+		version = r.Version
+		row := ContestResultRow{
+			Rank:        r.Rank,
+			UserID:      r.UserID,
+			Username:    r.User.Username,
+			Name:        r.User.Name,
+			Avatar:      r.User.Image,
+			Score:       r.Score,
+			SolvedCount: r.SolvedCount,
+			Penalty:     r.Penalty,
+			Status:      r.Status,
+			Problems:    r.Problems,
+		}
+		if r.Team != nil {
+			row.TeamID, row.TeamName = r.Team.ID, r.Team.Name
+		}
+		rows = append(rows, row)
+	}
+	return rows, version
+}
+
+// GetContestResults handles GET /events/:id/results, the archived final standings
+func GetContestResults(c *gin.Context) {
+	event, rows, version, ok := loadPublishedResults(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"eventId":     event.ID,
+		"scoringMode": event.ScoringMode,
+		"finalizedAt": event.FinalizedAt,
+		"version":     version,
+		"results":     rows,
+	})
+}
+
+// ExportContestResults handles GET /events/:id/results/export?format=csv|json
+func ExportContestResults(c *gin.Context) {
+	event, rows, version, ok := loadPublishedResults(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	filename := event.Slug
+	if filename == "" {
+		filename = event.ID
+	}
+	filename += "-results." + format
+
+	switch format {
+	case "json":
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.JSON(http.StatusOK, gin.H{
+			"eventId":     event.ID,
+			"title":       event.Title,
+			"scoringMode": event.ScoringMode,
+			"finalizedAt": event.FinalizedAt,
+			"version":     version,
+			"results":     rows,
+		})
+	case "csv":
+		problems := append([]models.Problem{}, event.Problems...)
+		sort.SliceStable(problems, func(i, j int) bool { return problems[i].Order < problems[j].Order })
+
+		header := []string{"rank", "username", "name", "team", "score", "solved", "penalty", "status"}
+		for _, p := range problems {
+			label := p.Title
+			if label == "" {
+				label = p.ID
+			}
+			header = append(header, label+" status", label+" attempts", label+" score")
+		}
+
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Status(http.StatusOK)
+
+		w := csv.NewWriter(c.Writer)
+		w.Write(header)
+		for _, row := range rows {
+			record := []string{
+				strconv.Itoa(row.Rank),
+				row.Username,
+				row.Name,
+				row.TeamName,
+				strconv.Itoa(row.Score),
+				strconv.Itoa(row.SolvedCount),
+				strconv.FormatFloat(row.Penalty, 'f', -1, 64),
+				row.Status,
+			}
+			for _, p := range problems {
+				cell, tried := row.Problems[p.ID]
+				if !tried {
+					record = append(record, "", "", "")
+					continue
+				}
+				record = append(record, cell.Status, strconv.Itoa(cell.Attempts), strconv.Itoa(cell.Score))
+			}
+			w.Write(record)
+		}
+		w.Flush()
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or json"})
+	}
+}
+
+// AdminRerankContest recomputes an ended contest's final standings after moderation
+// and replaces the results snapshot
+func AdminRerankContest(c *gin.Context) {
+	eventID := c.Param("id")
+	adminID := getAdminID(c)
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	version, err := services.RerankContest(database.DB, eventID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	case errors.Is(err, services.ErrContestNotEnded):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to re-rank: " + err.Error()})
+		return
+	}
+
+	reason := fmt.Sprintf("Re-ranked (results v%d)", version)
+	if req.Reason != "" {
+		reason += ": " + req.Reason
+	}
+	logAdminAction(database.DB, adminID, models.ActionRerankContest, eventID, "contest", reason)
+
+	results, _ := services.ContestResults(database.DB, eventID)
+	rows, _ := toResultRows(results)
+	c.JSON(http.StatusOK, gin.H{"message": "Contest Re-ranked", "version": version, "results": rows})
+}
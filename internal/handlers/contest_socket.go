@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"log"
+	"sync"
+
+	socketio "github.com/googollee/go-socket.io"
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
+)
+
+// Contest rooms: participants join contestRoom and get the public scoreboard, which
+// stops at FreezeTime and shows PENDING cells after it; staff join contestAdminRoom
+// and keep the live view through the freeze.
+
+func contestRoom(eventID string) string      { return "contest:" + eventID }
+func contestAdminRoom(eventID string) string { return "contest:" + eventID + ":admin" }
+
+// Standings last broadcast to each contest room, so judged submissions push only the rows that changed
+var (
+	pushedStandings   = make(map[string][]services.LeaderboardEntry) // room -> standings
+	pushedStandingsMu sync.Mutex                                     // Guards the map only
+	roomLocks         sync.Map                                       // room -> *sync.Mutex
+)
+
+// lockRoom serializes the scoreboard pushes of one room, so concurrent judges can't
+// push its deltas out of order while other contests go ahead. Call the result to unlock.
+func lockRoom(room string) func() {
+	mu, _ := roomLocks.LoadOrStore(room, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+func pushedBoard(room string) ([]services.LeaderboardEntry, bool) {
+	pushedStandingsMu.Lock()
+	defer pushedStandingsMu.Unlock()
+	standings, ok := pushedStandings[room]
+	return standings, ok
+}
+
+// setPushedBoard records what room's clients hold; nil forgets it
+func setPushedBoard(room string, standings []services.LeaderboardEntry) {
+	pushedStandingsMu.Lock()
+	defer pushedStandingsMu.Unlock()
+	if standings == nil {
+		delete(pushedStandings, room)
+		return
+	}
+	pushedStandings[room] = standings
+}
+
+// registerContestRooms adds the contest room events to the socket server
+func registerContestRooms(server *socketio.Server) {
+	server.OnEvent("/", "join_contest", func(s socketio.Conn, eventID string) {
+		userID, _ := s.Context().(string)
+		if userID == "" || eventID == "" {
+			return
+		}
+
+		var event models.Event
+		if err := database.DB.Select("id").First(&event, "id = ?", eventID).Error; err != nil {
+			s.Emit("contest_error", map[string]interface{}{"eventId": eventID, "error": "Event not found"})
+			return
+		}
+
+		asAdmin := isStaffUser(userID)
+		if !asAdmin && event.ID != "practice-arena-mvp" {
+			var count int64
+			database.DB.Model(&models.Registration{}).Where("user_id = ? AND event_id = ?", userID, eventID).Count(&count)
+			if count == 0 {
+				s.Emit("contest_error", map[string]interface{}{"eventId": eventID, "error": "Not registered"})
+				return
+			}
+		}
+
+		room := contestRoom(eventID)
+		if asAdmin {
+			room = contestAdminRoom(eventID)
+		}
+		s.Join(room)
+		log.Println("User joined contest room:", room, "User:", userID)
+
+		// Full board first; scoreboard_delta events patch it from here on
+		unlock := lockRoom(room)
+		standings, err := services.GetLeaderboard(eventID, asAdmin)
+		if err != nil {
+			standings = []services.LeaderboardEntry{}
+		}
+		if _, ok := pushedBoard(room); !ok {
+			setPushedBoard(room, standings)
+		}
+		unlock()
+		s.Emit("contest_scoreboard", map[string]interface{}{
+			"eventId":     eventID,
+			"frozenView":  !asAdmin,
+			"leaderboard": standings,
+		})
+	})
+
+	server.OnEvent("/", "leave_contest", func(s socketio.Conn, eventID string) {
+		s.Leave(contestRoom(eventID))
+		s.Leave(contestAdminRoom(eventID))
+	})
+}
+
+// pushContestResult notifies the submitter of their verdict and pushes scoreboard deltas
+// to the contest rooms. Called by the judge once a submission is saved.
+func pushContestResult(sub *models.Submission) {
+	if SocketServer == nil {
+		return
+	}
+
+	// The submitter always learns their own verdict, frozen or not
+	SocketServer.BroadcastToRoom("/", sub.UserID, "contest_verdict", map[string]interface{}{
+		"submissionId":    sub.ID,
+		"eventId":         sub.EventID,
+		"problemId":       sub.ProblemID,
+		"status":          sub.Status,
+		"verdict":         sub.Verdict,
+		"score":           sub.Score,
+		"testCasesPassed": sub.TestCasesPassed,
+		"totalTestCases":  sub.TotalTestCases,
+		"runtime":         sub.Runtime,
+		"memory":          sub.Memory,
+	})
+
+	// Virtual submissions only ever show on their ghost leaderboard
+	if sub.VirtualID != nil {
+		return
+	}
+	broadcastScoreboardDelta(sub.EventID, true)
+	broadcastScoreboardDelta(sub.EventID, false)
+}
+
+// broadcastScoreboardDelta sends the rows of one view of the scoreboard that changed
+// since the last push, and the IDs of rows to drop. Frozen public boards only ever
+// change by PENDING cells.
+func broadcastScoreboardDelta(eventID string, asAdmin bool) {
+	room := contestRoom(eventID)
+	if asAdmin {
+		room = contestAdminRoom(eventID)
+	}
+
+	// Held across the computation so concurrent judges can't push deltas out of order
+	defer lockRoom(room)()
+
+	if SocketServer.RoomLen("/", room) == 0 {
+		setPushedBoard(room, nil) // Nobody watching; the next joiner gets a full board
+		return
+	}
+
+	standings, err := services.GetLeaderboard(eventID, asAdmin)
+	if err != nil {
+		log.Println("Failed to compute scoreboard delta:", eventID, err)
+		return
+	}
+	prev, _ := pushedBoard(room)
+	rows, removed := services.DiffStandings(prev, standings)
+	setPushedBoard(room, standings)
+	if len(rows) == 0 && len(removed) == 0 {
+		return
+	}
+
+	SocketServer.BroadcastToRoom("/", room, "scoreboard_delta", map[string]interface{}{
+		"eventId":      eventID,
+		"rows":         rows,
+		"removed":      removed,
+		"participants": len(standings),
+	})
+}
+
+// broadcastResolverSteps sends revealed cells to everyone watching the contest, in order
+func broadcastResolverSteps(eventID string, steps []services.ResolverStep) {
+	if SocketServer == nil || len(steps) == 0 {
+		return
+	}
+	for _, step := range steps {
+		SocketServer.BroadcastToRoom("/", contestRoom(eventID), "resolver_step", map[string]interface{}{
+			"eventId": eventID,
+			"step":    step,
+		})
+	}
+
+	// The public board moved without a judged submission; rebase future deltas
+	room := contestRoom(eventID)
+	defer lockRoom(room)()
+	setPushedBoard(room, nil)
+}
+
+// broadcastResolverDone tells both rooms the board is unfrozen and sends it in full
+func broadcastResolverDone(eventID string, standings []services.LeaderboardEntry) {
+	if SocketServer == nil {
+		return
+	}
+	for _, room := range []string{contestRoom(eventID), contestAdminRoom(eventID)} {
+		SocketServer.BroadcastToRoom("/", room, "resolver_done", map[string]interface{}{
+			"eventId":     eventID,
+			"leaderboard": standings,
+		})
+	}
+
+	room := contestRoom(eventID)
+	defer lockRoom(room)()
+	setPushedBoard(room, standings)
+}
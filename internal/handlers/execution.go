@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -14,6 +15,20 @@ const (
 	MaxStdinSizeBytes = 16 * 1024
 )
 
+// executor is the code-execution backend shared by all run/submit handlers.
+// main wires the configured backend via SetExecutor; tests inject a fake.
+var executor services.Executor = services.NewPistonExecutor(services.DefaultPistonURL)
+
+// SetExecutor replaces the code-execution backend used by the handlers
+func SetExecutor(e services.Executor) {
+	executor = e
+}
+
+// GetExecutor returns the code-execution backend used by the handlers
+func GetExecutor() services.Executor {
+	return executor
+}
+
 type ExecuteRequest struct {
 	Language string `json:"language" binding:"required"`
 	Code     string `json:"code" binding:"required"`
@@ -45,7 +60,11 @@ func ExecuteCode(c *gin.Context) {
 	}
 
 	// P0 FIX: Enforce timeout (2s) and memory limits (128MB) - don't use 0 defaults
-	result, err := services.ExecuteCode(req.Language, req.Code, req.Stdin, 2.0, 128)
+	result, err := services.ExecuteCode(executor, req.Language, req.Code, req.Stdin, services.ExecuteOptions{TimeLimit: 2.0, MemoryLimit: 128})
+	if errors.Is(err, services.ErrExecutionRejected) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Execution failed: " + err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Execution failed: " + err.Error()})
 		return
@@ -8,7 +8,6 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/pushp314/devconnect-backend/internal/database"
 	"github.com/pushp314/devconnect-backend/internal/models"
-	"github.com/pushp314/devconnect-backend/internal/services"
 	"gorm.io/gorm"
 )
 
@@ -64,8 +63,7 @@ func CreateFeedback(c *gin.Context) {
 	go database.CacheInvalidate("feedback:latest*")
 
 	// 5. Check for Badges
-	newBadges, _ := services.CheckBadges(userID)
-	NotifyNewBadges(userID, newBadges)
+	newBadges := recordBadgeEvent(userID, models.MetricFeedbackGiven, 1)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message":   feedback,
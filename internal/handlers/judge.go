@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/judge"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
+	"github.com/pushp314/devconnect-backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// judgeQueue receives every contest and practice submission; see InitJudgeQueue
+var judgeQueue *services.JudgeQueue
+
+// InitJudgeQueue registers the submission judges on the queue and makes it
+// available to the submit handlers. Call before queue.Start().
+func InitJudgeQueue(q *services.JudgeQueue) {
+	q.Handle(models.JudgeJobContest, services.JudgeHandler{
+		Judge:  judgeContestSubmission,
+		GiveUp: giveUpContestSubmission,
+	})
+	q.Handle(models.JudgeJobPractice, services.JudgeHandler{
+		Judge:  judgePracticeSubmission,
+		GiveUp: giveUpPracticeSubmission,
+	})
+	judgeQueue = q
+}
+
+// judgeContestSubmission runs a contest submission against all test cases (sequentially,
+// stopping at the first failure except in IOI mode) and updates the participant's score
+func judgeContestSubmission(job models.JudgeJob) error {
+	var sub models.Submission
+	if err := database.DB.First(&sub, "id = ?", job.SubmissionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // Deleted, nothing to judge
+		}
+		return err
+	}
+	// Idempotency: a recovered duplicate job must not re-judge a finished submission
+	if sub.Status != models.SubStatusPending {
+		return nil
+	}
+
+	var prob models.Problem
+	if err := database.DB.Preload("TestCases").First(&prob, "id = ?", sub.ProblemID).Error; err != nil {
+		return err
+	}
+
+	// Late submissions (upsolving) are judged but never score
+	isLate := false
+	var event models.Event
+	if err := database.DB.First(&event, "id = ?", sub.EventID).Error; err == nil && event.ID != "practice-arena-mvp" {
+		isLate = sub.CreatedAt.After(event.EndTime)
+	}
+
+	partial := event.ScoringMode == models.ScoringIOI
+	cases := judge.ContestCases(prob.TestCases)
+	result, err := judge.Run(executor, sub.Language, sub.Code, cases, judge.Options{
+		TimeLimit:   prob.TimeLimit,
+		MemoryLimit: prob.MemoryLimit,
+		Checker:     judge.NewChecker(executor, prob.CheckerConfig),
+		NoCache:     true, // Contest verdicts and runtimes must come from a real run
+		Partial:     partial,
+	})
+	if errors.Is(err, judge.ErrCheckerFailed) {
+		// Retrying can't fix the problem's checker; staff have to
+		logger.Error().Err(err).Str("problem", prob.ID).Str("submission", sub.ID).Msg("Problem checker is broken")
+		giveUpContestSubmission(job, err)
+		return nil
+	}
+	if err != nil {
+		return err // Executor unavailable: retry later, keep PENDING
+	}
+	allPassed := result.Status == models.SubStatusAC
+
+	sub.Status = result.Status
+	sub.Verdict = result.Verdict
+	sub.TestCasesPassed = result.Passed
+	sub.TotalTestCases = result.Total
+	sub.Runtime = result.Runtime
+	sub.Memory = result.Memory
+	sub.CompileTime = result.CompileTime
+	sub.CompileOutput = result.CompileOutput
+	sub.CaseResults = result.Cases
+	switch {
+	case partial:
+		sub.Score = judge.Score(cases, result, prob.TestGroups, prob.Points)
+	case allPassed:
+		sub.Score = prob.Points
+	}
+	if result.LastRun != nil {
+		// Convert execution output to snapshot
+		snap, _ := json.Marshal(result.LastRun)
+		sub.OutputSnapshot = string(snap)
+	}
+
+	database.DB.Save(&sub)
+	services.InvalidateLeaderboardCache(sub.EventID)
+
+	// Update Registration Score when the submission earned points (Only if ON TIME).
+	// Recomputed from all submissions under the event's scoring mode, so re-judges don't double count.
+	if sub.Score > 0 && !isLate {
+		if score, err := services.ParticipantScore(sub.EventID, sub.UserID, sub.TeamID); err == nil {
+			query := database.DB.Model(&models.Registration{}).Where("event_id = ?", sub.EventID)
+			if sub.TeamID != nil {
+				// Every member's registration carries the team's score
+				query = query.Where("team_id = ?", *sub.TeamID)
+			} else {
+				query = query.Where("user_id = ?", sub.UserID)
+			}
+			query.Update("score", score)
+		}
+	}
+
+	if sub.Status == models.SubStatusAC {
+		// Virtual runs are always after EndTime but face the same rules as the real contest
+		if !isLate || sub.VirtualID != nil {
+			checkFastAccept(&sub, &event)
+		}
+		recordBadgeEvent(sub.UserID, models.MetricSolveStreak, 1)
+	}
+
+	pushContestResult(&sub)
+	return nil
+}
+
+// checkFastAccept runs the verdict-time anti-cheat rules on an accepted submission that
+// was the participant's first try at the problem
+func checkFastAccept(sub *models.Submission, event *models.Event) {
+	if event.ID == "" || event.ID == "practice-arena-mvp" {
+		return
+	}
+	var earlier int64
+	database.DB.Model(&models.Submission{}).
+		Where("user_id = ? AND problem_id = ? AND created_at < ? AND id != ?", sub.UserID, sub.ProblemID, sub.CreatedAt, sub.ID).
+		Count(&earlier)
+	if earlier > 0 {
+		return
+	}
+
+	// The participant's clock starts with the contest, a late registration or their virtual run
+	start := event.StartTime
+	if sub.VirtualID != nil {
+		var vp models.VirtualParticipation
+		if err := database.DB.First(&vp, "id = ?", *sub.VirtualID).Error; err != nil {
+			return
+		}
+		start = vp.StartedAt
+	} else {
+		var reg models.Registration
+		if err := database.DB.Where("user_id = ? AND event_id = ?", sub.UserID, sub.EventID).First(&reg).Error; err == nil && reg.CreatedAt.After(start) {
+			start = reg.CreatedAt
+		}
+	}
+
+	rules, err := services.AntiCheatRulesForEvent(database.DB, event.ID)
+	if err != nil {
+		logger.Error().Err(err).Str("submission", sub.ID).Msg("Failed to load anti-cheat rules")
+		return
+	}
+	results := services.EvaluateAntiCheat(rules, services.AntiCheatSignals{
+		models.ConditionFastAccept: sub.CreatedAt.Sub(start).Seconds(),
+	})
+	if err := services.RecordAntiCheat(database.DB, sub.ID, results); err != nil {
+		logger.Error().Err(err).Str("submission", sub.ID).Msg("Failed to record anti-cheat results")
+	}
+	if err := services.ApplyAntiCheat(database.DB, sub, results); err != nil {
+		logger.Error().Err(err).Str("submission", sub.ID).Msg("Failed to apply anti-cheat rules")
+	}
+}
+
+// giveUpContestSubmission records a judge failure once retries are exhausted
+func giveUpContestSubmission(job models.JudgeJob, err error) {
+	res := database.DB.Model(&models.Submission{}).
+		Where("id = ? AND status = ?", job.SubmissionID, models.SubStatusPending).
+		Updates(map[string]interface{}{
+			"status":  models.SubStatusRE,
+			"verdict": models.JudgeErrorVerdict + err.Error(),
+		})
+	if res.RowsAffected == 0 {
+		return
+	}
+
+	var sub models.Submission
+	if database.DB.First(&sub, "id = ?", job.SubmissionID).Error == nil {
+		services.InvalidateLeaderboardCache(sub.EventID)
+		pushContestResult(&sub)
+	}
+}
+
+// judgePracticeSubmission judges a practice submission like a contest one and applies gamification on AC
+func judgePracticeSubmission(job models.JudgeJob) error {
+	var submission models.PracticeSubmission
+	if err := database.DB.First(&submission, "id = ?", job.SubmissionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if submission.Status != "PENDING" && submission.Status != "RUNNING" {
+		return nil
+	}
+
+	var problem models.PracticeProblem
+	if err := database.DB.First(&problem, "id = ?", submission.ProblemID).Error; err != nil {
+		return err
+	}
+
+	database.DB.Model(&submission).Update("status", "RUNNING")
+
+	cases, err := judge.PracticeCases(problem.TestCases)
+	if err != nil {
+		submission.Status = "ERROR"
+		submission.Error = err.Error()
+		database.DB.Save(&submission)
+		return nil
+	}
+
+	result, err := judge.Run(executor, submission.Language, submission.Code, cases, judge.Options{
+		TimeLimit:   float64(problem.TimeLimit),
+		MemoryLimit: problem.MemoryLimit,
+		Checker:     judge.NewChecker(executor, problem.CheckerConfig),
+	})
+	if errors.Is(err, judge.ErrCheckerFailed) {
+		logger.Error().Err(err).Str("problem", problem.ID).Str("submission", submission.ID).Msg("Practice problem checker is broken")
+		giveUpPracticeSubmission(job, err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	submission.Status = string(result.Status)
+	submission.Verdict = result.Verdict
+	submission.TestsPassed = result.Passed
+	submission.TestsTotal = result.Total
+	submission.ExecutionTime = int(result.Runtime)
+	submission.MemoryUsed = result.Memory
+	submission.CaseResults = result.Cases
+	submission.CompileTime = int(result.CompileTime)
+	submission.CompileOutput = result.CompileOutput
+	submission.Output, submission.Error = "", ""
+	switch {
+	case result.Status == models.SubStatusCE:
+		submission.Error = result.CompileOutput
+	case result.LastRun != nil:
+		submission.Output = result.LastRun.Run.Stdout
+		submission.Error = result.LastRun.Run.Stderr
+	default:
+		submission.Error = result.Verdict // Rejected before running
+	}
+
+	database.DB.Save(&submission)
+
+	// GAMIFICATION & STATS
+	if result.Status == models.SubStatusAC {
+		// 1. Update Solve Count (if first time)
+		var prevSolves int64
+		database.DB.Model(&models.PracticeSubmission{}).
+			Where("\"userId\" = ? AND \"problemId\" = ? AND status = ? AND id != ?",
+				submission.UserID, submission.ProblemID, models.SubStatusAC, submission.ID).
+			Count(&prevSolves)
+
+		if prevSolves == 0 {
+			database.DB.Model(&problem).Update("solve_count", gorm.Expr("solve_count + 1"))
+
+			// 2. Badge progress
+			recordBadgeEvent(submission.UserID, models.MetricPracticeSolved, 1)
+		}
+		recordBadgeEvent(submission.UserID, models.MetricSolveStreak, 1)
+
+		// 3. Daily problem streak (judged late submissions count for the day they were made)
+		if _, _, err := services.RecordDailySolve(database.DB, submission.UserID, submission.ProblemID, submission.CreatedAt); err != nil {
+			logger.Error().Err(err).Str("submission", submission.ID).Msg("Failed to record daily solve")
+		}
+
+		// 4. Suggest the next problem, now that this one counts as solved
+		recordNextProblem(&submission)
+	}
+
+	return nil
+}
+
+// recordNextProblem stores the user's top recommendation on an accepted submission
+func recordNextProblem(submission *models.PracticeSubmission) {
+	var user models.User
+	if err := database.DB.Select("id, preferred_languages, interests").First(&user, "id = ?", submission.UserID).Error; err != nil {
+		return
+	}
+	recs, err := services.RecommendPracticeProblems(database.DB, &user, 1)
+	if err != nil {
+		logger.Error().Err(err).Str("submission", submission.ID).Msg("Failed to recommend next practice problem")
+		return
+	}
+	if len(recs) > 0 {
+		submission.NextProblemID = recs[0].Problem.ID
+		database.DB.Model(submission).Update("next_problem_id", submission.NextProblemID)
+	}
+}
+
+// giveUpPracticeSubmission records a judge failure once retries are exhausted
+func giveUpPracticeSubmission(job models.JudgeJob, err error) {
+	database.DB.Model(&models.PracticeSubmission{}).
+		Where("id = ? AND status IN ?", job.SubmissionID, []string{"PENDING", "RUNNING"}).
+		Updates(map[string]interface{}{
+			"status": "ERROR",
+			"error":  err.Error(),
+		})
+}
+
+// redactSubmission hides hidden-test data from non-staff viewers, including the
+// raw snapshot of the last run when that run was a hidden case
+func redactSubmission(sub *models.Submission) {
+	if n := len(sub.CaseResults); n > 0 && sub.CaseResults[n-1].Hidden {
+		sub.OutputSnapshot = ""
+	}
+	sub.CaseResults = judge.Redact(sub.CaseResults)
+}
+
+// redactPracticeSubmission is redactSubmission for practice submissions
+func redactPracticeSubmission(sub *models.PracticeSubmission) {
+	if n := len(sub.CaseResults); n > 0 && sub.CaseResults[n-1].Hidden {
+		sub.Output, sub.Error = "", ""
+	}
+	sub.CaseResults = judge.Redact(sub.CaseResults)
+	redactPracticeProblem(&sub.Problem, false)
+}
+
+// redactPracticeProblem hides a practice problem's solution, and its hidden test cases
+// from anyone but staff
+func redactPracticeProblem(p *models.PracticeProblem, staff bool) {
+	p.SolutionCode = ""
+	if !staff {
+		p.TestCases = judge.PublicPracticeCases(p.TestCases)
+	}
+}
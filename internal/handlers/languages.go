@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
+	"gorm.io/gorm"
+)
+
+// RuntimeInfo is a registry language as shown to clients, with backend availability
+type RuntimeInfo struct {
+	ID                string   `json:"id"`
+	DisplayName       string   `json:"displayName"`
+	Aliases           []string `json:"aliases"`
+	FileName          string   `json:"fileName"`
+	Version           string   `json:"version"`
+	TimeLimit         float64  `json:"timeLimit"`
+	MemoryLimit       int      `json:"memoryLimit"`
+	BlockedImports    []string `json:"blockedImports"`
+	ClientSide        bool     `json:"clientSide"`
+	Available         bool     `json:"available"`
+	InstalledVersions []string `json:"installedVersions,omitempty"`
+}
+
+// GetRuntimes lists the enabled languages and whether the execution backend can run them
+func GetRuntimes(c *gin.Context) {
+	runtimes := []RuntimeInfo{}
+	for _, lang := range services.ListLanguages() {
+		if !lang.Enabled {
+			continue
+		}
+		info := RuntimeInfo{
+			ID:             lang.ID,
+			DisplayName:    lang.DisplayName,
+			Aliases:        lang.Aliases,
+			FileName:       lang.FileName,
+			Version:        lang.Version,
+			TimeLimit:      lang.TimeLimit,
+			MemoryLimit:    lang.MemoryLimit,
+			BlockedImports: lang.BlockedImports,
+			ClientSide:     lang.ClientSide,
+			Available:      lang.ClientSide,
+		}
+		if !lang.ClientSide {
+			info.InstalledVersions = services.InstalledVersions(executor, lang.Runtime)
+			info.Available = versionInstalled(lang.Version, info.InstalledVersions)
+		}
+		runtimes = append(runtimes, info)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runtimes": runtimes})
+}
+
+// versionInstalled reports whether a pinned version ("*" = any) is on the backend
+func versionInstalled(pinned string, installed []string) bool {
+	if len(installed) == 0 {
+		return false
+	}
+	if pinned == "" || pinned == "*" {
+		return true
+	}
+	for _, v := range installed {
+		if v == pinned {
+			return true
+		}
+	}
+	return false
+}
+
+// checkEventLanguage rejects unknown or disabled languages and those the event does not allow
+func checkEventLanguage(event *models.Event, language string) error {
+	if err := services.ValidateLanguage(language); err != nil {
+		return err
+	}
+	if !event.AllowsLanguage(services.CanonicalLanguage(language)) {
+		return fmt.Errorf("%s is not allowed in this contest (allowed: %s)", language, strings.Join(event.AllowedLanguages, ", "))
+	}
+	return nil
+}
+
+// normalizeAllowedLanguages validates an event's allowed list and stores registry IDs
+func normalizeAllowedLanguages(languages []string) (pq.StringArray, error) {
+	out := pq.StringArray{}
+	seen := make(map[string]bool)
+	for _, name := range languages {
+		lang, ok := services.LookupLanguage(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown language %q", name)
+		}
+		if !seen[lang.ID] {
+			seen[lang.ID] = true
+			out = append(out, lang.ID)
+		}
+	}
+	return out, nil
+}
+
+// --- Admin: Language Registry ---
+
+type languageInput struct {
+	DisplayName    string   `json:"displayName"`
+	Runtime        string   `json:"runtime"`
+	Aliases        []string `json:"aliases"`
+	FileName       string   `json:"fileName"`
+	Version        string   `json:"version"`
+	TimeLimit      *float64 `json:"timeLimit"`
+	MemoryLimit    *int     `json:"memoryLimit"`
+	BlockedImports []string `json:"blockedImports"`
+	ClientSide     *bool    `json:"clientSide"`
+	Enabled        *bool    `json:"enabled"`
+}
+
+// validate checks the limits and that aliases don't already name another language
+func (in *languageInput) validate(id string) error {
+	if in.TimeLimit != nil && (*in.TimeLimit <= 0 || *in.TimeLimit > 60) {
+		return errors.New("timeLimit must be between 0 and 60 seconds")
+	}
+	if in.MemoryLimit != nil && (*in.MemoryLimit < 0 || *in.MemoryLimit > 4096) {
+		return errors.New("memoryLimit must be between 0 and 4096 MB")
+	}
+	for _, alias := range in.Aliases {
+		if other, ok := services.LookupLanguage(alias); ok && other.ID != id {
+			return fmt.Errorf("alias %q already belongs to %s", alias, other.ID)
+		}
+	}
+	return nil
+}
+
+func AdminListLanguages(c *gin.Context) {
+	var languages []models.Language
+	if err := database.DB.Order("id asc").Find(&languages).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB Error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"languages": languages})
+}
+
+func AdminCreateLanguage(c *gin.Context) {
+	adminID := getAdminID(c)
+
+	var req struct {
+		ID string `json:"id" binding:"required"`
+		languageInput
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id := strings.ToLower(strings.TrimSpace(req.ID))
+	if existing, ok := services.LookupLanguage(id); ok {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("%q already resolves to %s", id, existing.ID)})
+		return
+	}
+	if err := req.validate(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lang := models.Language{
+		ID:             id,
+		DisplayName:    req.DisplayName,
+		Runtime:        req.Runtime,
+		Aliases:        pq.StringArray(req.Aliases),
+		FileName:       req.FileName,
+		Version:        req.Version,
+		TimeLimit:      5,
+		BlockedImports: pq.StringArray(req.BlockedImports),
+		Enabled:        true,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	if lang.DisplayName == "" {
+		lang.DisplayName = id
+	}
+	if lang.Runtime == "" {
+		lang.Runtime = id
+	}
+	if lang.FileName == "" {
+		lang.FileName = "code.txt"
+	}
+	if lang.Version == "" {
+		lang.Version = "*"
+	}
+	if req.TimeLimit != nil {
+		lang.TimeLimit = *req.TimeLimit
+	}
+	if req.MemoryLimit != nil {
+		lang.MemoryLimit = *req.MemoryLimit
+	}
+	if req.ClientSide != nil {
+		lang.ClientSide = *req.ClientSide
+	}
+	if req.Enabled != nil {
+		lang.Enabled = *req.Enabled
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&lang).Error; err != nil {
+			return err
+		}
+		return logAdminAction(tx, adminID, models.ActionCreateLanguage, lang.ID, "language", "Created Language: "+lang.DisplayName)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create language: " + err.Error()})
+		return
+	}
+	services.ReloadLanguages()
+
+	c.JSON(http.StatusCreated, gin.H{"language": lang})
+}
+
+func AdminUpdateLanguage(c *gin.Context) {
+	id := c.Param("id")
+	adminID := getAdminID(c)
+
+	var req languageInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.validate(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var lang models.Language
+		if err := tx.First(&lang, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		if req.DisplayName != "" {
+			updates["display_name"] = req.DisplayName
+		}
+		if req.Runtime != "" {
+			updates["runtime"] = req.Runtime
+		}
+		if req.Aliases != nil {
+			updates["aliases"] = pq.StringArray(req.Aliases)
+		}
+		if req.FileName != "" {
+			updates["file_name"] = req.FileName
+		}
+		if req.Version != "" {
+			updates["version"] = req.Version
+		}
+		if req.TimeLimit != nil {
+			updates["time_limit"] = *req.TimeLimit
+		}
+		if req.MemoryLimit != nil {
+			updates["memory_limit"] = *req.MemoryLimit
+		}
+		if req.BlockedImports != nil {
+			updates["blocked_imports"] = pq.StringArray(req.BlockedImports)
+		}
+		if req.ClientSide != nil {
+			updates["client_side"] = *req.ClientSide
+		}
+		if req.Enabled != nil {
+			updates["enabled"] = *req.Enabled
+		}
+		updates["updated_at"] = time.Now()
+
+		if err := tx.Model(&lang).Updates(updates).Error; err != nil {
+			return err
+		}
+		return logAdminAction(tx, adminID, models.ActionUpdateLanguage, id, "language", "Admin Updated Language")
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Language not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	services.ReloadLanguages()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Language Updated"})
+}
+
+// AdminDeleteLanguage removes a registry entry. Contests still listing it keep the ID,
+// but submissions in it are rejected as unknown; disable it instead to keep history readable.
+func AdminDeleteLanguage(c *gin.Context) {
+	id := c.Param("id")
+	adminID := getAdminID(c)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.Language{}, "id = ?", id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return logAdminAction(tx, adminID, models.ActionDeleteLanguage, id, "language", "Deleted Language")
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Language not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	services.ReloadLanguages()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Language Deleted"})
+}
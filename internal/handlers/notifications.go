@@ -3,10 +3,13 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pushp314/devconnect-backend/internal/database"
 	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
+	"github.com/pushp314/devconnect-backend/pkg/logger"
 	"gorm.io/gorm"
 )
 
@@ -170,3 +173,15 @@ func NotifyNewBadges(userID string, badges []models.Badge) {
 		CreateNotification(database.DB, notification)
 	}
 }
+
+// recordBadgeEvent feeds a domain event to the badge engine and notifies the user of
+// the badges it unlocked. Failures are logged: badges never block the action itself.
+func recordBadgeEvent(userID string, metric models.BadgeMetric, value int) []models.Badge {
+	badges, err := services.RecordBadgeEvent(database.DB, userID, metric, value, time.Now())
+	if err != nil {
+		logger.Error().Err(err).Str("user", userID).Str("metric", string(metric)).Msg("Failed to record badge event")
+		return nil
+	}
+	NotifyNewBadges(userID, badges)
+	return badges
+}
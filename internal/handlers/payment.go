@@ -4,6 +4,8 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"math"
 	"net/http"
 	"os"
 	"time"
@@ -13,14 +15,17 @@ import (
 	"github.com/pushp314/devconnect-backend/internal/models"
 	"github.com/pushp314/devconnect-backend/pkg/utils"
 	razorpay "github.com/razorpay/razorpay-go"
+	"gorm.io/gorm"
 )
 
 type CreateOrderInput struct {
 	EventID string `json:"eventId" binding:"required"`
+	TeamID  string `json:"teamId"` // Required for team events: the owner pays for the team
 }
 
 type VerifyPaymentInput struct {
 	EventID           string `json:"eventId" binding:"required"`
+	TeamID            string `json:"teamId"` // Rejected: teams register through RegisterTeamForEvent
 	RazorpayPaymentID string `json:"razorpay_payment_id" binding:"required"`
 	RazorpayOrderID   string `json:"razorpay_order_id" binding:"required"`
 	RazorpaySignature string `json:"razorpay_signature" binding:"required"`
@@ -45,6 +50,28 @@ func CreateOrder(c *gin.Context) {
 		return
 	}
 
+	userID := c.MustGet("userId").(string)
+	receipt := "receipt_" + input.EventID
+	notes := map[string]interface{}{"event_id": event.ID, "user_id": userID}
+	if event.IsTeamEvent() {
+		if input.TeamID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "teamId is required for team contests"})
+			return
+		}
+		team, err := loadTeam(database.DB, input.TeamID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+			return
+		}
+		var regErr *teamRegistrationError
+		if err := checkTeamRegistration(database.DB, &event, team, userID); errors.As(err, &regErr) {
+			c.JSON(regErr.status, gin.H{"error": regErr.message})
+			return
+		}
+		receipt += "_" + team.ID
+		notes["team_id"] = team.ID
+	}
+
 	keyID := os.Getenv("RAZORPAY_KEY_ID")
 	keySecret := os.Getenv("RAZORPAY_KEY_SECRET")
 
@@ -59,7 +86,8 @@ func CreateOrder(c *gin.Context) {
 	data := map[string]interface{}{
 		"amount":   amountInPaise,
 		"currency": "INR",
-		"receipt":  "receipt_" + input.EventID,
+		"receipt":  receipt,
+		"notes":    notes,
 	}
 
 	body, err := client.Order.Create(data, nil)
@@ -88,16 +116,26 @@ func VerifyPayment(c *gin.Context) {
 	keySecret := os.Getenv("RAZORPAY_KEY_SECRET")
 
 	// signature verification
-	data := input.RazorpayOrderID + "|" + input.RazorpayPaymentID
-	h := hmac.New(sha256.New, []byte(keySecret))
-	h.Write([]byte(data))
-	expectedSignature := hex.EncodeToString(h.Sum(nil))
-
-	if expectedSignature != input.RazorpaySignature {
+	if !validRazorpaySignature(keySecret, input.RazorpayOrderID, input.RazorpayPaymentID, input.RazorpaySignature) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid signature"})
 		return
 	}
 
+	if input.TeamID != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Teams register through POST /events/:id/register-team"})
+		return
+	}
+
+	var event models.Event
+	if err := database.DB.First(&event, "id = ?", input.EventID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+	if event.IsTeamEvent() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This is a team contest, register with your team"})
+		return
+	}
+
 	// Update Registration Status
 	userID := c.MustGet("userId").(string)
 
@@ -117,6 +155,7 @@ func VerifyPayment(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save registration"})
 			return
 		}
+		recordBadgeEvent(userID, models.MetricContestsJoined, 1)
 	} else {
 		registration.Status = models.RegStatusPaid
 		registration.PaymentID = input.RazorpayPaymentID
@@ -126,6 +165,53 @@ func VerifyPayment(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Payment verified and registered"})
 }
 
+var (
+	errOrderMismatch = errors.New("payment order does not match this registration")
+	errPaymentUsed   = errors.New("payment already used for another registration")
+)
+
+// fetchRazorpayOrder loads an order from the gateway; tests replace it
+var fetchRazorpayOrder = func(orderID string) (map[string]interface{}, error) {
+	client := razorpay.NewClient(os.Getenv("RAZORPAY_KEY_ID"), os.Getenv("RAZORPAY_KEY_SECRET"))
+	return client.Order.Fetch(orderID, nil, nil)
+}
+
+// checkTeamOrder looks the order up server-side: it must be the one CreateOrder made
+// for this event and team, for the event's price
+func checkTeamOrder(event *models.Event, team *models.Team, orderID string) error {
+	order, err := fetchRazorpayOrder(orderID)
+	if err != nil {
+		return err
+	}
+	amount, _ := order["amount"].(float64)
+	notes, _ := order["notes"].(map[string]interface{}) // Razorpay sends [] when empty
+	if math.Round(amount) != math.Round(event.Price*100) || notes["event_id"] != event.ID || notes["team_id"] != team.ID {
+		return errOrderMismatch
+	}
+	return nil
+}
+
+// claimPayment fails when a payment already backs a registration. Team members share
+// their team's payment, so this is a check rather than a unique index; run it in the
+// registering transaction.
+func claimPayment(tx *gorm.DB, paymentID string) error {
+	var used int64
+	if err := tx.Model(&models.Registration{}).Where("payment_id = ?", paymentID).Count(&used).Error; err != nil {
+		return err
+	}
+	if used > 0 {
+		return errPaymentUsed
+	}
+	return nil
+}
+
+// validRazorpaySignature checks a checkout callback's signature over order|payment
+func validRazorpaySignature(secret, orderID, paymentID, signature string) bool {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(orderID + "|" + paymentID))
+	return hmac.Equal([]byte(hex.EncodeToString(h.Sum(nil))), []byte(signature))
+}
+
 // Handler for Webhook if needed (e.g. async confirmation)
 // Currently VerifyPayment is synchronous via frontend callback.
 func HandleRazorpayWebhook(c *gin.Context) {
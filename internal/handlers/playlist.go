@@ -1,14 +1,17 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pushp314/devconnect-backend/internal/database"
 	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
 	"github.com/pushp314/devconnect-backend/pkg/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CreatePlaylistInput struct {
@@ -81,22 +84,30 @@ func GetPlaylist(c *gin.Context) {
 
 	// Check progress if authenticated
 	currentUserID, exists := c.Get("userId")
-	completedCount := 0
+	progress := services.NewPlaylistProgressSummary(0, len(playlist.Items))
 	if exists {
+		done, err := services.PlaylistCompletions(database.DB, currentUserID.(string), []string{playlist.ID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch progress"})
+			return
+		}
+		completedCount := 0
 		for i, item := range playlist.Items {
-			var view models.EntityView
-			if err := database.DB.Where("user_id = ? AND entity_type = ? AND entity_id = ?",
-				currentUserID.(string), models.EntityTypeSnippet, item.SnippetID).First(&view).Error; err == nil {
+			if at, ok := done[playlist.ID][item.SnippetID]; ok {
 				playlist.Items[i].IsCompleted = true
+				playlist.Items[i].CompletedAt = &at
 				completedCount++
 			}
 		}
+		progress = services.NewPlaylistProgressSummary(completedCount, len(playlist.Items))
+		playlist.Progress = &progress
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"playlist":       playlist,
-		"completedCount": completedCount,
-		"totalCount":     len(playlist.Items),
+		"completedCount": progress.Completed,
+		"totalCount":     progress.Total,
+		"percent":        progress.Percent,
 	})
 }
 
@@ -147,6 +158,22 @@ func ListPlaylists(c *gin.Context) {
 		return
 	}
 
+	if hasAuth && len(playlists) > 0 {
+		ids := make([]string, len(playlists))
+		for i, p := range playlists {
+			ids[i] = p.ID
+		}
+		summaries, err := services.SummarizePlaylistProgress(database.DB, currentUserID.(string), ids)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch progress"})
+			return
+		}
+		for i := range playlists {
+			summary := summaries[playlists[i].ID]
+			playlists[i].Progress = &summary
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{"playlists": playlists})
 }
 
@@ -267,7 +294,52 @@ func DeletePlaylist(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Playlist deleted"})
 }
 
-// ClaimEndorsement handles POST /playlists/:id/claim
+// MarkPlaylistItem handles POST /playlists/:id/snippets/:snippetId/complete, marking an
+// item done explicitly (viewing or running the snippet also does)
+func MarkPlaylistItem(c *gin.Context) {
+	playlistID := c.Param("id")
+	snippetID := c.Param("snippetId")
+	userID := c.MustGet("userId").(string)
+
+	var item models.PlaylistSnippet
+	if err := database.DB.Where("playlist_id = ? AND snippet_id = ?", playlistID, snippetID).First(&item).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Snippet is not in this track"})
+		return
+	}
+
+	if err := services.CompletePlaylistItem(database.DB, userID, playlistID, snippetID, models.ProgressSourceManual); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record progress"})
+		return
+	}
+	respondPlaylistProgress(c, userID, playlistID)
+}
+
+// UnmarkPlaylistItem handles DELETE /playlists/:id/snippets/:snippetId/complete
+func UnmarkPlaylistItem(c *gin.Context) {
+	playlistID := c.Param("id")
+	userID := c.MustGet("userId").(string)
+
+	if err := database.DB.Where("user_id = ? AND playlist_id = ? AND snippet_id = ?", userID, playlistID, c.Param("snippetId")).
+		Delete(&models.PlaylistProgress{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update progress"})
+		return
+	}
+	respondPlaylistProgress(c, userID, playlistID)
+}
+
+func respondPlaylistProgress(c *gin.Context, userID, playlistID string) {
+	summaries, err := services.SummarizePlaylistProgress(database.DB, userID, []string{playlistID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch progress"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"progress": summaries[playlistID]})
+}
+
+var errEndorsementClaimed = errors.New("endorsement already claimed")
+
+// ClaimEndorsement handles POST /playlists/:id/claim, once every item of a verified
+// track is completed
 func ClaimEndorsement(c *gin.Context) {
 	id := c.Param("id")
 	userID, exists := c.Get("userId")
@@ -287,25 +359,58 @@ func ClaimEndorsement(c *gin.Context) {
 		return
 	}
 
-	var user models.User
-	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	summaries, err := services.SummarizePlaylistProgress(database.DB, userID.(string), []string{playlist.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch progress"})
+		return
+	}
+	if progress := summaries[playlist.ID]; !progress.Done {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Complete every item in this track first", "progress": progress})
 		return
 	}
 
-	// Check if already endorsed
-	for _, e := range user.Endorsements {
-		if e == playlist.AwardsEndorsement {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Endorsement already claimed"})
-			return
+	var user models.User
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userID).Error; err != nil {
+			return err
 		}
-	}
 
-	// Add endorsement and grant XP
-	user.Endorsements = append(user.Endorsements, playlist.AwardsEndorsement)
-	user.XP += 250 // Completion bonus
+		// Check if already endorsed
+		for _, e := range user.Endorsements {
+			if e == playlist.AwardsEndorsement {
+				return errEndorsementClaimed
+			}
+		}
 
-	if err := database.DB.Save(&user).Error; err != nil {
+		// Add endorsement and grant the track's completion bonus
+		user.Endorsements = append(user.Endorsements, playlist.AwardsEndorsement)
+		if err := tx.Model(&user).Update("endorsements", user.Endorsements).Error; err != nil {
+			return err
+		}
+		if playlist.CompletionBonusXP > 0 {
+			entry, err := services.ApplyXP(tx, services.XPChange{
+				UserID:         user.ID,
+				Amount:         playlist.CompletionBonusXP,
+				Source:         models.XPSourcePlaylist,
+				ReferenceType:  "playlist",
+				ReferenceID:    playlist.ID,
+				IdempotencyKey: "playlist:" + playlist.ID + ":" + user.ID,
+			})
+			if err != nil {
+				return err
+			}
+			user.XP = entry.BalanceAfter
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	case errors.Is(err, errEndorsementClaimed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Endorsement already claimed"})
+		return
+	case err != nil:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to claim endorsement"})
 		return
 	}
@@ -313,6 +418,7 @@ func ClaimEndorsement(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message":      "Certification claimed successfully!",
 		"endorsement":  playlist.AwardsEndorsement,
+		"bonusXp":      playlist.CompletionBonusXP,
 		"xp":           user.XP,
 		"endorsements": user.Endorsements,
 	})
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimEndorsement_RequiresCompletion(t *testing.T) {
+	SetupTestDB()
+	gin.SetMode(gin.TestMode)
+
+	database.DB.Create(&models.User{ID: "learner", Username: "learner", Email: "learner@example.com", XP: 100})
+	database.DB.Create(&models.Playlist{ID: "track", Title: "Graphs 101", AuthorID: "author", IsPublished: true,
+		IsVerified: true, AwardsEndorsement: "Graphs", CompletionBonusXP: 400})
+	database.DB.Create(&models.PlaylistSnippet{ID: "item-1", PlaylistID: "track", SnippetID: "bfs", Order: 0})
+	database.DB.Create(&models.PlaylistSnippet{ID: "item-2", PlaylistID: "track", SnippetID: "dfs", Order: 1})
+
+	call := func(handler gin.HandlerFunc, method string, params gin.Params) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(method, "/uri", nil)
+		c.Params = params
+		c.Set("userId", "learner")
+		handler(c)
+		return w
+	}
+	claim := func() *httptest.ResponseRecorder {
+		return call(ClaimEndorsement, "POST", gin.Params{{Key: "id", Value: "track"}})
+	}
+	mark := func(snippetID string) models.PlaylistProgressSummary {
+		w := call(MarkPlaylistItem, "POST", gin.Params{{Key: "id", Value: "track"}, {Key: "snippetId", Value: snippetID}})
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Progress models.PlaylistProgressSummary `json:"progress"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Progress
+	}
+
+	assert.Equal(t, http.StatusForbidden, claim().Code)
+
+	assert.Equal(t, 50, mark("bfs").Percent)
+	assert.Equal(t, http.StatusForbidden, claim().Code)
+
+	// Items outside the track can't be marked
+	w := call(MarkPlaylistItem, "POST", gin.Params{{Key: "id", Value: "track"}, {Key: "snippetId", Value: "dijkstra"}})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	progress := mark("dfs")
+	assert.True(t, progress.Done)
+	assert.Equal(t, 100, progress.Percent)
+
+	require.Equal(t, http.StatusOK, claim().Code)
+	var user models.User
+	database.DB.First(&user, "id = ?", "learner")
+	assert.Equal(t, 500, user.XP)
+	assert.Contains(t, user.Endorsements, "Graphs")
+
+	assert.Equal(t, http.StatusBadRequest, claim().Code)
+}
@@ -1,14 +1,17 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/judge"
 	"github.com/pushp314/devconnect-backend/internal/models"
 	"github.com/pushp314/devconnect-backend/internal/services"
+	"github.com/pushp314/devconnect-backend/pkg/logger"
 	"github.com/pushp314/devconnect-backend/pkg/utils"
 	"gorm.io/gorm"
 )
@@ -22,6 +25,11 @@ import (
 func ListPracticeProblems(c *gin.Context) {
 	var problems []models.PracticeProblem
 
+	// Rotates IsDailyProblem onto today's calendar entry
+	if _, err := services.TodaysDailyProblem(database.DB); err != nil && !errors.Is(err, services.ErrNoPracticeProblem) {
+		logger.Error().Err(err).Msg("Failed to rotate daily problem")
+	}
+
 	query := database.DB.Model(&models.PracticeProblem{})
 
 	// Filter by difficulty
@@ -41,6 +49,10 @@ func ListPracticeProblems(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch problems"})
 		return
 	}
+	staff := isStaff(c)
+	for i := range problems {
+		redactPracticeProblem(&problems[i], staff)
+	}
 
 	// If user is authenticated, add their solve status (batch query to avoid N+1)
 	userID, exists := c.Get("userId")
@@ -98,8 +110,8 @@ func GetPracticeProblem(c *gin.Context) {
 		return
 	}
 
-	// Don't expose solution code
-	problem.SolutionCode = ""
+	// Don't expose the solution or hidden cases
+	redactPracticeProblem(&problem, isStaff(c))
 
 	// Check if user has solved it
 	isSolved := false
@@ -143,54 +155,56 @@ func RunPracticeSolution(c *gin.Context) {
 		return
 	}
 
-	// 1. Execute code
-	res, err := services.ExecuteCode(input.Language, input.Code, "", float64(problem.TimeLimit), problem.MemoryLimit)
+	cases, err := judge.PracticeCases(problem.TestCases)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "ERROR",
-			"error":  err.Error(),
-		})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Problem has invalid test cases"})
 		return
 	}
 
-	if res.Run.Code != 0 {
+	// Hidden cases are reserved for submissions
+	var samples []judge.Case
+	for _, tc := range cases {
+		if !tc.Hidden {
+			samples = append(samples, tc)
+		}
+	}
+
+	result, err := judge.Run(executor, input.Language, input.Code, samples, judge.Options{
+		TimeLimit:   float64(problem.TimeLimit),
+		MemoryLimit: problem.MemoryLimit,
+		Checker:     judge.NewChecker(executor, problem.CheckerConfig),
+	})
+	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
-			"status": "RUNTIME_ERROR",
-			"error":  res.Run.Stderr,
+			"status": "ERROR",
+			"error":  err.Error(),
 		})
 		return
 	}
 
-	// 2. Output Validation (Against Sample Case 0)
-	// In a real system, we'd run against all *VISIBLE* test cases
-	var testCases []struct {
-		Input    string `json:"input"`
-		Expected string `json:"expected"`
-	}
-	json.Unmarshal([]byte(problem.TestCases), &testCases)
-
-	status := "ACCEPTED"
-	verdict := "Passed sample case"
-
-	if len(testCases) > 0 {
-		expected := testCases[0].Expected
-		// Trim whitespace for loose comparison
-		if !contains(res.Run.Stdout, expected) {
-			status = "WRONG_ANSWER"
-			verdict = "Output mismatch"
-		}
+	output, stderr := "", ""
+	switch {
+	case result.Status == models.SubStatusCE:
+		stderr = result.CompileOutput
+	case result.LastRun != nil:
+		output = result.LastRun.Run.Stdout
+		stderr = result.LastRun.Run.Stderr
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"status":  status,
-		"verdict": verdict,
-		"output":  res.Run.Stdout,
-		"stderr":  res.Run.Stderr,
+		"status":      result.Status,
+		"verdict":     result.Verdict,
+		"output":      output,
+		"stderr":      stderr,
+		"compileTime": result.CompileTime,
+		"testsPassed": result.Passed,
+		"testsTotal":  result.Total,
+		"results":     result.Cases,
 	})
 }
 
 // SubmitPracticeSolution handles POST /api/practice/submit
-// Runs against ALL test cases, records submission, awards badges
+// Records the submission and queues it for judging (badges are awarded by the judge)
 func SubmitPracticeSolution(c *gin.Context) {
 	userID, exists := c.Get("userId")
 	if !exists {
@@ -217,6 +231,11 @@ func SubmitPracticeSolution(c *gin.Context) {
 		return
 	}
 
+	if judgeQueue.Full() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Judge is busy, please retry in a moment"})
+		return
+	}
+
 	// Create submission record
 	submission := models.PracticeSubmission{
 		ID:        utils.GenerateID(),
@@ -224,7 +243,7 @@ func SubmitPracticeSolution(c *gin.Context) {
 		ProblemID: input.ProblemID,
 		Code:      input.Code,
 		Language:  input.Language,
-		Status:    "RUNNING",
+		Status:    "PENDING",
 		CreatedAt: time.Now(),
 	}
 
@@ -236,105 +255,75 @@ func SubmitPracticeSolution(c *gin.Context) {
 	// Increment attempt count
 	database.DB.Model(&problem).Update("attempt_count", gorm.Expr("attempt_count + 1"))
 
-	// Execute code
-	res, err := services.ExecuteCode(input.Language, input.Code, "", float64(problem.TimeLimit), problem.MemoryLimit)
-
-	if err != nil {
-		submission.Status = "ERROR"
-		submission.Error = err.Error()
-		database.DB.Save(&submission)
-		c.JSON(http.StatusOK, gin.H{"submission": submission})
-		return
+	// Judged asynchronously; clients poll GET /practice/submissions/:id
+	if err := judgeQueue.Enqueue(models.JudgeJobPractice, submission.ID); err != nil {
+		// Row stays PENDING; the queue's periodic recovery picks it up
+		logger.Error().Err(err).Str("submission", submission.ID).Msg("Failed to enqueue practice submission")
 	}
 
-	// Parse Validations
-	var testCases []struct {
-		Input    string `json:"input"`
-		Expected string `json:"expected"`
-	}
-	json.Unmarshal([]byte(problem.TestCases), &testCases)
-
-	submission.Output = res.Run.Stdout
-	submission.TestsTotal = len(testCases)
-	submission.TestsPassed = 0
-
-	// Validate
-	if res.Run.Code != 0 {
-		submission.Status = "ERROR"
-		submission.Error = res.Run.Stderr
-	} else if len(testCases) > 0 {
-		// Strict check against First Test Case (MVP shortcut)
-		// Ideal: Run loop for all inputs
-		expected := testCases[0].Expected
-		if contains(res.Run.Stdout, expected) {
-			submission.Status = "ACCEPTED"
-			submission.TestsPassed = len(testCases)
-			submission.Verdict = "All tests passed"
-		} else {
-			submission.Status = "WRONG_ANSWER"
-			submission.Verdict = "Wrong answer on test case 1"
-		}
-	} else {
-		submission.Status = "ACCEPTED"
-		submission.Verdict = "Code executed successfully"
-	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":    "Submission queued",
+		"submission": submission,
+	})
+}
 
-	// GAMIFICATION & STATS
-	var newBadges []models.Badge
-	if submission.Status == "ACCEPTED" {
-		// 1. Update Solve Count (if first time)
-		var prevSolves int64
-		database.DB.Model(&models.PracticeSubmission{}).
-			Where("\"userId\" = ? AND \"problemId\" = ? AND status = ? AND id != ?",
-				uid, input.ProblemID, "ACCEPTED", submission.ID).
-			Count(&prevSolves)
-
-		if prevSolves == 0 {
-			database.DB.Model(&problem).Update("solve_count", gorm.Expr("solve_count + 1"))
-
-			// 2. Check & Award Badges
-			awarded, err := services.CheckBadges(uid)
-			if err == nil && len(awarded) > 0 {
-				newBadges = awarded
-				NotifyNewBadges(uid, awarded)
-			}
-		}
+// GetPracticeSubmission handles GET /api/practice/submissions/:id
+// Returns the verdict of a queued submission, plus the suggested next problem once accepted
+// (picked when it was judged, since clients poll this)
+func GetPracticeSubmission(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
 	}
+	uid := userID.(string)
 
-	database.DB.Save(&submission)
-
-	// Findings Next Problem (if Accepted)
-	var nextProblemID string
-	if submission.Status == "ACCEPTED" {
-		// Get all solved IDs
-		var solvedIDs []string
-		database.DB.Model(&models.PracticeSubmission{}).
-			Where("\"userId\" = ? AND status = ?", uid, "ACCEPTED").
-			Distinct("\"problemId\"").
-			Pluck("\"problemId\"", &solvedIDs)
+	var submission models.PracticeSubmission
+	if err := database.DB.First(&submission, "id = ? AND \"userId\" = ?", c.Param("id"), uid).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
+		return
+	}
 
-		// Find first unsolved problem (ordered by difficulty/daily)
-		var nextProblem models.PracticeProblem
-		query := database.DB.Model(&models.PracticeProblem{})
-		if len(solvedIDs) > 0 {
-			query = query.Where("id NOT IN ?", solvedIDs)
-		}
-		// Simple heuristic: Next easiest, or random if all easy done
-		// For now, just generic order
-		if err := query.Order("difficulty ASC, solve_count DESC").First(&nextProblem).Error; err == nil {
-			nextProblemID = nextProblem.ID
-		}
+	if !isStaff(c) {
+		redactPracticeSubmission(&submission)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"submission":    submission,
-		"output":        res.Run.Stdout,
-		"stderr":        res.Run.Stderr,
-		"newBadges":     newBadges,
-		"nextProblemId": nextProblemID,
+		"output":        submission.Output,
+		"stderr":        submission.Error,
+		"nextProblemId": submission.NextProblemID,
 	})
 }
 
+// GetPracticeRecommendations handles GET /api/practice/recommendations: the user's
+// next problems (?limit=, default 10, max 50) and a practice plan built from their
+// solve history, preferred languages and interests
+func GetPracticeRecommendations(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	plan, err := services.PracticePlanFor(database.DB, &user, limit)
+	if err != nil {
+		logger.Error().Err(err).Str("user", user.ID).Msg("Failed to build practice plan")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recommendations"})
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
 // GetUserPracticeSubmissions handles GET /api/practice/submissions
 func GetUserPracticeSubmissions(c *gin.Context) {
 	userID, exists := c.Get("userId")
@@ -356,39 +345,112 @@ func GetUserPracticeSubmissions(c *gin.Context) {
 
 	query.Limit(50).Find(&submissions)
 
+	if !isStaff(c) {
+		for i := range submissions {
+			redactPracticeSubmission(&submissions[i])
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{"submissions": submissions})
 }
 
-// GetDailyProblem handles GET /api/practice/daily
+// GetDailyProblem handles GET /api/practice/daily: today's problem from the daily
+// calendar, with the viewer's streak when signed in
 func GetDailyProblem(c *gin.Context) {
-	var problem models.PracticeProblem
+	entry, err := services.TodaysDailyProblem(database.DB)
+	if errors.Is(err, services.ErrNoPracticeProblem) || (err == nil && entry.Problem == nil) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No practice problems available"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch daily problem"})
+		return
+	}
+
+	problem := *entry.Problem
+	// Don't expose the solution or hidden cases
+	redactPracticeProblem(&problem, isStaff(c))
 
-	// Get the current daily problem
-	if err := database.DB.Where("is_daily_problem = ?", true).First(&problem).Error; err != nil {
-		// No daily problem set, return a random one
-		if err := database.DB.Order("RANDOM()").First(&problem).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "No practice problems available"})
-			return
+	resp := gin.H{"problem": problem, "date": entry.Date}
+	if userID, exists := c.Get("userId"); exists {
+		if streak, err := services.StreakFor(database.DB, userID.(string), time.Now()); err == nil {
+			resp["streak"] = streak
 		}
 	}
+	c.JSON(http.StatusOK, resp)
+}
 
-	// Don't expose solution
-	problem.SolutionCode = ""
+// GetDailyCalendar handles GET /api/practice/daily/calendar?from=&to= (YYYY-MM-DD, the
+// last 30 days by default, at most 62 days). Future days are never shown.
+func GetDailyCalendar(c *gin.Context) {
+	now := time.Now()
+	today := services.DailyDate(now)
+	to := c.DefaultQuery("to", today)
+	from := c.DefaultQuery("from", services.DailyDate(now.AddDate(0, 0, -30)))
+	toDay, err := services.ParseDailyDate(to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	fromDay, err := services.ParseDailyDate(from)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if to > today {
+		to, toDay = today, now
+	}
+	if from > to {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+	if toDay.Sub(fromDay) > 62*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Range is limited to 62 days"})
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"problem": problem})
+	// Make sure today exists before listing
+	if _, err := services.TodaysDailyProblem(database.DB); err != nil && !errors.Is(err, services.ErrNoPracticeProblem) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch daily problem"})
+		return
+	}
+	days, err := services.DailyCalendar(database.DB, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch calendar"})
+		return
+	}
+	for i := range days {
+		if days[i].Problem != nil {
+			redactPracticeProblem(days[i].Problem, false)
+		}
+		days[i].CreatedBy = ""
+	}
+	c.JSON(http.StatusOK, gin.H{"days": days})
 }
 
-// Helper function
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
-		(len(s) > 0 && len(substr) > 0 && findSubstring(s, substr)))
-}
+// GetMyDailyStreak handles GET /api/practice/daily/streak: the user's streak and the
+// solved and frozen days of the last ?days= days (default 30, max 365)
+func GetMyDailyStreak(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days < 1 || days > 365 {
+		days = 30
+	}
 
-func findSubstring(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
+	now := time.Now()
+	streak, err := services.StreakFor(database.DB, userID.(string), now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch streak"})
+		return
+	}
+	history, err := services.DailyHistory(database.DB, userID.(string), days, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch streak history"})
+		return
 	}
-	return false
+	c.JSON(http.StatusOK, gin.H{"streak": streak, "history": history, "maxFreezes": models.MaxStreakFreezes})
 }
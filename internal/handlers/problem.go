@@ -3,8 +3,7 @@ package handlers
 import (
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
-	"fmt"
+	"io"
 	"net/http"
 	"regexp"
 	"strings"
@@ -12,8 +11,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/judge"
 	"github.com/pushp314/devconnect-backend/internal/models"
 	"github.com/pushp314/devconnect-backend/internal/services"
+	"github.com/pushp314/devconnect-backend/pkg/logger"
 	"github.com/pushp314/devconnect-backend/pkg/utils"
 	"gorm.io/gorm"
 )
@@ -40,6 +41,16 @@ func RunSolution(c *gin.Context) {
 		return
 	}
 
+	var event models.Event
+	if err := database.DB.First(&event, "id = ?", problem.EventID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+	if err := checkEventLanguage(&event, input.Language); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var sampleCases []models.TestCase
 	for _, tc := range problem.TestCases {
 		if !tc.IsHidden {
@@ -65,10 +76,23 @@ func RunSolution(c *gin.Context) {
 	}
 
 	var results []TestCaseResult
+	checker := judge.NewChecker(executor, problem.CheckerConfig)
+	if closer, ok := checker.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	// Compile once with the default Run limits; every sample reuses the build
+	prog, compileErr := services.CompileCode(executor, input.Language, input.Code, services.ExecuteOptions{TimeLimit: 2.0, MemoryLimit: 128})
+	if compileErr == nil {
+		defer prog.Close()
+	}
 
 	for _, tc := range sampleCases {
-		// Default limits for Run
-		res, err := services.ExecuteCode(input.Language, input.Code, tc.Input, 2.0, 128)
+		var res *services.ExecutionResult
+		err := compileErr
+		if err == nil {
+			res, err = prog.Run(c.Request.Context(), tc.Input)
+		}
 
 		var result TestCaseResult
 		result.Input = tc.Input
@@ -77,21 +101,28 @@ func RunSolution(c *gin.Context) {
 		if err != nil {
 			result.Status = "ERROR"
 			result.Stderr = err.Error()
-		} else if res.Run.Code != 0 {
+		} else if status, verdict := judge.Classify(res, 128); status != "" {
+			// Same classification as the judge, so Run and Submit agree
 			result.Status = "ERROR"
 			result.Stderr = res.Run.Stderr
 			if res.Run.Signal != "" {
 				result.Stderr += " (Signal: " + res.Run.Signal + ")"
 			}
+			if status == models.SubStatusCE {
+				result.Stderr = res.Compile.Stderr
+			}
+			if result.Stderr == "" {
+				result.Stderr = verdict
+			}
 		} else {
-			// Normalize check
-			actualNormalized := strings.TrimSpace(strings.ReplaceAll(res.Run.Stdout, "\r\n", "\n"))
-			expectedNormalized := strings.TrimSpace(strings.ReplaceAll(tc.Output, "\r\n", "\n"))
-
 			result.Actual = res.Run.Stdout
 			result.Stderr = res.Run.Stderr
 
-			if actualNormalized == expectedNormalized {
+			ok, _, err := checker.Check(tc.Input, tc.Output, res.Run.Stdout)
+			if err != nil {
+				result.Status = "ERROR"
+				result.Stderr = err.Error()
+			} else if ok {
 				result.Status = "PASSED"
 			} else {
 				result.Status = "FAILED"
@@ -142,16 +173,18 @@ func ListProblems(c *gin.Context) {
 		return
 	}
 
-	// Check Registration & Rules
-	var registration models.Registration
-	if err := database.DB.Where("user_id = ? AND event_id = ?", userID, eventID).First(&registration).Error; err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Not registered"})
-		return
-	}
+	// Check Registration & Rules (virtual participants take part without registering)
+	if _, virtual := findVirtualParticipation(userID.(string), eventID); !virtual {
+		var registration models.Registration
+		if err := database.DB.Where("user_id = ? AND event_id = ?", userID, eventID).First(&registration).Error; err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not registered"})
+			return
+		}
 
-	if !registration.RulesAccepted {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Rules not accepted", "rulesRequired": true})
-		return
+		if !registration.RulesAccepted {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Rules not accepted", "rulesRequired": true})
+			return
+		}
 	}
 
 	var problems []models.Problem
@@ -214,15 +247,17 @@ func GetProblem(c *gin.Context) {
 			return
 		}
 
-		// 2. Rules & Registration
-		var registration models.Registration
-		if err := database.DB.Where("user_id = ? AND event_id = ?", userID, event.ID).First(&registration).Error; err != nil {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Not registered"})
-			return
-		}
-		if !registration.RulesAccepted {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Rules not accepted"})
-			return
+		// 2. Rules & Registration (virtual participants take part without registering)
+		if _, virtual := findVirtualParticipation(userID.(string), event.ID); !virtual {
+			var registration models.Registration
+			if err := database.DB.Where("user_id = ? AND event_id = ?", userID, event.ID).First(&registration).Error; err != nil {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Not registered"})
+				return
+			}
+			if !registration.RulesAccepted {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Rules not accepted"})
+				return
+			}
 		}
 	}
 
@@ -398,13 +433,8 @@ func SubmitSolution(c *gin.Context) {
 	}
 
 	// CRITICAL: Contest Time Lock (Server Time Enforcement)
-	// CRITICAL: Contest Time Lock (Server Time Enforcement)
-	isLateSubmission := false
+	// Late submissions (after EndTime) are allowed for upsolving; the judge skips scoring them.
 	if event.ID != "practice-arena-mvp" {
-		if event.Status == "ENDED" || time.Now().UTC().After(event.EndTime) {
-			isLateSubmission = true
-			// We allow submission for practice/upsolving, but scores won't count.
-		}
 		// Time Lock: Not Started
 		if time.Now().UTC().Before(event.StartTime) {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Contest has not started yet (not live)"})
@@ -412,15 +442,32 @@ func SubmitSolution(c *gin.Context) {
 		}
 	}
 
-	// Rule: Registration Check (skip for practice)
+	// Virtual participation: submissions while the personal clock runs are tagged with it
+	var virtualID *string
 	if event.ID != "practice-arena-mvp" {
+		if vp, ok := findVirtualParticipation(uid, event.ID); ok && vp.Active(time.Now()) {
+			virtualID = &vp.ID
+		}
+	}
+
+	// Rule: Registration Check (skip for practice and virtual participants)
+	var teamID *string // Team events: the submission counts for the submitter's team
+	if event.ID != "practice-arena-mvp" && virtualID == nil {
 		var registration models.Registration
 		if err := database.DB.Where("user_id = ? AND event_id = ?", uid, problem.EventID).First(&registration).Error; err != nil {
 			c.JSON(http.StatusForbidden, gin.H{"error": "You must be registered for this contest"})
 			return
 		}
+		teamID = registration.TeamID
 	}
 
+	// Rule: Language must be enabled and allowed in this contest
+	if err := checkEventLanguage(&event, input.Language); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Language = services.CanonicalLanguage(input.Language)
+
 	// LAYER 1: HARD RULES (BLOCKING)
 	// Rule: Max 20 submissions per problem
 	var subCount int64
@@ -467,6 +514,12 @@ func SubmitSolution(c *gin.Context) {
 		return
 	}
 
+	// Back-pressure: refuse new work while the judge backlog is saturated
+	if judgeQueue.Full() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Judge is busy, please retry in a moment"})
+		return
+	}
+
 	// 4. Rate Limiting (10s cooldown)
 	var lastSub models.Submission
 	if err := database.DB.Where("user_id = ? AND problem_id = ?", uid, problemID).Order("created_at desc").First(&lastSub).Error; err == nil {
@@ -517,10 +570,14 @@ func SubmitSolution(c *gin.Context) {
 	hashStr := hex.EncodeToString(codeHash[:])
 
 	// Check for EXACT hash match cross-user
+	// Teammates sharing code is expected, so their submissions don't count
 	var dupCount int64
-	database.DB.Model(&models.Submission{}).
-		Where("problem_id = ? AND code_hash = ? AND user_id != ?", problemID, hashStr, uid).
-		Count(&dupCount)
+	dupQuery := database.DB.Model(&models.Submission{}).
+		Where("problem_id = ? AND code_hash = ? AND user_id != ?", problemID, hashStr, uid)
+	if teamID != nil {
+		dupQuery = dupQuery.Where("(team_id IS NULL OR team_id != ?)", *teamID)
+	}
+	dupQuery.Count(&dupCount)
 
 	// Low-Trust User: Stricter Limits
 	var user models.User
@@ -530,20 +587,13 @@ func SubmitSolution(c *gin.Context) {
 		return
 	}
 
-	// Too-Fast Correct Submission Detection (flag if first submission is ACCEPTED in < 60s)
-	var firstSub models.Submission
-	database.DB.Where("user_id = ? AND problem_id = ?", uid, problemID).Order("created_at asc").First(&firstSub)
-	tooFastFlag := false
-	if firstSub.ID == "" {
-		// This is the first submission, will check after execution if it's too fast
-		tooFastFlag = true
-	}
-
 	submission := models.Submission{
 		ID:        utils.GenerateID(),
 		UserID:    uid,
 		EventID:   problem.EventID,
 		ProblemID: problemID,
+		TeamID:    teamID,
+		VirtualID: virtualID,
 		Code:      input.Code,
 		Language:  input.Language,
 		CodeHash:  hashStr,
@@ -557,241 +607,75 @@ func SubmitSolution(c *gin.Context) {
 		return
 	}
 
-	// Save Metrics
-	metrics := models.SubmissionMetrics{
-		SubmissionID:  submission.ID,
-		PasteCount:    input.PasteCount,
-		PastedChars:   input.PastedChars,
-		BlurCount:     input.BlurCount,
-		TabSwitchCnt:  input.BlurCount, // Map blur to tab switch for now
-		IP:            clientIP,
-		UserAgent:     userAgent,
-		LineCount:     lineCount,
-		FunctionCount: funcCount,
-		LoopCount:     loopCount,
-	}
-	database.DB.Create(&metrics)
-
-	// LAYER 2 & 4: FLAGGING & TRUST SCORE
-	flags := []models.SubmissionFlag{}
-	trustDeduction := 0
-
-	// Flag: Duplicate Hash
-	if dupCount > 0 {
-		flags = append(flags, models.SubmissionFlag{
-			ID:           utils.GenerateID(),
-			SubmissionID: submission.ID,
-			Type:         models.FlagTypeHash,
-			Details:      fmt.Sprintf("Similar code hash found in %d other submissions", dupCount),
-			CreatedAt:    time.Now(),
-		})
-		trustDeduction += 20
-	}
-
-	// Flag: Excessive Pasting (>50% of code is pasted)
-	if len(cleanCode) > 50 && input.PastedChars > len(cleanCode)/2 {
-		flags = append(flags, models.SubmissionFlag{
-			ID:           utils.GenerateID(),
-			SubmissionID: submission.ID,
-			Type:         models.FlagTypePaste,
-			Details:      fmt.Sprintf("Pasted %d chars (Total: %d)", input.PastedChars, len(cleanCode)),
-			CreatedAt:    time.Now(),
-		})
-		trustDeduction += 10
-	}
-
-	// Flag: Excessive Focus Loss (>10 times)
-	if input.BlurCount > 10 {
-		flags = append(flags, models.SubmissionFlag{
-			ID:           utils.GenerateID(),
-			SubmissionID: submission.ID,
-			Type:         models.FlagTypeBlur,
-			Details:      fmt.Sprintf("Focus lost %d times", input.BlurCount),
-			CreatedAt:    time.Now(),
-		})
-		trustDeduction += 15
+	// Teammates may well share a machine, so only other teams count below
+	otherParticipants := func(q *gorm.DB) *gorm.DB {
+		if teamID != nil {
+			return q.Where("(submissions.team_id IS NULL OR submissions.team_id != ?)", *teamID)
+		}
+		return q
 	}
 
-	// Flag: IP Sharing (Multiple users on same IP in same Event)
+	// IP Sharing (Multiple users on same IP in same Event)
 	var usersOnIP int64
-	database.DB.Table("submission_metrics").
+	otherParticipants(database.DB.Table("submission_metrics").
 		Joins("JOIN submissions ON submissions.id = submission_metrics.submission_id").
-		Where("submission_metrics.ip = ? AND submissions.event_id = ? AND submissions.user_id != ?", clientIP, problem.EventID, uid).
+		Where("submission_metrics.ip = ? AND submissions.event_id = ? AND submissions.user_id != ?", clientIP, problem.EventID, uid)).
 		Distinct("submissions.user_id").
 		Count(&usersOnIP)
 
-	if usersOnIP > 0 {
-		flags = append(flags, models.SubmissionFlag{
-			ID:           utils.GenerateID(),
-			SubmissionID: submission.ID,
-			Type:         models.FlagTypeSuspicious,
-			Details:      fmt.Sprintf("IP shared with %d other users in this contest", usersOnIP),
-			CreatedAt:    time.Now(),
-		})
-		trustDeduction += 30
-	}
-
-	// Flag: User-Agent Correlation (same UA + IP as another user)
+	// User-Agent Correlation (same UA + IP as another user)
 	var sameUA int64
-	database.DB.Table("submission_metrics").
+	otherParticipants(database.DB.Table("submission_metrics").
 		Joins("JOIN submissions ON submissions.id = submission_metrics.submission_id").
 		Where("submission_metrics.ip = ? AND submission_metrics.user_agent = ? AND submissions.event_id = ? AND submissions.user_id != ?",
-			clientIP, userAgent, problem.EventID, uid).
+			clientIP, userAgent, problem.EventID, uid)).
 		Distinct("submissions.user_id").
 		Count(&sameUA)
 
-	if sameUA > 0 {
-		flags = append(flags, models.SubmissionFlag{
-			ID:           utils.GenerateID(),
-			SubmissionID: submission.ID,
-			Type:         models.FlagTypeSuspicious,
-			Details:      fmt.Sprintf("Same IP + User-Agent as %d other users", sameUA),
-			CreatedAt:    time.Now(),
+	// LAYER 2 & 4: FLAGGING & TRUST SCORE, by the event's anti-cheat rules
+	pastedRatio := 0.0
+	if len(cleanCode) > 50 {
+		pastedRatio = float64(input.PastedChars) / float64(len(cleanCode))
+	}
+	var ruleResults []models.AntiCheatResult
+	if rules, err := services.AntiCheatRulesForEvent(database.DB, problem.EventID); err != nil {
+		logger.Error().Err(err).Str("submission", submission.ID).Msg("Failed to load anti-cheat rules")
+	} else {
+		ruleResults = services.EvaluateAntiCheat(rules, services.AntiCheatSignals{
+			models.ConditionDuplicateHash: float64(dupCount),
+			models.ConditionPastedRatio:   pastedRatio,
+			models.ConditionBlurCount:     float64(input.BlurCount),
+			models.ConditionSharedIP:      float64(usersOnIP),
+			models.ConditionSharedDevice:  float64(sameUA),
 		})
-		trustDeduction += 25
 	}
 
-	// Store tooFastFlag for post-execution check
-	_ = tooFastFlag // Will be used in execution callback
-
-	// Apply Flags & Trust Score
-	for _, f := range flags {
-		database.DB.Create(&f)
+	// Save Metrics
+	metrics := models.SubmissionMetrics{
+		SubmissionID:  submission.ID,
+		PasteCount:    input.PasteCount,
+		PastedChars:   input.PastedChars,
+		BlurCount:     input.BlurCount,
+		TabSwitchCnt:  input.BlurCount, // Map blur to tab switch for now
+		IP:            clientIP,
+		UserAgent:     userAgent,
+		LineCount:     lineCount,
+		FunctionCount: funcCount,
+		LoopCount:     loopCount,
+		RuleResults:   ruleResults,
 	}
+	database.DB.Create(&metrics)
 
-	if trustDeduction > 0 {
-		// Decrease Trust Score (Cap at 0)
-		database.DB.Model(&models.User{}).Where("id = ?", uid).
-			Update("trust_score", gorm.Expr("GREATEST(trust_score - ?, 0)", trustDeduction))
+	// Apply Flags & Trust Score (dry-run rules are only recorded)
+	if err := services.ApplyAntiCheat(database.DB, &submission, ruleResults); err != nil {
+		logger.Error().Err(err).Str("submission", submission.ID).Msg("Failed to apply anti-cheat rules")
 	}
 
-	// EXECUTION LOGIC (Piston)
-	// Why loop? Because we have multiple test cases.
-	// We run them sequentially.
-	go func(subID string, prob models.Problem, userCode, lang string, isLate bool) {
-		// Re-fetch submission to update later
-		var sub models.Submission
-		database.DB.First(&sub, "id = ?", subID)
-
-		// Create execution context
-		allPassed := true
-		passedCases := 0
-		totalExecTime := 0.0
-		var lastRun *services.PistonExecuteResponse
-
-		// Normalize output helper
-		normalizeOutput := func(s string) string {
-			s = strings.TrimSpace(s)
-			s = strings.ReplaceAll(s, "\r\n", "\n")
-			lines := strings.Split(s, "\n")
-			for i, line := range lines {
-				lines[i] = strings.TrimRight(line, " \t")
-			}
-			return strings.Join(lines, "\n")
-		}
-
-		for _, tc := range prob.TestCases {
-			start := time.Now()
-			res, err := services.ExecuteCode(lang, userCode, tc.Input, prob.TimeLimit, prob.MemoryLimit)
-			execDuration := time.Since(start).Seconds() * 1000 // ms
-			totalExecTime += execDuration
-			lastRun = res
-
-			if err != nil {
-				sub.Status = models.SubStatusRE // Runtime Error (or infra error)
-				sub.Verdict = "Runtime Error"
-				allPassed = false
-				break
-			}
-			if res.Run.Signal == "SIGKILL" {
-				sub.Status = models.SubStatusTLE
-				sub.Verdict = "Time Limit Exceeded"
-				allPassed = false
-				break
-			}
-			if res.Run.Signal == "SIGTERM" {
-				sub.Status = models.SubStatusTLE
-				sub.Verdict = "Time Limit Exceeded"
-				allPassed = false
-				break
-			}
-			if res.Run.Signal != "" {
-				sub.Status = models.SubStatusRE
-				sub.Verdict = "Runtime Error (" + res.Run.Signal + ")"
-				allPassed = false
-				break
-			}
-			if res.Run.Code != 0 {
-				sub.Status = models.SubStatusRE
-				// Check for common exit codes
-				if res.Run.Code == 137 { // 128 + 9 (SIGKILL)
-					sub.Status = models.SubStatusTLE
-					sub.Verdict = "Time Limit Exceeded"
-				} else {
-					sub.Verdict = "Runtime Error (Exit Code " + fmt.Sprintf("%d", res.Run.Code) + ")"
-					// Capture stderr
-					if len(res.Run.Stderr) > 0 {
-						// truncate if too long
-						msg := res.Run.Stderr
-						if len(msg) > 100 {
-							msg = msg[:100] + "..."
-						}
-						sub.Verdict += ": " + msg
-					}
-				}
-				allPassed = false
-				break
-			}
-
-			// Compare Output
-			actual := normalizeOutput(res.Run.Stdout)
-			expected := normalizeOutput(tc.Output)
-
-			if actual != expected {
-				sub.Status = models.SubStatusWA
-				sub.Verdict = "Wrong Answer"
-				allPassed = false
-				break
-			}
-			passedCases++
-		}
-
-		if allPassed {
-			sub.Status = models.SubStatusAC
-			sub.Verdict = "Accepted"
-		}
-
-		sub.TestCasesPassed = passedCases
-		sub.TotalTestCases = len(prob.TestCases)
-		sub.Runtime = totalExecTime
-		if lastRun != nil {
-			// Convert Piston output to snapshot
-			snap, _ := json.Marshal(lastRun)
-			sub.OutputSnapshot = string(snap)
-		}
-
-		database.DB.Save(&sub)
-
-		// Update Registration Score if AC (Only if ON TIME)
-		if allPassed && prob.Points > 0 && !isLate {
-			var reg models.Registration
-			if err := database.DB.Where("user_id = ? AND event_id = ?", sub.UserID, sub.EventID).First(&reg).Error; err == nil {
-				// Check if already solved correctly before?
-				// Simple MVP: Just add points if status wasn't AC before?
-				// Better: Count unique solved problems.
-				// For now, simpler: user gets points.
-				// Wait, if they submit AC twice, do they get double points?
-				// Need to check if they already have an AC submission for this problem.
-				var count int64
-				database.DB.Model(&models.Submission{}).Where("user_id = ? AND problem_id = ? AND status = ? AND id != ?", sub.UserID, sub.ProblemID, models.SubStatusAC, sub.ID).Count(&count)
-				if count == 0 {
-					reg.Score += prob.Points
-					database.DB.Save(&reg)
-				}
-			}
-		}
-
-	}(submission.ID, problem, input.Code, input.Language, isLateSubmission)
+	// EXECUTION: hand off to the durable judge queue (bounded workers, survives restarts)
+	if err := judgeQueue.Enqueue(models.JudgeJobContest, submission.ID); err != nil {
+		// Row stays PENDING; the queue's periodic recovery picks it up
+		logger.Error().Err(err).Str("submission", submission.ID).Msg("Failed to enqueue submission")
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":    "Submission received",
@@ -815,6 +699,13 @@ func GetUserSubmissions(c *gin.Context) {
 		return
 	}
 
+	// Hidden test data stays hidden even in the author's own report
+	if !isStaff(c) {
+		for i := range submissions {
+			redactSubmission(&submissions[i])
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{"submissions": submissions})
 }
 
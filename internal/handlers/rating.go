@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
+	"gorm.io/gorm"
+)
+
+// ratingErrorStatus maps rating service errors to HTTP statuses
+func ratingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrUnratedContest), errors.Is(err, services.ErrResultsNotFinal):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrRatingsApplied):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GetContestRatingChanges handles GET /events/:id/rating-changes: the applied changes
+// of a rated contest, or a preview while they are pending. Hidden like the results
+// until a frozen scoreboard has been unfrozen.
+func GetContestRatingChanges(c *gin.Context) {
+	eventID := c.Param("id")
+
+	var event models.Event
+	if err := database.DB.First(&event, "id = ?", eventID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+	if event.FreezeTime != nil && event.UnfrozenAt == nil && !isStaff(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Rating changes are published once the scoreboard is unfrozen"})
+		return
+	}
+
+	if event.RatedAt != nil {
+		var history []models.RatingHistory
+		if err := database.DB.Preload("User").Where("event_id = ?", eventID).Order("rank asc").Find(&history).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rating changes"})
+			return
+		}
+		changes := make([]services.RatingChange, 0, len(history))
+		for _, h := range history {
+			changes = append(changes, services.RatingChange{
+				UserID:    h.UserID,
+				Username:  h.User.Username,
+				Rank:      h.Rank,
+				OldRating: h.OldRating,
+				NewRating: h.NewRating,
+				Delta:     h.Delta,
+			})
+		}
+		c.JSON(http.StatusOK, gin.H{"eventId": eventID, "preview": false, "ratedAt": event.RatedAt, "changes": changes})
+		return
+	}
+
+	changes, err := services.PreviewRatingChanges(database.DB, eventID)
+	if err != nil {
+		c.JSON(ratingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"eventId": eventID, "preview": true, "changes": changes})
+}
+
+// GetRatingHistory handles GET /users/:username/rating-history
+func GetRatingHistory(c *gin.Context) {
+	username := c.Param("username")
+
+	var user models.User
+	if err := database.DB.Select("id", "username", "rating", "max_rating", "rated_contests").
+		First(&user, "username = ?", username).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var history []models.RatingHistory
+	if err := database.DB.Preload("Event", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "title", "slug", "start_time", "end_time")
+	}).Where("user_id = ?", user.ID).Order("created_at asc").Find(&history).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rating history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rating":        user.Rating,
+		"maxRating":     user.MaxRating,
+		"ratedContests": user.RatedContests,
+		"history":       history,
+	})
+}
+
+// AdminPreviewRatings handles GET /admin/contests/:id/ratings/preview
+func AdminPreviewRatings(c *gin.Context) {
+	changes, err := services.PreviewRatingChanges(database.DB, c.Param("id"))
+	if err != nil {
+		c.JSON(ratingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"changes": changes})
+}
+
+// AdminApplyRatings handles POST /admin/contests/:id/ratings/apply. Re-rank first if the
+// results need moderation: applied changes are final.
+func AdminApplyRatings(c *gin.Context) {
+	eventID := c.Param("id")
+	adminID := getAdminID(c)
+
+	changes, err := services.ApplyRatingChanges(database.DB, eventID)
+	if err != nil {
+		c.JSON(ratingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	logAdminAction(database.DB, adminID, models.ActionApplyRatings, eventID, "contest", fmt.Sprintf("Applied %d rating changes", len(changes)))
+	for _, change := range changes {
+		recordBadgeEvent(change.UserID, models.MetricContestRank, change.Rank)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ratings Applied", "changes": changes})
+}
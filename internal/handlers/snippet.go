@@ -11,6 +11,7 @@ import (
 	"github.com/pushp314/devconnect-backend/internal/database"
 	"github.com/pushp314/devconnect-backend/internal/models"
 	"github.com/pushp314/devconnect-backend/internal/services"
+	"github.com/pushp314/devconnect-backend/pkg/logger"
 	"github.com/pushp314/devconnect-backend/pkg/utils"
 	"gorm.io/gorm"
 )
@@ -238,13 +239,21 @@ func CreateSnippet(c *gin.Context) {
 
 	// Reward XP for creating a snippet (if public)
 	if snippet.Visibility == "public" {
-		database.DB.Model(&models.User{}).Where("id = ?", userID.(string)).Update("xp", gorm.Expr("xp + ?", 50))
+		if _, err := services.ApplyXP(database.DB, services.XPChange{
+			UserID:         userID.(string),
+			Amount:         50,
+			Source:         models.XPSourceSnippet,
+			ReferenceType:  "snippet",
+			ReferenceID:    snippet.ID,
+			IdempotencyKey: "snippet:" + snippet.ID,
+		}); err != nil {
+			logger.Error().Err(err).Str("snippet", snippet.ID).Msg("Failed to award snippet XP")
+		}
 		services.LogActivity(userID.(string), models.ActivityNewSnippet, snippet.ID, "Created a new snippet: "+snippet.Title)
 	}
 
 	// Check for Badges
-	newBadges, _ := services.CheckBadges(userID.(string))
-	NotifyNewBadges(userID.(string), newBadges)
+	newBadges := recordBadgeEvent(userID.(string), models.MetricSnippetsCreated, 1)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"snippet":   snippet,
@@ -484,6 +493,7 @@ func DeleteSnippet(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete snippet: " + err.Error()})
 		return
 	}
+	recordBadgeEvent(snippet.AuthorID, models.MetricSnippetsCreated, -1)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Snippet deleted"})
 }
@@ -547,7 +557,7 @@ func RunSnippet(c *gin.Context) {
 	// MVP: Standardize execution limits (e.g., 2s timeout)
 	// Input logic is removed for MVP as per requirements (No input handling)
 	start := time.Now()
-	res, err := services.ExecuteCode(snippet.Language, snippet.Code, "", 2.0, 128)
+	res, err := services.ExecuteCode(executor, snippet.Language, snippet.Code, "", services.ExecuteOptions{TimeLimit: 2.0, MemoryLimit: 128})
 	duration := time.Since(start).Seconds() * 1000 // ms
 
 	if err != nil {
@@ -598,6 +608,12 @@ func RunSnippet(c *gin.Context) {
 
 	database.DB.Save(&snippet)
 
+	if userID, ok := c.Get("userId"); ok {
+		if err := services.CompleteSnippetInPlaylists(database.DB, userID.(string), snippet.ID, models.ProgressSourceRun); err != nil {
+			logger.Error().Err(err).Str("snippet", snippet.ID).Msg("Failed to record playlist progress")
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"stdout": res.Run.Stdout,
 		"stderr": res.Run.Stderr,
@@ -903,6 +919,11 @@ func RecordSnippetView(c *gin.Context) {
 		return
 	}
 
+	// Viewing a snippet completes it in any track the user follows
+	if err := services.CompleteSnippetInPlaylists(database.DB, userID.(string), snippetID, models.ProgressSourceView); err != nil {
+		logger.Error().Err(err).Str("snippet", snippetID).Msg("Failed to record playlist progress")
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "View recorded"})
 }
 
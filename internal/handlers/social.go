@@ -185,11 +185,6 @@ func LinkUser(c *gin.Context) {
 		for _, u := range users {
 			updateData := map[string]interface{}{}
 
-			// Only award XP if it's a fresh link (not a restore)
-			if shouldAwardXP {
-				updateData["xp"] = gorm.Expr("xp + 50")
-			}
-
 			if u.isTarget {
 				updateData["linkersCount"] = gorm.Expr("\"linkersCount\" + 1")
 			} else {
@@ -199,6 +194,24 @@ func LinkUser(c *gin.Context) {
 			if err := tx.Model(&models.User{}).Where("id = ?", u.id).Updates(updateData).Error; err != nil {
 				return fmt.Errorf("update user %s: %w", u.id, err)
 			}
+
+			// Only award XP if it's a fresh link (not a restore); the key keeps it one-time
+			if shouldAwardXP {
+				other := linkerID.(string)
+				if !u.isTarget {
+					other = actualTargetID
+				}
+				if _, err := services.ApplyXP(tx, services.XPChange{
+					UserID:         u.id,
+					Amount:         50,
+					Source:         models.XPSourceLink,
+					ReferenceType:  "user",
+					ReferenceID:    other,
+					IdempotencyKey: "link:" + linkerID.(string) + ":" + actualTargetID + ":" + u.id,
+				}); err != nil {
+					return fmt.Errorf("award xp %s: %w", u.id, err)
+				}
+			}
 		}
 
 		return nil
@@ -136,6 +136,8 @@ func InitSocketServer() *socketio.Server {
 		s.Join(chatId)
 	})
 
+	registerContestRooms(server)
+
 	server.OnEvent("/", "typing", func(s socketio.Conn, data map[string]interface{}) {
 		recipientID, ok := data["recipientId"].(string)
 		if !ok {
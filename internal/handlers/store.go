@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
+)
+
+// storeCategoryEnabled reports whether a store category is switched on. Themes and
+// power-ups have their own feature flags; the other categories follow the store itself.
+func storeCategoryEnabled(category models.StoreCategory) bool {
+	switch category {
+	case models.StoreCategoryTheme:
+		return database.IsFeatureEnabled(models.SettingFeatureStoreThemes)
+	case models.StoreCategoryPowerup:
+		return database.IsFeatureEnabled(models.SettingFeatureStorePowerups)
+	default:
+		return true
+	}
+}
+
+// ListStoreItems handles GET /store/items: the items for sale now, optionally filtered
+// by ?category. Signed-in users also see which items they own and can afford.
+func ListStoreItems(c *gin.Context) {
+	now := time.Now()
+	query := database.DB.Where("enabled = ?", true).
+		Where("available_from IS NULL OR available_from <= ?", now).
+		Where("available_until IS NULL OR available_until > ?", now)
+	if category := c.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	var items []models.StoreItem
+	if err := query.Order("category asc, price asc, id asc").Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch store items"})
+		return
+	}
+
+	var user *models.User
+	if userId, exists := c.Get("userId"); exists {
+		var u models.User
+		if database.DB.Select("id", "xp", "level", "purchased_component_ids").First(&u, "id = ?", userId).Error == nil {
+			user = &u
+		}
+	}
+
+	type storeItem struct {
+		models.StoreItem
+		Owned    bool `json:"owned"`
+		Unlocked bool `json:"unlocked"` // Level requirement met
+		CanBuy   bool `json:"canBuy"`
+	}
+	result := make([]storeItem, 0, len(items))
+	for _, item := range items {
+		if !storeCategoryEnabled(item.Category) {
+			continue
+		}
+		entry := storeItem{StoreItem: item}
+		if user != nil {
+			entry.Owned = slices.Contains(user.PurchasedComponentIds, item.ID)
+			entry.Unlocked = user.Level >= item.MinLevel
+			entry.CanBuy = !entry.Owned && entry.Unlocked && user.XP >= item.Price
+		}
+		result = append(result, entry)
+	}
+
+	resp := gin.H{"items": result}
+	if user != nil {
+		resp["xp"] = user.XP
+		resp["level"] = user.Level
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,464 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/pkg/logger"
+	"github.com/pushp314/devconnect-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+type CreateTeamInput struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type InviteToTeamInput struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type RegisterTeamInput struct {
+	TeamID            string `json:"teamId" binding:"required"`
+	RazorpayPaymentID string `json:"razorpayPaymentId"`
+	RazorpayOrderID   string `json:"razorpayOrderId"`
+	RazorpaySignature string `json:"razorpaySignature"`
+}
+
+var errRosterLocked = errors.New("the team is registered for a contest that has not ended, its roster is locked")
+
+// teamRegistrationError explains why a team can't register for an event
+type teamRegistrationError struct {
+	status  int
+	message string
+}
+
+func (e *teamRegistrationError) Error() string { return e.message }
+
+// loadTeam fetches a team with its members and their users
+func loadTeam(db *gorm.DB, teamID string) (*models.Team, error) {
+	var team models.Team
+	if err := db.Preload("Members.User").First(&team, "id = ?", teamID).Error; err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// teamRosterLocked reports whether a team is registered for a contest that hasn't
+// ended: members can't join or leave until it has
+func teamRosterLocked(db *gorm.DB, teamID string) bool {
+	var count int64
+	db.Model(&models.Registration{}).
+		Joins("JOIN events ON events.id = registrations.event_id").
+		Where("registrations.team_id = ? AND events.status <> ?", teamID, models.EventStatusEnded).
+		Count(&count)
+	return count > 0
+}
+
+// checkTeamRegistration validates a team against a team event: owner-only, roster size
+// within the event's limits, every member rating-eligible and not already registered
+func checkTeamRegistration(db *gorm.DB, event *models.Event, team *models.Team, userID string) error {
+	if !event.IsTeamEvent() {
+		return &teamRegistrationError{http.StatusBadRequest, "This is not a team contest"}
+	}
+	if team.OwnerID != userID {
+		return &teamRegistrationError{http.StatusForbidden, "Only the team owner can register the team"}
+	}
+	size := len(team.Members)
+	if size < event.TeamSizeMin || size > event.TeamSizeMax {
+		return &teamRegistrationError{http.StatusBadRequest,
+			fmt.Sprintf("Teams in this contest need %d to %d members, yours has %d", event.TeamSizeMin, event.TeamSizeMax, size)}
+	}
+
+	memberIDs := make([]string, 0, size)
+	for _, m := range team.Members {
+		memberIDs = append(memberIDs, m.UserID)
+		if !event.RatingEligible(m.User.Rating) {
+			return &teamRegistrationError{http.StatusForbidden,
+				fmt.Sprintf("%s's rating is outside this contest's eligible range", m.User.Username)}
+		}
+	}
+
+	var registered int64
+	db.Model(&models.Registration{}).Where("event_id = ? AND user_id IN ?", event.ID, memberIDs).Count(&registered)
+	if registered > 0 {
+		return &teamRegistrationError{http.StatusBadRequest, "A member of this team is already registered"}
+	}
+	return nil
+}
+
+// registerTeam creates one registration per member, all carrying the team
+func registerTeam(tx *gorm.DB, event *models.Event, team *models.Team, paymentID string) ([]models.Registration, error) {
+	now := time.Now()
+	teamID := team.ID
+	registrations := make([]models.Registration, 0, len(team.Members))
+	for _, m := range team.Members {
+		registrations = append(registrations, models.Registration{
+			ID:        utils.GenerateID(),
+			UserID:    m.UserID,
+			EventID:   event.ID,
+			TeamID:    &teamID,
+			Status:    models.RegStatusPaid,
+			PaymentID: paymentID,
+			CreatedAt: now,
+		})
+	}
+	if err := tx.Create(&registrations).Error; err != nil {
+		return nil, err
+	}
+
+	memberIDs := make([]string, 0, len(registrations))
+	for _, reg := range registrations {
+		memberIDs = append(memberIDs, reg.UserID)
+	}
+	if err := tx.Model(&models.User{}).Where("id IN ?", memberIDs).
+		UpdateColumn("contest_count", gorm.Expr("contest_count + ?", 1)).Error; err != nil {
+		return nil, err
+	}
+	return registrations, nil
+}
+
+// CreateTeam handles POST /teams. The creator becomes its owner.
+func CreateTeam(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	var input CreateTeamInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Team name must be 1-50 characters"})
+		return
+	}
+
+	now := time.Now()
+	team := models.Team{
+		ID:        utils.GenerateID(),
+		Name:      name,
+		OwnerID:   userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&team).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.TeamMember{TeamID: team.ID, UserID: userID, Role: models.TeamRoleOwner, JoinedAt: now}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create team"})
+		return
+	}
+
+	created, _ := loadTeam(database.DB, team.ID)
+	c.JSON(http.StatusCreated, created)
+}
+
+// GetMyTeams handles GET /teams/my
+func GetMyTeams(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	var teams []models.Team
+	if err := database.DB.Preload("Members.User").
+		Where("id IN (?)", database.DB.Model(&models.TeamMember{}).Select("team_id").Where("user_id = ?", userID)).
+		Order("created_at desc").
+		Find(&teams).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch teams"})
+		return
+	}
+	c.JSON(http.StatusOK, teams)
+}
+
+// GetTeam handles GET /teams/:id
+func GetTeam(c *gin.Context) {
+	team, err := loadTeam(database.DB, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
+	}
+	c.JSON(http.StatusOK, team)
+}
+
+// InviteToTeam handles POST /teams/:id/invites (owner only)
+func InviteToTeam(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+	teamID := c.Param("id")
+
+	var input InviteToTeamInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	team, err := loadTeam(database.DB, teamID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
+	}
+	if team.OwnerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the team owner can invite members"})
+		return
+	}
+	if teamRosterLocked(database.DB, teamID) {
+		c.JSON(http.StatusConflict, gin.H{"error": errRosterLocked.Error()})
+		return
+	}
+
+	var invitee models.User
+	if err := database.DB.Select("id", "username").First(&invitee, "username = ?", input.Username).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	for _, m := range team.Members {
+		if m.UserID == invitee.ID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User is already a member"})
+			return
+		}
+	}
+
+	var pending int64
+	database.DB.Model(&models.TeamInvite{}).
+		Where("team_id = ? AND user_id = ? AND status = ?", teamID, invitee.ID, models.TeamInvitePending).
+		Count(&pending)
+	if pending > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "User already has a pending invite"})
+		return
+	}
+
+	invite := models.TeamInvite{
+		ID:        utils.GenerateID(),
+		TeamID:    teamID,
+		UserID:    invitee.ID,
+		InvitedBy: userID,
+		Status:    models.TeamInvitePending,
+		CreatedAt: time.Now(),
+	}
+	if err := database.DB.Create(&invite).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send invite"})
+		return
+	}
+
+	CreateNotification(database.DB, models.Notification{
+		UserID:  invitee.ID,
+		ActorID: userID,
+		Type:    models.NotificationTypeTeamInvite,
+		Message: fmt.Sprintf("invited you to join the team %s", team.Name),
+	})
+
+	c.JSON(http.StatusCreated, invite)
+}
+
+// GetMyTeamInvites handles GET /teams/invites, the caller's pending invites
+func GetMyTeamInvites(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	var invites []models.TeamInvite
+	if err := database.DB.Preload("Team").
+		Where("user_id = ? AND status = ?", userID, models.TeamInvitePending).
+		Order("created_at desc").
+		Find(&invites).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invites"})
+		return
+	}
+	c.JSON(http.StatusOK, invites)
+}
+
+// RespondToTeamInvite handles POST /teams/invites/:inviteId/accept and .../decline
+func RespondToTeamInvite(accept bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.MustGet("userId").(string)
+
+		var invite models.TeamInvite
+		if err := database.DB.First(&invite, "id = ?", c.Param("inviteId")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+			return
+		}
+		if invite.UserID != userID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not your invite"})
+			return
+		}
+		if invite.Status != models.TeamInvitePending {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invite was already answered"})
+			return
+		}
+
+		if !accept {
+			database.DB.Model(&invite).Update("status", models.TeamInviteDeclined)
+			c.JSON(http.StatusOK, gin.H{"message": "Invite declined"})
+			return
+		}
+
+		if teamRosterLocked(database.DB, invite.TeamID) {
+			c.JSON(http.StatusConflict, gin.H{"error": errRosterLocked.Error()})
+			return
+		}
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&invite).Update("status", models.TeamInviteAccepted).Error; err != nil {
+				return err
+			}
+			return tx.Create(&models.TeamMember{
+				TeamID:   invite.TeamID,
+				UserID:   userID,
+				Role:     models.TeamRoleMember,
+				JoinedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join team"})
+			return
+		}
+
+		team, _ := loadTeam(database.DB, invite.TeamID)
+		c.JSON(http.StatusOK, gin.H{"message": "Joined team", "team": team})
+	}
+}
+
+// LeaveTeam handles POST /teams/:id/leave. An owner leaving hands the team to the
+// longest-standing member; the last member leaving deletes it.
+func LeaveTeam(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+	teamID := c.Param("id")
+
+	team, err := loadTeam(database.DB, teamID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
+	}
+	isMember := false
+	for _, m := range team.Members {
+		if m.UserID == userID {
+			isMember = true
+		}
+	}
+	if !isMember {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You are not a member of this team"})
+		return
+	}
+	if teamRosterLocked(database.DB, teamID) {
+		c.JSON(http.StatusConflict, gin.H{"error": errRosterLocked.Error()})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ? AND user_id = ?", teamID, userID).Delete(&models.TeamMember{}).Error; err != nil {
+			return err
+		}
+
+		var next models.TeamMember
+		err := tx.Where("team_id = ?", teamID).Order("joined_at asc").First(&next).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			// Last one out; past registrations keep the team ID for the results
+			if err := tx.Where("team_id = ?", teamID).Delete(&models.TeamInvite{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&models.Team{}, "id = ?", teamID).Error
+		case err != nil:
+			return err
+		}
+
+		if team.OwnerID != userID {
+			return nil
+		}
+		if err := tx.Model(&models.TeamMember{}).Where("team_id = ? AND user_id = ?", teamID, next.UserID).
+			Update("role", models.TeamRoleOwner).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Team{}).Where("id = ?", teamID).Update("owner_id", next.UserID).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave team"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left team"})
+}
+
+// RegisterTeamForEvent handles POST /events/:id/register-team, the only way a team
+// registers. Paid events take the Razorpay payment of the order created with the team
+// (see CreateOrder); the order is checked with Razorpay and each payment is used once.
+func RegisterTeamForEvent(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+	eventID := c.Param("id")
+
+	var input RegisterTeamInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var event models.Event
+	if err := database.DB.First(&event, "id = ?", eventID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+	team, err := loadTeam(database.DB, input.TeamID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
+	}
+
+	var regErr *teamRegistrationError
+	if err := checkTeamRegistration(database.DB, &event, team, userID); errors.As(err, &regErr) {
+		c.JSON(regErr.status, gin.H{"error": regErr.message})
+		return
+	}
+
+	paymentID := ""
+	if event.Price > 0 {
+		if input.RazorpayOrderID == "" || input.RazorpayPaymentID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Payment details required"})
+			return
+		}
+		secret := os.Getenv("RAZORPAY_KEY_SECRET")
+		if secret == "" {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Payment configuration error"})
+			return
+		}
+		if !validRazorpaySignature(secret, input.RazorpayOrderID, input.RazorpayPaymentID, input.RazorpaySignature) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Payment verification failed: Invalid Signature"})
+			return
+		}
+		if err := checkTeamOrder(&event, team, input.RazorpayOrderID); errors.Is(err, errOrderMismatch) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		} else if err != nil {
+			logger.Error().Err(err).Str("order", input.RazorpayOrderID).Msg("Failed to fetch Razorpay order")
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Could not verify payment, please retry"})
+			return
+		}
+		paymentID = input.RazorpayPaymentID
+	}
+
+	var registrations []models.Registration
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if paymentID != "" {
+			if err := claimPayment(tx, paymentID); err != nil {
+				return err
+			}
+		}
+		registrations, err = registerTeam(tx, &event, team, paymentID)
+		return err
+	})
+	if errors.Is(err, errPaymentUsed) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register team"})
+		return
+	}
+	for _, reg := range registrations {
+		recordBadgeEvent(reg.UserID, models.MetricContestsJoined, 1)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"teamId": team.ID, "registrations": registrations})
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -9,7 +10,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/pushp314/devconnect-backend/internal/database"
 	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
+	"github.com/pushp314/devconnect-backend/pkg/logger"
 	"github.com/pushp314/devconnect-backend/pkg/utils"
+	"gorm.io/gorm"
 )
 
 // ProfileSummaryResponse defines the shape of the summary API
@@ -306,7 +310,9 @@ func GetProfile(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user": user, "isFollowing": isFollowing})
+	streak, _ := services.StreakFor(database.DB, user.ID, time.Now())
+
+	c.JSON(http.StatusOK, gin.H{"user": user, "isFollowing": isFollowing, "streak": streak})
 }
 
 // UpdateProfile handles PUT /users/profile
@@ -443,7 +449,7 @@ func GetPublicProfile(c *gin.Context) {
 	}
 
 	// Check Privacy
-	if !user.PublicProfileEnabled {
+	if !user.PublicProfileEnabled || user.ID == models.SystemUserID {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User profile not available"})
 		return
 	}
@@ -516,21 +522,9 @@ func EquipAura(c *gin.Context) {
 	}
 
 	// Verify ownership if it's a specific aura (not empty)
-	if input.AuraID != "" {
-		owned := false
-		for _, id := range user.PurchasedComponentIds {
-			if id == input.AuraID {
-				owned = true
-				break
-			}
-		}
-
-		// Allow equipping if it's one of the base/legacy auras too?
-		// Actually XPStore.tsx only handles purchased ones via equip.
-		if !owned {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Item not owned"})
-			return
-		}
+	if input.AuraID != "" && !services.OwnsStoreItem(database.DB, &user, input.AuraID, models.StoreCategoryAura) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Item not owned"})
+		return
 	}
 
 	if err := database.DB.Model(&user).Update("equippedAura", input.AuraID).Error; err != nil {
@@ -565,19 +559,9 @@ func EquipTheme(c *gin.Context) {
 	}
 
 	// Verify ownership if it's a specific theme (not empty)
-	if input.ThemeID != "" {
-		owned := false
-		for _, id := range user.PurchasedComponentIds {
-			if id == input.ThemeID {
-				owned = true
-				break
-			}
-		}
-
-		if !owned {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Item not owned"})
-			return
-		}
+	if input.ThemeID != "" && !services.OwnsStoreItem(database.DB, &user, input.ThemeID, models.StoreCategoryTheme) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Item not owned"})
+		return
 	}
 
 	if err := database.DB.Model(&user).Update("equippedTheme", input.ThemeID).Error; err != nil {
@@ -608,7 +592,7 @@ func ListCommunityUsers(c *gin.Context) {
 	}
 	offset := (page - 1) * limit
 
-	query := database.DB.Model(&models.User{}).Where("search_visible = ? AND onboarding_completed = ?", true, true)
+	query := database.DB.Model(&models.User{}).Where("search_visible = ? AND onboarding_completed = ? AND id <> ?", true, true, models.SystemUserID)
 
 	// Don't show own profile in community list
 	if userID, exists := c.Get("userId"); exists {
@@ -783,6 +767,9 @@ func GetStats(c *gin.Context) {
 		{"name": "Sun", "activity": 0},
 	}
 
+	// Daily problem streak
+	streak, _ := services.StreakFor(database.DB, user.ID, time.Now())
+
 	c.JSON(http.StatusOK, gin.H{
 		"snippets":            snippetCount,
 		"totalCopiesReceived": totalCopiesReceived,
@@ -791,6 +778,7 @@ func GetStats(c *gin.Context) {
 		"trustScore":          user.TrustScore,
 		"rankPercentile":      rankPercentile,
 		"chart":               chartData,
+		"streak":              streak,
 	})
 }
 
@@ -949,11 +937,16 @@ func GetBadges(c *gin.Context) {
 		return
 	}
 
-	// 1. Fetch All System Badges
+	// 1. Bring progress up to date (picks up badges added or edited since the last event)
+	if _, err := services.EvaluateBadges(database.DB, user.ID); err != nil {
+		logger.Error().Err(err).Str("user", user.ID).Msg("Failed to evaluate badges")
+	}
+
+	// 2. Fetch All System Badges
 	var allBadges []models.Badge
 	database.DB.Order("threshold asc").Find(&allBadges)
 
-	// 2. Fetch User's Unlocked/Progress Badges
+	// 3. Fetch User's Unlocked/Progress Badges
 	var userBadges []models.UserBadge
 	database.DB.Where("user_id = ?", user.ID).Find(&userBadges)
 
@@ -963,65 +956,32 @@ func GetBadges(c *gin.Context) {
 		userBadgeMap[ub.BadgeID] = ub
 	}
 
-	// 3. Calculate Stats for Self-Healing & Influence
-	// Count ALL snippets (including drafts, so users see progress immediately)
-	var snippetCount int64
-	database.DB.Model(&models.Snippet{}).Where("\"authorId\" = ?", user.ID).Count(&snippetCount)
-
-	// Count Contests
-	var contestCount int64
-	database.DB.Model(&models.Registration{}).Where("user_id = ? AND status != 'BANNED'", user.ID).Count(&contestCount)
-
-	// Count Practice Problems Solved
-	var practiceCount int64
-	database.DB.Model(&models.Submission{}).Where("user_id = ? AND status = 'ACCEPTED'", user.ID).Count(&practiceCount)
-
-	// Count Feedback Sent
-	var feedbackCount int64
-	database.DB.Model(&models.FeedbackMessage{}).Where("user_id = ?", user.ID).Count(&feedbackCount)
-
-	// Count Rank for Early Adopter (First 1000)
-	var userJoinRank int64
-	database.DB.Model(&models.User{}).
-		Where("\"createdAt\" <= (SELECT \"createdAt\" FROM \"User\" WHERE id = ?)", user.ID).
-		Count(&userJoinRank)
-	earlyAdopterProgress := int64(0)
-	if userJoinRank <= 1000 {
-		earlyAdopterProgress = 1
+	// Stats for Influence, kept by the badge engine
+	var metrics []models.UserMetric
+	database.DB.Where("user_id = ?", user.ID).Find(&metrics)
+	var snippetCount, contestCount int64
+	for _, m := range metrics {
+		switch m.Metric {
+		case models.MetricSnippetsCreated:
+			snippetCount = int64(m.Value)
+		case models.MetricContestsJoined:
+			contestCount = int64(m.Value)
+		}
 	}
 
-	// 4. Construct Response & Self-Healing
+	// 4. Construct Response
 	type BadgeResponse struct {
 		models.Badge
-		Unlocked   bool      `json:"unlocked"`
-		Progress   int64     `json:"progress"`
-		UnlockedAt time.Time `json:"unlockedAt,omitempty"`
+		Unlocked   bool       `json:"unlocked"`
+		Progress   int64      `json:"progress"`
+		UnlockedAt *time.Time `json:"unlockedAt,omitempty"`
 	}
 
 	var responseBadges []BadgeResponse
 	unlockedCount := 0
 
 	for _, badge := range allBadges {
-		ub, exists := userBadgeMap[badge.ID]
-
-		var currentProgress int64 = 0
-		if exists {
-			currentProgress = int64(ub.Progress)
-		}
-
-		// Dynamic Progress Check
-		switch badge.Condition {
-		case "1_snippet", "5_snippets", "10_snippets", "25_snippets", "snippet_master":
-			currentProgress = snippetCount
-		case "1_contest", "5_contests":
-			currentProgress = contestCount
-		case "1_practice_solved", "5_practice_solved", "25_practice_solved":
-			currentProgress = practiceCount
-		case "feedback_given", "5_feedback":
-			currentProgress = feedbackCount
-		case "early_adopter":
-			currentProgress = earlyAdopterProgress
-		}
+		ub := userBadgeMap[badge.ID]
 
 		// Dynamic Type assignment for refinement
 		if badge.Condition == "early_adopter" || badge.Condition == "contest_winner" || badge.Condition == "snippet_master" || badge.Condition == "25_snippets" {
@@ -1030,39 +990,15 @@ func GetBadges(c *gin.Context) {
 			badge.Type = models.BadgeType2D
 		}
 
-		// Logic Override for "Early Adopter" (Threshold 0)
-		isUnlocked := exists && (badge.Threshold == 0 || ub.Progress >= badge.Threshold)
-
-		// Self-Healing
-		if !isUnlocked && badge.Threshold > 0 && currentProgress >= int64(badge.Threshold) {
-			newBadge := models.UserBadge{
-				UserID:     user.ID,
-				BadgeID:    badge.ID,
-				Progress:   int(currentProgress),
-				UnlockedAt: time.Now(),
-			}
-			if err := database.DB.Create(&newBadge).Error; err == nil {
-				isUnlocked = true
-				exists = true
-				ub = newBadge
-			}
-		}
-
-		if isUnlocked {
+		if ub.UnlockedAt != nil {
 			unlockedCount++
-			responseBadges = append(responseBadges, BadgeResponse{
-				Badge:      badge,
-				Unlocked:   true,
-				Progress:   currentProgress,
-				UnlockedAt: ub.UnlockedAt,
-			})
-		} else {
-			responseBadges = append(responseBadges, BadgeResponse{
-				Badge:    badge,
-				Unlocked: false,
-				Progress: currentProgress,
-			})
 		}
+		responseBadges = append(responseBadges, BadgeResponse{
+			Badge:      badge,
+			Unlocked:   ub.UnlockedAt != nil,
+			Progress:   int64(ub.Progress),
+			UnlockedAt: ub.UnlockedAt,
+		})
 	}
 
 	// 5. Calculate Influence Score
@@ -1114,14 +1050,18 @@ func GetBadges(c *gin.Context) {
 
 // GetGlobalLeaderboard returns top users by XP or Influence
 func GetGlobalLeaderboard(c *gin.Context) {
-	lbType := c.Query("type") // "creators" or "xp" (default)
+	lbType := c.Query("type") // "creators", "rating" or "xp" (default)
 	var users []models.User
-	query := database.DB.Model(&models.User{}).Where("onboarding_completed = ?", true)
+	query := database.DB.Model(&models.User{}).Where("onboarding_completed = ? AND id <> ?", true, models.SystemUserID)
 
-	if lbType == "creators" {
+	switch lbType {
+	case "creators":
 		// Influence Score = trust_score + (snippet_count * 10) + (contest_count * 25) + (linkersCount * 50) + (xp / 100)
 		query = query.Order("((trust_score) + (snippet_count * 10) + (contest_count * 25) + (\"linkersCount\" * 50) + (xp / 100)) DESC")
-	} else {
+	case "rating":
+		// Contest rating, rated users only
+		query = query.Where("rated_contests > 0").Order("rating DESC, max_rating DESC, \"createdAt\" ASC")
+	default:
 		query = query.Where("xp > 0").Order("xp DESC, \"createdAt\" ASC")
 	}
 
@@ -1146,13 +1086,16 @@ func GetGlobalLeaderboard(c *gin.Context) {
 			"id":             u.ID,
 			"snippetsCount":  u.WrappedSnippetCount,
 			"influenceScore": influence,
+			"rating":         u.Rating,
+			"maxRating":      u.MaxRating,
 		})
 	}
 
 	c.JSON(http.StatusOK, gin.H{"leaderboard": safeUsers})
 }
 
-// SpendXP handles POST /users/spend-xp
+// SpendXP handles POST /users/spend-xp. The price comes from the store catalog; an
+// amount sent by older clients is ignored.
 func SpendXP(c *gin.Context) {
 	userId, exists := c.Get("userId")
 	if !exists {
@@ -1162,7 +1105,6 @@ func SpendXP(c *gin.Context) {
 
 	var input struct {
 		ItemID string `json:"itemId" binding:"required"`
-		Amount int    `json:"amount"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -1170,34 +1112,42 @@ func SpendXP(c *gin.Context) {
 		return
 	}
 
-	var user models.User
-	if err := database.DB.First(&user, "id = ?", userId).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	var item models.StoreItem
+	if err := database.DB.First(&item, "id = ?", input.ItemID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
 		return
 	}
-
-	if user.XP < input.Amount {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient XP balance"})
+	if !storeCategoryEnabled(item.Category) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This part of the store is currently disabled"})
 		return
 	}
 
-	// Check if already purchased
-	for _, id := range user.PurchasedComponentIds {
-		if id == input.ItemID {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Item already unlocked"})
-			return
-		}
-	}
-
-	user.XP -= input.Amount
-	user.PurchasedComponentIds = append(user.PurchasedComponentIds, input.ItemID)
-
-	if err := database.DB.Save(&user).Error; err != nil {
+	user, _, err := services.PurchaseStoreItem(database.DB, userId.(string), item.ID, time.Now())
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	case errors.Is(err, services.ErrInsufficientXP):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient XP balance", "price": item.Price})
+		return
+	case errors.Is(err, services.ErrItemOwned):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Item already unlocked"})
+		return
+	case errors.Is(err, services.ErrItemUnavailable):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Item is not available"})
+		return
+	case errors.Is(err, services.ErrItemLevelLocked):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your level is too low for this item", "minLevel": item.MinLevel})
+		return
+	case errors.Is(err, services.ErrStreakFreezeCap):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You already hold the maximum number of streak freezes", "maxFreezes": models.MaxStreakFreezes})
+		return
+	case err != nil:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete transaction"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Item unlocked successfully", "xp": user.XP, "purchasedIds": user.PurchasedComponentIds})
+	c.JSON(http.StatusOK, gin.H{"message": "Item unlocked successfully", "xp": user.XP, "price": item.Price, "purchasedIds": user.PurchasedComponentIds})
 }
 
 // GenerateVaultKey handles POST /api/users/vault/key
@@ -28,10 +28,23 @@ func GetMyContestHistory(c *gin.Context) {
 		return
 	}
 
+	// Finalized contests are served from the results snapshot, so moderation after the
+	// fact only shows up once an admin re-ranks
+	eventIDs := make([]string, 0, len(registrations))
+	for _, reg := range registrations {
+		eventIDs = append(eventIDs, reg.EventID)
+	}
+	var results []models.ContestResult
+	database.DB.Where("user_id = ? AND event_id IN ?", userID, eventIDs).Find(&results)
+	resultByEvent := make(map[string]models.ContestResult, len(results))
+	for _, r := range results {
+		resultByEvent[r.EventID] = r
+	}
+
 	var history []gin.H
 	for _, reg := range registrations {
 		// Only include finished contests or joined ones
-		history = append(history, gin.H{
+		entry := gin.H{
 			"id":            reg.Event.ID,
 			"title":         reg.Event.Title,
 			"rank":          reg.Rank,
@@ -40,7 +53,20 @@ func GetMyContestHistory(c *gin.Context) {
 			"startTime":     reg.Event.StartTime,
 			"endTime":       reg.Event.EndTime,
 			"rulesAccepted": reg.RulesAccepted,
-		})
+			"final":         false,
+		}
+		if resultsHidden(c, &reg.Event) {
+			// Frozen scoreboard: rank and score wait for the resolver
+			entry["rank"] = nil
+			entry["score"] = nil
+		} else if result, ok := resultByEvent[reg.EventID]; ok {
+			entry["rank"] = result.Rank
+			entry["score"] = result.Score
+			entry["solvedCount"] = result.SolvedCount
+			entry["penalty"] = result.Penalty
+			entry["final"] = true
+		}
+		history = append(history, entry)
 	}
 
 	c.JSON(http.StatusOK, gin.H{"history": history})
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
+	"gorm.io/gorm"
+)
+
+// findVirtualParticipation returns a user's virtual run of an event, if they started one
+func findVirtualParticipation(userID, eventID string) (*models.VirtualParticipation, bool) {
+	var vp models.VirtualParticipation
+	if err := database.DB.Where("user_id = ? AND event_id = ?", userID, eventID).First(&vp).Error; err != nil {
+		return nil, false
+	}
+	return &vp, true
+}
+
+// StartVirtualContest handles POST /events/:id/virtual
+func StartVirtualContest(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	vp, err := services.StartVirtual(database.DB, c.Param("id"), userID, time.Now())
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	case errors.Is(err, services.ErrVirtualUnavailable), errors.Is(err, services.ErrVirtualParticipated), errors.Is(err, services.ErrVirtualFrozen):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrVirtualStarted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start virtual participation"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"participation": vp, "active": true, "remainingSeconds": int(time.Until(vp.EndsAt).Seconds())})
+}
+
+// GetVirtualContest handles GET /events/:id/virtual, the caller's virtual run and clock
+func GetVirtualContest(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	vp, ok := findVirtualParticipation(userID, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No virtual participation"})
+		return
+	}
+
+	now := time.Now()
+	remaining := 0
+	if vp.Active(now) {
+		remaining = int(vp.EndsAt.Sub(now).Seconds())
+	}
+	c.JSON(http.StatusOK, gin.H{"participation": vp, "active": vp.Active(now), "remainingSeconds": remaining})
+}
+
+// GetVirtualLeaderboard handles GET /events/:id/virtual/leaderboard: the caller placed
+// among the original participants at the same elapsed time
+func GetVirtualLeaderboard(c *gin.Context) {
+	userID := c.MustGet("userId").(string)
+
+	vp, ok := findVirtualParticipation(userID, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No virtual participation"})
+		return
+	}
+
+	standings, elapsed, err := services.GhostLeaderboard(database.DB, vp, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build leaderboard"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"eventId":        vp.EventID,
+		"elapsedMinutes": elapsed.Minutes(),
+		"finished":       !time.Now().Before(vp.EndsAt),
+		"leaderboard":    standings,
+	})
+}
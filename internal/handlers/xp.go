@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
+	"gorm.io/gorm"
+)
+
+// xpHistory pages through a user's ledger, newest first (?page=&limit=&source=)
+func xpHistory(c *gin.Context, userID string) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	query := database.DB.Model(&models.XPLedgerEntry{}).Where("user_id = ?", userID)
+	if source := c.Query("source"); source != "" {
+		query = query.Where("source = ?", source)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var entries []models.XPLedgerEntry
+	if err := query.Order("created_at desc, id desc").Offset((page - 1) * limit).Limit(limit).Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch XP history"})
+		return
+	}
+
+	var user models.User
+	database.DB.Select("id", "xp", "level").First(&user, "id = ?", userID)
+	curve := services.LevelCurveFromSettings(database.DB)
+
+	c.JSON(http.StatusOK, gin.H{
+		"xp":           user.XP,
+		"level":        user.Level,
+		"nextLevelXp":  curve.MinXP(user.Level + 1),
+		"levelStartXp": curve.MinXP(user.Level),
+		"entries":      entries,
+		"pagination": gin.H{
+			"page":       page,
+			"limit":      limit,
+			"total":      total,
+			"totalPages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// GetMyXPHistory handles GET /users/me/xp
+func GetMyXPHistory(c *gin.Context) {
+	xpHistory(c, c.MustGet("userId").(string))
+}
+
+// AdminGetUserXPHistory handles GET /admin/users/:id/xp
+func AdminGetUserXPHistory(c *gin.Context) {
+	xpHistory(c, c.Param("id"))
+}
+
+// AdminReverseXPEntry handles POST /admin/users/:id/xp/:entryId/reverse
+func AdminReverseXPEntry(c *gin.Context) {
+	userID, entryID := c.Param("id"), c.Param("entryId")
+	adminID := getAdminID(c)
+
+	var req struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required"})
+		return
+	}
+
+	var entry models.XPLedgerEntry
+	if err := database.DB.First(&entry, "id = ? AND user_id = ?", entryID, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Entry not found"})
+		return
+	}
+
+	var reversal *models.XPLedgerEntry
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if reversal, err = services.ReverseXP(tx, entry.ID, adminID, req.Reason); err != nil {
+			return err
+		}
+		return logAdminAction(tx, adminID, models.ActionReverseXP, userID, "user",
+			"Reversed "+strconv.Itoa(entry.Amount)+" XP ("+string(entry.Source)+"): "+req.Reason)
+	})
+	switch {
+	case errors.Is(err, services.ErrXPAlreadyReversed), errors.Is(err, services.ErrXPNotReversible):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reverse entry"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reversal": reversal})
+}
@@ -0,0 +1,226 @@
+package judge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
+)
+
+// DefaultEpsilon is used by FLOAT checkers that don't set one
+const DefaultEpsilon = 1e-6
+
+// ErrCheckerFailed means the checker program itself misbehaved (crashed, timed out,
+// bad exit code). It is a problem-setup issue, so the submission is not judged.
+var ErrCheckerFailed = errors.New("checker failed")
+
+// Checker decides whether an output is correct for a test case.
+// ok=false carries an optional message; err is reserved for checker/infra failures.
+type Checker interface {
+	Check(input, expected, actual string) (ok bool, message string, err error)
+}
+
+// CheckerFunc adapts a plain comparison to the Checker interface
+type CheckerFunc func(input, expected, actual string) (bool, string, error)
+
+func (f CheckerFunc) Check(input, expected, actual string) (bool, string, error) {
+	return f(input, expected, actual)
+}
+
+// ExactChecker compares normalized output (see Normalize)
+var ExactChecker = CheckerFunc(func(_, expected, actual string) (bool, string, error) {
+	return Compare(actual, expected), "", nil
+})
+
+// TokenChecker ignores all whitespace differences
+var TokenChecker = CheckerFunc(func(_, expected, actual string) (bool, string, error) {
+	return tokensEqual(strings.Fields(expected), strings.Fields(actual), -1), "", nil
+})
+
+// FloatChecker compares token-wise, accepting numbers within an absolute or relative epsilon
+func FloatChecker(epsilon float64) Checker {
+	if epsilon <= 0 {
+		epsilon = DefaultEpsilon
+	}
+	return CheckerFunc(func(_, expected, actual string) (bool, string, error) {
+		return tokensEqual(strings.Fields(expected), strings.Fields(actual), epsilon), "", nil
+	})
+}
+
+// tokensEqual compares token lists; epsilon < 0 means exact string comparison
+func tokensEqual(expected, actual []string, epsilon float64) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+	for i := range expected {
+		if expected[i] == actual[i] {
+			continue
+		}
+		if epsilon < 0 {
+			return false
+		}
+		e, err1 := strconv.ParseFloat(expected[i], 64)
+		a, err2 := strconv.ParseFloat(actual[i], 64)
+		if err1 != nil || err2 != nil || math.IsNaN(a) {
+			return false
+		}
+		diff := math.Abs(e - a)
+		if diff > epsilon && diff > epsilon*math.Abs(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// ProgramChecker runs a checker program through the execution service for every test case.
+// It is compiled on the first Check and reused until Close, so build one per judging run.
+type ProgramChecker struct {
+	Exec     services.Executor
+	Language string
+	Code     string
+
+	prog services.CompiledProgram
+}
+
+type checkerInput struct {
+	Input    string `json:"input"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+func (p *ProgramChecker) Check(input, expected, actual string) (bool, string, error) {
+	stdin, _ := json.Marshal(checkerInput{Input: input, Expected: expected, Actual: actual})
+
+	res, err := p.run(string(stdin))
+	if err != nil {
+		return false, "", err
+	}
+	if res.Run.Signal != "" {
+		return false, "", fmt.Errorf("%w: killed by %s", ErrCheckerFailed, res.Run.Signal)
+	}
+
+	message := strings.TrimSpace(strings.SplitN(res.Run.Stdout, "\n", 2)[0])
+	switch res.Run.Code {
+	case 0:
+		return true, message, nil
+	case 1:
+		return false, message, nil
+	default:
+		return false, "", fmt.Errorf("%w: exit code %d", ErrCheckerFailed, res.Run.Code)
+	}
+}
+
+// run executes the checker program on stdin, compiling it first if needed. A checker
+// that doesn't build fails with ErrCheckerFailed.
+func (p *ProgramChecker) run(stdin string) (*services.ExecutionResult, error) {
+	if p.prog == nil {
+		prog, err := services.CompileCode(p.Exec, p.Language, p.Code, services.ExecuteOptions{})
+		if err != nil {
+			return nil, checkerError(err)
+		}
+		p.prog = prog
+	}
+	if err := compileError(p.prog.Compile()); err != nil {
+		return nil, err
+	}
+
+	res, err := p.prog.Run(context.Background(), stdin)
+	if err != nil {
+		return nil, checkerError(err)
+	}
+	// Backends that build on every run report the compile stage with it
+	if err := compileError(res.Compile); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Close releases the compiled checker
+func (p *ProgramChecker) Close() error {
+	if p.prog == nil {
+		return nil
+	}
+	return p.prog.Close()
+}
+
+// checkerError keeps executor outages retryable and blames anything else on the checker
+func checkerError(err error) error {
+	if errors.Is(err, services.ErrExecutorUnavailable) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrCheckerFailed, err)
+}
+
+func compileError(stage *services.StageResult) error {
+	if stage == nil || (stage.Code == 0 && stage.Signal == "") {
+		return nil
+	}
+	output := stage.Stderr
+	if output == "" {
+		output = stage.Stdout
+	}
+	return fmt.Errorf("%w: compilation error: %s", ErrCheckerFailed, output)
+}
+
+// VerifyChecker builds a PROGRAM checker so one that doesn't compile is refused when it
+// is saved instead of failing every submission. Backends without a separate compile
+// step build it by running it once on empty input; how that run ends doesn't matter.
+func VerifyChecker(exec services.Executor, cfg models.CheckerConfig) error {
+	if cfg.CheckerType != models.CheckerProgram {
+		return nil
+	}
+	checker := &ProgramChecker{Exec: exec, Language: cfg.CheckerLanguage, Code: cfg.CheckerCode}
+	defer checker.Close()
+	stdin, _ := json.Marshal(checkerInput{})
+	_, err := checker.run(string(stdin))
+	return err
+}
+
+// NewChecker builds the checker for a problem's configuration
+func NewChecker(exec services.Executor, cfg models.CheckerConfig) Checker {
+	switch cfg.CheckerType {
+	case models.CheckerToken:
+		return TokenChecker
+	case models.CheckerFloat:
+		return FloatChecker(cfg.CheckerEpsilon)
+	case models.CheckerProgram:
+		return &ProgramChecker{Exec: exec, Language: cfg.CheckerLanguage, Code: cfg.CheckerCode}
+	default:
+		return ExactChecker
+	}
+}
+
+// ValidateChecker normalizes and checks a checker configuration submitted by an admin
+func ValidateChecker(cfg *models.CheckerConfig) error {
+	if cfg.CheckerType == "" {
+		cfg.CheckerType = models.CheckerExact
+	}
+	cfg.CheckerType = models.CheckerType(strings.ToUpper(string(cfg.CheckerType)))
+
+	switch cfg.CheckerType {
+	case models.CheckerExact, models.CheckerToken:
+	case models.CheckerFloat:
+		if cfg.CheckerEpsilon < 0 || cfg.CheckerEpsilon >= 1 {
+			return fmt.Errorf("checkerEpsilon must be between 0 and 1")
+		}
+		if cfg.CheckerEpsilon == 0 {
+			cfg.CheckerEpsilon = DefaultEpsilon
+		}
+	case models.CheckerProgram:
+		if strings.TrimSpace(cfg.CheckerCode) == "" {
+			return fmt.Errorf("checkerCode is required for PROGRAM checkers")
+		}
+		if cfg.CheckerLanguage == "" {
+			return fmt.Errorf("checkerLanguage is required for PROGRAM checkers")
+		}
+	default:
+		return fmt.Errorf("unknown checkerType %q (want EXACT, TOKEN, FLOAT or PROGRAM)", cfg.CheckerType)
+	}
+	return nil
+}
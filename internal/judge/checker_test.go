@@ -0,0 +1,102 @@
+package judge
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenAndFloatCheckers(t *testing.T) {
+	ok, _, _ := TokenChecker.Check("", "1 2\n3", "1\n2 3  ")
+	assert.True(t, ok)
+	ok, _, _ = TokenChecker.Check("", "1 2 3", "1 2")
+	assert.False(t, ok)
+
+	float := FloatChecker(1e-4)
+	ok, _, _ = float.Check("", "3.14159 x", "3.14160 x")
+	assert.True(t, ok)
+	ok, _, _ = float.Check("", "3.14159", "3.15")
+	assert.False(t, ok)
+	ok, _, _ = float.Check("", "1000000", "1000050") // within relative epsilon
+	assert.True(t, ok)
+	ok, _, _ = float.Check("", "1", "nan")
+	assert.False(t, ok)
+}
+
+func TestProgramChecker(t *testing.T) {
+	exec := services.NewFakeExecutor()
+	exec.Handle = func(req services.ExecutionRequest) (*services.ExecutionResult, error) {
+		var in checkerInput
+		json.Unmarshal([]byte(req.Stdin), &in)
+		switch in.Actual {
+		case "good":
+			return &services.ExecutionResult{Run: services.StageResult{Stdout: "ok\n"}}, nil
+		case "bad":
+			return &services.ExecutionResult{Run: services.StageResult{Stdout: "expected a permutation\n", Code: 1}}, nil
+		default:
+			return &services.ExecutionResult{Run: services.StageResult{Code: 2}}, nil
+		}
+	}
+	checker := NewChecker(exec, models.CheckerConfig{CheckerType: models.CheckerProgram, CheckerLanguage: "python", CheckerCode: "# checker"})
+
+	ok, _, err := checker.Check("in", "exp", "good")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, msg, err := checker.Check("in", "exp", "bad")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "expected a permutation", msg)
+
+	_, _, err = checker.Check("in", "exp", "crash")
+	assert.ErrorIs(t, err, ErrCheckerFailed)
+}
+
+func TestProgramChecker_CompilesOnce(t *testing.T) {
+	exec := &compilingExecutor{FakeExecutor: services.NewFakeExecutor()}
+	checker := NewChecker(exec, models.CheckerConfig{CheckerType: models.CheckerProgram, CheckerLanguage: "c++", CheckerCode: "// checker"})
+	cases := []Case{{Input: "1", Expected: "1"}, {Input: "2", Expected: "2"}, {Input: "3", Expected: "3"}}
+
+	result, err := Run(services.NewFakeExecutor(), "python", "print(input()) # checked", cases, Options{Checker: checker, NoCache: true})
+	assert.NoError(t, err)
+	assert.Equal(t, models.SubStatusAC, result.Status)
+	assert.Equal(t, 1, exec.compiles, "the checker is built once per judging run")
+}
+
+func TestVerifyChecker(t *testing.T) {
+	exec := services.NewFakeExecutor()
+	exec.Handle = func(req services.ExecutionRequest) (*services.ExecutionResult, error) {
+		if req.Code == "// broken" {
+			return &services.ExecutionResult{Compile: &services.StageResult{Code: 1, Stderr: "checker.cpp:1: error"}}, nil
+		}
+		return &services.ExecutionResult{Compile: &services.StageResult{}, Run: services.StageResult{Code: 3}}, nil
+	}
+	program := func(code string) models.CheckerConfig {
+		return models.CheckerConfig{CheckerType: models.CheckerProgram, CheckerLanguage: "c++", CheckerCode: code}
+	}
+
+	err := VerifyChecker(exec, program("// broken"))
+	assert.ErrorIs(t, err, ErrCheckerFailed)
+	assert.Contains(t, err.Error(), "checker.cpp:1: error")
+	assert.NoError(t, VerifyChecker(exec, program("// fine")), "only the build is verified")
+	assert.NoError(t, VerifyChecker(nil, models.CheckerConfig{CheckerType: models.CheckerExact}))
+
+	exec.Handle = func(req services.ExecutionRequest) (*services.ExecutionResult, error) {
+		return nil, services.ErrExecutorUnavailable
+	}
+	assert.ErrorIs(t, VerifyChecker(exec, program("// fine, later")), services.ErrExecutorUnavailable)
+}
+
+func TestValidateChecker(t *testing.T) {
+	cfg := models.CheckerConfig{CheckerType: "float"}
+	assert.NoError(t, ValidateChecker(&cfg))
+	assert.Equal(t, models.CheckerFloat, cfg.CheckerType)
+	assert.Equal(t, DefaultEpsilon, cfg.CheckerEpsilon)
+
+	assert.Error(t, ValidateChecker(&models.CheckerConfig{CheckerType: models.CheckerFloat, CheckerEpsilon: 2}))
+	assert.Error(t, ValidateChecker(&models.CheckerConfig{CheckerType: models.CheckerProgram, CheckerLanguage: "python"}))
+	assert.Error(t, ValidateChecker(&models.CheckerConfig{CheckerType: "REGEX"}))
+}
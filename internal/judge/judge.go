@@ -0,0 +1,455 @@
+// Package judge holds the verdict logic shared by contest and practice submissions:
+// run every test case, check the output and classify failures.
+package judge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
+)
+
+// Case is one test case; Input is fed to the program as stdin
+type Case struct {
+	Input    string
+	Expected string
+	Hidden   bool
+	Group    int // IOI test group, 0 = none
+}
+
+// Result is the overall verdict for a submission
+type Result struct {
+	Status        models.SubmissionStatus
+	Verdict       string
+	Passed        int
+	Total         int
+	Runtime       float64 // ms, summed over executed cases, excluding compilation
+	Memory        int     // Peak KB over executed cases, 0 if not reported
+	CompileTime   float64 // ms, 0 for interpreted languages or if not reported
+	CompileOutput string  // Compiler diagnostics (truncated), set on Compilation Error
+	LastRun       *services.ExecutionResult
+	Cases         []models.TestCaseResult // Executed cases only; judging stops at the first failure unless Partial
+}
+
+// Options are the per-problem judging settings
+type Options struct {
+	TimeLimit   float64 // seconds per case
+	MemoryLimit int     // MB
+	Checker     Checker // nil = ExactChecker; closed when Run returns if it is an io.Closer
+	NoCache     bool    // Bypass the execution cache (contest judging)
+	Partial     bool    // Keep going after a failure (IOI); only cases of an already failed group are skipped
+}
+
+// Run compiles code once, executes it against every case in order and stops at the first failure
+// (with opts.Partial, the verdict is still the first failure but the remaining groups are judged).
+// The returned error is non-nil only when the submission could not be judged:
+// infrastructure failures (services.ErrExecutorUnavailable), which callers should
+// retry, or a broken checker (ErrCheckerFailed), which needs staff to fix the problem.
+// Neither is the submission's verdict. Requests the backend rejects (services.ErrExecutionRejected) get a Runtime Error verdict instead.
+// With no cases the program is run once with empty stdin and accepted if it runs cleanly.
+func Run(exec services.Executor, language, code string, cases []Case, opts Options) (*Result, error) {
+	result := &Result{Total: len(cases)}
+	checker := opts.Checker
+	if checker == nil {
+		checker = ExactChecker
+	}
+	if closer, ok := checker.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	prog, err := services.CompileCode(exec, language, code, services.ExecuteOptions{
+		TimeLimit:   opts.TimeLimit,
+		MemoryLimit: opts.MemoryLimit,
+		NoCache:     opts.NoCache,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrExecutorUnavailable) {
+			return nil, err
+		}
+		// Rejected before running (e.g. unsupported library)
+		result.Status = models.SubStatusRE
+		result.Verdict = "Runtime Error: " + err.Error()
+		return result, nil
+	}
+	defer prog.Close()
+
+	// Backends that build up front report a failed compile before any test runs
+	if compile := prog.Compile(); compile != nil {
+		result.CompileTime = float64(compile.WallTime)
+		if compileFailed(compile) {
+			return compilationError(result, compile), nil
+		}
+	}
+
+	runCases := cases
+	if len(runCases) == 0 {
+		runCases = []Case{{}}
+	}
+
+	// Batching backends run the upcoming cases together; batch holds results not used yet
+	batcher, batched := prog.(services.BatchProgram)
+	var batch []*services.ExecutionResult
+
+	failedGroups := make(map[int]bool)
+	for i, tc := range runCases {
+		if opts.Partial && tc.Group != 0 && failedGroups[tc.Group] {
+			if len(batch) > 0 {
+				batch = batch[1:]
+			}
+			continue // The group scores nothing already
+		}
+
+		var res *services.ExecutionResult
+		var err error
+		var elapsed float64 // ms
+		if batched {
+			if len(batch) == 0 {
+				inputs := make([]string, 0, len(runCases)-i)
+				for _, next := range runCases[i:] {
+					inputs = append(inputs, next.Input)
+				}
+				batch, err = batcher.RunBatch(context.Background(), inputs)
+			}
+			if err == nil {
+				res, batch = batch[0], batch[1:]
+				elapsed = float64(res.Run.WallTime)
+			}
+		} else {
+			start := time.Now()
+			res, err = prog.Run(context.Background(), tc.Input)
+			elapsed = time.Since(start).Seconds() * 1000
+		}
+		if errors.Is(err, services.ErrExecutionRejected) {
+			// The backend won't run it at all (e.g. an unknown version); retrying can't help
+			result.Status = models.SubStatusRE
+			result.Verdict = "Runtime Error: " + err.Error()
+			return result, nil
+		}
+		if err != nil {
+			return nil, err // Guards already passed, so this is the backend failing
+		}
+		result.LastRun = res
+
+		// Per-run backends compile with every request; keep compilation out of the runtime
+		if res.Compile != nil {
+			if compileFailed(res.Compile) {
+				return compilationError(result, res.Compile), nil
+			}
+			if result.CompileTime == 0 {
+				result.CompileTime = float64(res.Compile.WallTime)
+			}
+			elapsed -= float64(res.Compile.WallTime)
+		}
+		if res.Run.WallTime > 0 {
+			elapsed = float64(res.Run.WallTime)
+		}
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		result.Runtime += elapsed
+
+		caseResult := models.TestCaseResult{
+			Index:    i + 1,
+			Hidden:   tc.Hidden,
+			Group:    tc.Group,
+			Runtime:  elapsed,
+			Memory:   res.Run.Memory / 1024,
+			Input:    truncate(tc.Input),
+			Expected: truncate(tc.Expected),
+			Stdout:   truncate(res.Run.Stdout),
+			Stderr:   truncate(res.Run.Stderr),
+		}
+		if caseResult.Memory > result.Memory {
+			result.Memory = caseResult.Memory
+		}
+
+		status, verdict := Classify(res, opts.MemoryLimit)
+		if status == "" && len(cases) > 0 {
+			ok, message, err := checker.Check(tc.Input, tc.Expected, res.Run.Stdout)
+			if err != nil {
+				return nil, err
+			}
+			caseResult.Message = message
+			if !ok {
+				status, verdict = models.SubStatusWA, fmt.Sprintf("Wrong Answer on test %d", i+1)
+				if message != "" {
+					verdict += ": " + message
+				}
+				caseResult.Diff = Diff(tc.Expected, res.Run.Stdout)
+			}
+		}
+		if status == "" {
+			status = models.SubStatusAC
+		}
+		caseResult.Status = status
+		result.Cases = append(result.Cases, caseResult)
+
+		if status != models.SubStatusAC {
+			if result.Status == "" {
+				result.Status = status
+				result.Verdict = verdict
+			}
+			if !opts.Partial {
+				return result, nil
+			}
+			failedGroups[tc.Group] = true
+			continue
+		}
+		if len(cases) > 0 {
+			result.Passed++
+		}
+	}
+
+	if result.Status == "" {
+		result.Status = models.SubStatusAC
+		result.Verdict = "Accepted"
+	}
+	return result, nil
+}
+
+// Score awards partial points for a judged submission: each group in groups is worth its
+// Points if every case in it passed; without groups, points are split evenly across cases.
+// Cases that were skipped or never ran count as failed.
+func Score(cases []Case, result *Result, groups []models.TestGroup, points int) int {
+	if result == nil || result.Status == models.SubStatusCE {
+		return 0
+	}
+	if result.Status == models.SubStatusAC && len(groups) == 0 {
+		return points
+	}
+
+	passed := make(map[int]bool, len(result.Cases))
+	for _, cr := range result.Cases {
+		if cr.Status == models.SubStatusAC {
+			passed[cr.Index] = true
+		}
+	}
+
+	if len(groups) == 0 {
+		if len(cases) == 0 {
+			return 0
+		}
+		return points * len(passed) / len(cases)
+	}
+
+	score := 0
+	for _, g := range groups {
+		complete, size := true, 0
+		for i, tc := range cases {
+			if tc.Group != g.Group {
+				continue
+			}
+			size++
+			if !passed[i+1] {
+				complete = false
+				break
+			}
+		}
+		if complete && size > 0 {
+			score += g.Points
+		}
+	}
+	return score
+}
+
+// MaxCompileOutput bounds the compiler diagnostics stored on a submission
+const MaxCompileOutput = 4096
+
+func compileFailed(stage *services.StageResult) bool {
+	return stage.Code != 0 || stage.Signal != ""
+}
+
+// compilationError finalizes a result whose compile stage failed; no test case counts as run
+func compilationError(result *Result, compile *services.StageResult) *Result {
+	output := compile.Stderr
+	if strings.TrimSpace(output) == "" {
+		output = compile.Stdout // Some toolchains print diagnostics to stdout
+	}
+	if compile.Signal != "" && strings.TrimSpace(output) == "" {
+		output = "Compiler killed by " + compile.Signal
+	}
+	if len(output) > MaxCompileOutput {
+		output = output[:MaxCompileOutput] + "\n...(truncated)"
+	}
+
+	result.Status = models.SubStatusCE
+	result.Verdict = "Compilation Error"
+	result.CompileOutput = output
+	result.CompileTime = float64(compile.WallTime)
+	result.Cases = nil
+	return result
+}
+
+// outOfMemoryMarkers are stderr fragments of runtimes dying on a failed allocation
+var outOfMemoryMarkers = []string{"MemoryError", "std::bad_alloc", "java.lang.OutOfMemoryError", "out of memory", "JavaScript heap out of memory"}
+
+// Classify inspects an execution for compile errors, memory/time limits and crashes.
+// memoryLimit is in MB (0 = none). It returns an empty status if the program ran to completion.
+func Classify(res *services.ExecutionResult, memoryLimit int) (models.SubmissionStatus, string) {
+	if res.Compile != nil && (res.Compile.Code != 0 || res.Compile.Signal != "") {
+		return models.SubStatusCE, "Compilation Error"
+	}
+
+	run := res.Run
+
+	// Checked before TLE: the backend kills a process over its memory limit with SIGKILL too.
+	// Backends without a hard cap (local) report peak usage, which is enforced here.
+	if memoryLimit > 0 && run.Memory >= memoryLimit*1024*1024 {
+		return models.SubStatusMLE, "Memory Limit Exceeded"
+	}
+	if run.Code != 0 || run.Signal != "" {
+		for _, marker := range outOfMemoryMarkers {
+			if strings.Contains(run.Stderr, marker) {
+				return models.SubStatusMLE, "Memory Limit Exceeded"
+			}
+		}
+	}
+
+	switch {
+	case run.Signal == "SIGKILL" || run.Signal == "SIGTERM":
+		return models.SubStatusTLE, "Time Limit Exceeded"
+	case run.Signal != "":
+		return models.SubStatusRE, "Runtime Error (" + run.Signal + ")"
+	case run.Code == 137: // 128 + 9 (SIGKILL)
+		return models.SubStatusTLE, "Time Limit Exceeded"
+	case run.Code != 0:
+		verdict := fmt.Sprintf("Runtime Error (Exit Code %d)", run.Code)
+		if len(run.Stderr) > 0 {
+			msg := run.Stderr
+			if len(msg) > 100 {
+				msg = msg[:100] + "..."
+			}
+			verdict += ": " + msg
+		}
+		return models.SubStatusRE, verdict
+	}
+	return "", ""
+}
+
+// MaxReportOutput bounds each text field stored in a test case report
+const MaxReportOutput = 1024
+
+func truncate(s string) string {
+	if len(s) <= MaxReportOutput {
+		return s
+	}
+	return s[:MaxReportOutput] + "\n...(truncated)"
+}
+
+// Diff describes the first line where actual output differs from the expected output
+func Diff(expected, actual string) string {
+	exp := strings.Split(Normalize(expected), "\n")
+	act := strings.Split(Normalize(actual), "\n")
+	for i := 0; i < len(exp) || i < len(act); i++ {
+		var e, a string
+		if i < len(exp) {
+			e = exp[i]
+		}
+		if i < len(act) {
+			a = act[i]
+		}
+		switch {
+		case i >= len(act):
+			return truncate(fmt.Sprintf("Line %d: expected %q, got end of output", i+1, e))
+		case i >= len(exp):
+			return truncate(fmt.Sprintf("Line %d: expected end of output, got %q", i+1, a))
+		case e != a:
+			return truncate(fmt.Sprintf("Line %d: expected %q, got %q", i+1, e, a))
+		}
+	}
+	return "" // Only differs in ways the checker cares about (e.g. precision)
+}
+
+// PublicPracticeCases returns PracticeProblem.TestCases without its hidden cases, for
+// showing the problem to users. Malformed input yields no cases rather than leaking.
+func PublicPracticeCases(raw string) string {
+	if strings.TrimSpace(raw) == "" {
+		return raw
+	}
+	var cases []json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &cases); err != nil {
+		return "[]"
+	}
+	visible := make([]json.RawMessage, 0, len(cases))
+	for _, c := range cases {
+		var flags struct {
+			IsHidden bool `json:"isHidden"`
+		}
+		if json.Unmarshal(c, &flags) == nil && !flags.IsHidden {
+			visible = append(visible, c)
+		}
+	}
+	out, _ := json.Marshal(visible)
+	return string(out)
+}
+
+// Redact strips the contents of hidden test cases for viewers who aren't staff,
+// keeping status, runtime and memory
+func Redact(cases []models.TestCaseResult) []models.TestCaseResult {
+	redacted := make([]models.TestCaseResult, len(cases))
+	for i, tc := range cases {
+		if tc.Hidden {
+			tc.Input, tc.Expected, tc.Stdout, tc.Stderr, tc.Diff, tc.Message = "", "", "", "", "", ""
+		}
+		redacted[i] = tc
+	}
+	return redacted
+}
+
+// Normalize trims surrounding whitespace, unifies line endings and drops trailing
+// spaces on each line so formatting noise doesn't cause Wrong Answer
+func Normalize(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Compare reports whether actual output matches the expected output after normalization
+func Compare(actual, expected string) bool {
+	return Normalize(actual) == Normalize(expected)
+}
+
+// ContestCases converts a contest problem's test cases
+func ContestCases(tcs []models.TestCase) []Case {
+	cases := make([]Case, len(tcs))
+	for i, tc := range tcs {
+		cases[i] = Case{Input: tc.Input, Expected: tc.Output, Hidden: tc.IsHidden, Group: tc.Group}
+	}
+	return cases
+}
+
+// PracticeCases parses PracticeProblem.TestCases, a JSON array of {input, expected}.
+// "output" and "isHidden" are accepted as well so contest-style exports can be pasted in.
+func PracticeCases(raw string) ([]Case, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var parsed []struct {
+		Input    string `json:"input"`
+		Expected string `json:"expected"`
+		Output   string `json:"output"`
+		IsHidden bool   `json:"isHidden"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid test cases: %w", err)
+	}
+	cases := make([]Case, len(parsed))
+	for i, p := range parsed {
+		expected := p.Expected
+		if expected == "" {
+			expected = p.Output
+		}
+		cases[i] = Case{Input: p.Input, Expected: expected, Hidden: p.IsHidden}
+	}
+	return cases, nil
+}
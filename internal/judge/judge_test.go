@@ -0,0 +1,272 @@
+package judge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun_AllCasesFedAsStdin(t *testing.T) {
+	// The fake echoes stdin, so the second case fails
+	exec := services.NewFakeExecutor()
+	cases := []Case{
+		{Input: "1 2\n", Expected: "1 2"},
+		{Input: "3 4\n", Expected: "7"},
+		{Input: "5 6\n", Expected: "5 6"},
+	}
+
+	result, err := Run(exec, "python", "print(input()) # all-cases", cases, Options{TimeLimit: 1, MemoryLimit: 128})
+	assert.NoError(t, err)
+	assert.Equal(t, models.SubStatusWA, result.Status)
+	assert.Equal(t, "Wrong Answer on test 2", result.Verdict)
+	assert.Equal(t, 1, result.Passed)
+	assert.Equal(t, 3, result.Total)
+	if assert.Len(t, result.Cases, 2) {
+		assert.Equal(t, models.SubStatusAC, result.Cases[0].Status)
+		assert.Equal(t, `Line 1: expected "7", got "3 4"`, result.Cases[1].Diff)
+	}
+}
+
+func TestRun_SubstringIsNotAccepted(t *testing.T) {
+	exec := services.NewFakeExecutor()
+	exec.Handle = func(req services.ExecutionRequest) (*services.ExecutionResult, error) {
+		return &services.ExecutionResult{Run: services.StageResult{Stdout: "answer: 42\n"}}, nil
+	}
+
+	result, err := Run(exec, "python", "print('answer: 42') # substring", []Case{{Expected: "42"}}, Options{TimeLimit: 1, MemoryLimit: 128})
+	assert.NoError(t, err)
+	assert.Equal(t, models.SubStatusWA, result.Status)
+	assert.Equal(t, 0, result.Passed)
+}
+
+func TestRun_InfraErrorIsReturned(t *testing.T) {
+	exec := services.NewFakeExecutor()
+	exec.Handle = func(req services.ExecutionRequest) (*services.ExecutionResult, error) {
+		return nil, services.ErrExecutorUnavailable
+	}
+
+	_, err := Run(exec, "python", "print(1) # infra", []Case{{Expected: "1"}}, Options{TimeLimit: 1, MemoryLimit: 128})
+	assert.ErrorIs(t, err, services.ErrExecutorUnavailable)
+}
+
+func TestRun_RejectedRequestIsAVerdict(t *testing.T) {
+	exec := services.NewFakeExecutor()
+	exec.Handle = func(req services.ExecutionRequest) (*services.ExecutionResult, error) {
+		return nil, fmt.Errorf("%w: rust-9.9.9 runtime is unknown", services.ErrExecutionRejected)
+	}
+
+	result, err := Run(exec, "rust", "fn main() {} // rejected", []Case{{Expected: "1"}}, Options{TimeLimit: 1, MemoryLimit: 128})
+	assert.NoError(t, err, "retrying a rejected request can't help")
+	assert.Equal(t, models.SubStatusRE, result.Status)
+	assert.Contains(t, result.Verdict, "runtime is unknown")
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		res  services.ExecutionResult
+		want models.SubmissionStatus
+	}{
+		{"ok", services.ExecutionResult{}, ""},
+		{"compile", services.ExecutionResult{Compile: &services.StageResult{Code: 1}}, models.SubStatusCE},
+		{"sigkill", services.ExecutionResult{Run: services.StageResult{Signal: "SIGKILL"}}, models.SubStatusTLE},
+		{"exit137", services.ExecutionResult{Run: services.StageResult{Code: 137}}, models.SubStatusTLE},
+		{"segv", services.ExecutionResult{Run: services.StageResult{Signal: "SIGSEGV"}}, models.SubStatusRE},
+		{"exit1", services.ExecutionResult{Run: services.StageResult{Code: 1, Stderr: strings.Repeat("x", 200)}}, models.SubStatusRE},
+		{"under memory", services.ExecutionResult{Run: services.StageResult{Memory: 10 << 20}}, ""},
+		{"peak over limit", services.ExecutionResult{Run: services.StageResult{Memory: 64 << 20, Signal: "SIGKILL"}}, models.SubStatusMLE},
+		{"bad_alloc", services.ExecutionResult{Run: services.StageResult{Code: 134, Stderr: "terminate called after throwing an instance of 'std::bad_alloc'"}}, models.SubStatusMLE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := Classify(&tt.res, 64)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPracticeCases(t *testing.T) {
+	cases, err := PracticeCases(`[{"input":"1","expected":"2"},{"input":"3","output":"4","isHidden":true}]`)
+	assert.NoError(t, err)
+	assert.Equal(t, []Case{{Input: "1", Expected: "2"}, {Input: "3", Expected: "4", Hidden: true}}, cases)
+
+	_, err = PracticeCases("not json")
+	assert.Error(t, err)
+}
+
+func TestPublicPracticeCases(t *testing.T) {
+	raw := `[{"input":"1","expected":"2"},{"input":"3","output":"4","isHidden":true}]`
+	assert.JSONEq(t, `[{"input":"1","expected":"2"}]`, PublicPracticeCases(raw))
+	assert.Equal(t, "[]", PublicPracticeCases("not json"))
+	assert.Equal(t, "", PublicPracticeCases(""))
+}
+
+func TestRedact(t *testing.T) {
+	cases := []models.TestCaseResult{
+		{Index: 1, Status: models.SubStatusAC, Input: "1", Stdout: "1"},
+		{Index: 2, Hidden: true, Status: models.SubStatusWA, Runtime: 12, Input: "2", Expected: "3", Stdout: "4", Diff: "x"},
+	}
+	redacted := Redact(cases)
+	assert.Equal(t, cases[0], redacted[0])
+	assert.Equal(t, models.TestCaseResult{Index: 2, Hidden: true, Status: models.SubStatusWA, Runtime: 12}, redacted[1])
+	assert.Equal(t, "4", cases[1].Stdout, "original must not be modified")
+}
+
+func TestRun_RecordsPeakMemory(t *testing.T) {
+	exec := services.NewFakeExecutor()
+	exec.Handle = func(req services.ExecutionRequest) (*services.ExecutionResult, error) {
+		assert.Equal(t, 256<<20, req.MemoryLimit, "limit is forwarded in bytes")
+		mem := 2 << 20
+		if req.Stdin == "big" {
+			mem = 8 << 20
+		}
+		return &services.ExecutionResult{Run: services.StageResult{Stdout: req.Stdin, Memory: mem}}, nil
+	}
+
+	cases := []Case{{Input: "small", Expected: "small"}, {Input: "big", Expected: "big"}}
+	result, err := Run(exec, "python", "print(input()) # memory", cases, Options{MemoryLimit: 256, NoCache: true})
+	assert.NoError(t, err)
+	assert.Equal(t, models.SubStatusAC, result.Status)
+	assert.Equal(t, 8192, result.Memory)
+	assert.Equal(t, 2048, result.Cases[0].Memory)
+}
+
+// compilingExecutor builds once per Compile call and echoes stdin on every run
+type compilingExecutor struct {
+	*services.FakeExecutor
+	compiles int
+	compile  services.StageResult
+}
+
+type echoProgram struct{ compile *services.StageResult }
+
+func (p *echoProgram) Compile() *services.StageResult { return p.compile }
+func (p *echoProgram) Close() error                   { return nil }
+func (p *echoProgram) Run(ctx context.Context, stdin string) (*services.ExecutionResult, error) {
+	return &services.ExecutionResult{Compile: p.compile, Run: services.StageResult{Stdout: stdin, WallTime: 3}}, nil
+}
+
+func (e *compilingExecutor) Compile(ctx context.Context, req services.ExecutionRequest) (services.CompiledProgram, error) {
+	e.compiles++
+	compile := e.compile
+	return &echoProgram{compile: &compile}, nil
+}
+
+func TestRun_CompilesOncePerSubmission(t *testing.T) {
+	exec := &compilingExecutor{FakeExecutor: services.NewFakeExecutor(), compile: services.StageResult{WallTime: 250}}
+	cases := []Case{{Input: "1", Expected: "1"}, {Input: "2", Expected: "2"}, {Input: "3", Expected: "3"}}
+
+	result, err := Run(exec, "c++", "int main() {} // once", cases, Options{NoCache: true})
+	assert.NoError(t, err)
+	assert.Equal(t, models.SubStatusAC, result.Status)
+	assert.Equal(t, 1, exec.compiles)
+	assert.Equal(t, 250.0, result.CompileTime)
+	assert.Equal(t, 9.0, result.Runtime, "runtime excludes compilation")
+}
+
+func TestRun_CompilationError(t *testing.T) {
+	exec := &compilingExecutor{
+		FakeExecutor: services.NewFakeExecutor(),
+		compile:      services.StageResult{Code: 1, Stderr: "main.cpp:1:1: error: expected ';'"},
+	}
+
+	result, err := Run(exec, "c++", "int main() { // broken", []Case{{Input: "1", Expected: "1"}}, Options{NoCache: true})
+	assert.NoError(t, err)
+	assert.Equal(t, models.SubStatusCE, result.Status)
+	assert.Equal(t, "Compilation Error", result.Verdict)
+	assert.Contains(t, result.CompileOutput, "expected ';'")
+	assert.Empty(t, result.Cases)
+}
+
+func TestRun_CompilationErrorFromPerRunBackend(t *testing.T) {
+	// Piston-style backends report the compile stage with the first run
+	exec := services.NewFakeExecutor()
+	exec.Handle = func(req services.ExecutionRequest) (*services.ExecutionResult, error) {
+		return &services.ExecutionResult{Compile: &services.StageResult{Code: 1, Stdout: "Main.java:3: error"}}, nil
+	}
+
+	cases := []Case{{Input: "1", Expected: "1"}, {Input: "2", Expected: "2"}}
+	result, err := Run(exec, "java", "class Main { // broken", cases, Options{NoCache: true})
+	assert.NoError(t, err)
+	assert.Equal(t, models.SubStatusCE, result.Status)
+	assert.Equal(t, "Main.java:3: error", result.CompileOutput)
+	assert.Len(t, exec.Requests(), 1)
+}
+
+// batchingExecutor echoes stdin and reports at most two runs per batch
+type batchingExecutor struct {
+	*services.FakeExecutor
+	batches [][]string
+}
+
+func (e *batchingExecutor) CanBatch(req services.ExecutionRequest) bool { return true }
+func (e *batchingExecutor) ExecuteBatch(ctx context.Context, req services.ExecutionRequest, stdins []string) (*services.ExecutionResult, []services.StageResult, error) {
+	e.batches = append(e.batches, stdins)
+	var runs []services.StageResult
+	for _, stdin := range stdins[:min(2, len(stdins))] {
+		runs = append(runs, services.StageResult{Stdout: stdin, WallTime: 4})
+	}
+	return &services.ExecutionResult{Compile: &services.StageResult{}}, runs, nil
+}
+
+func TestRun_BatchesCases(t *testing.T) {
+	exec := &batchingExecutor{FakeExecutor: services.NewFakeExecutor()}
+	cases := []Case{
+		{Input: "a", Expected: "a"},
+		{Input: "b", Expected: "b"},
+		{Input: "c", Expected: "x"},
+		{Input: "d", Expected: "d"},
+	}
+
+	result, err := Run(exec, "c++", "int main() {} // batched", cases, Options{NoCache: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "Wrong Answer on test 3", result.Verdict)
+	assert.Equal(t, 12.0, result.Runtime, "runtime is the harness's wall time per run")
+	assert.Empty(t, exec.Requests())
+	assert.Equal(t, [][]string{{"a", "b", "c", "d"}, {"c", "d"}}, exec.batches, "unreported cases go in the next batch")
+}
+
+func TestRun_PartialSkipsFailedGroups(t *testing.T) {
+	// The fake echoes stdin: group 1 fails on its first case, group 2 passes
+	exec := services.NewFakeExecutor()
+	cases := []Case{
+		{Input: "a", Expected: "x", Group: 1},
+		{Input: "b", Expected: "b", Group: 1},
+		{Input: "c", Expected: "c", Group: 2},
+		{Input: "d", Expected: "d", Group: 2},
+	}
+	groups := []models.TestGroup{{Group: 1, Points: 30}, {Group: 2, Points: 70}}
+
+	result, err := Run(exec, "python", "print(input()) # partial", cases, Options{TimeLimit: 1, Partial: true, NoCache: true})
+	assert.NoError(t, err)
+	assert.Equal(t, models.SubStatusWA, result.Status)
+	assert.Equal(t, "Wrong Answer on test 1", result.Verdict)
+	assert.Equal(t, 2, result.Passed)
+	assert.Len(t, result.Cases, 3, "the rest of group 1 is skipped")
+	assert.Len(t, exec.Requests(), 3)
+	assert.Equal(t, 70, Score(cases, result, groups, 100))
+
+	// Without groups the points are split per case
+	assert.Equal(t, 50, Score(cases, result, nil, 100))
+}
+
+func TestScore(t *testing.T) {
+	cases := []Case{{Group: 1}, {Group: 2}}
+	groups := []models.TestGroup{{Group: 1, Points: 40}, {Group: 2, Points: 60}}
+
+	accepted := &Result{Status: models.SubStatusAC, Cases: []models.TestCaseResult{
+		{Index: 1, Status: models.SubStatusAC}, {Index: 2, Status: models.SubStatusAC},
+	}}
+	assert.Equal(t, 100, Score(cases, accepted, groups, 100))
+	assert.Equal(t, 100, Score(cases, accepted, nil, 100))
+
+	assert.Equal(t, 0, Score(cases, &Result{Status: models.SubStatusCE}, groups, 100))
+
+	// A group with no test cases can't be earned
+	assert.Equal(t, 40, Score(cases[:1], &Result{Status: models.SubStatusAC, Cases: accepted.Cases[:1]}, groups, 100))
+}
@@ -103,6 +103,9 @@ const (
 	// Admin Dashboard (Additional)
 	SettingDockBadges  = "dock_badges"  // JSON: { "/path": "BADGE_TEXT" }
 	SettingCustomAuras = "custom_auras" // JSON: [{ id, name, gradient, pulse, minXP }]
+
+	// Gamification
+	SettingXPLevelCurve = "xp_level_curve" // JSON: { "base": 1000, "growth": 1.0 }
 )
 
 // AdminAuditLog extends AdminAction with IP tracking (used for detailed audit)
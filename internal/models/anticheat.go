@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// AntiCheatCondition is the signal an anti-cheat rule measures on a submission
+type AntiCheatCondition string
+
+const (
+	ConditionDuplicateHash AntiCheatCondition = "DUPLICATE_HASH"       // Other users' submissions with the same normalized code
+	ConditionPastedRatio   AntiCheatCondition = "PASTED_RATIO"         // Pasted chars / code length, 0..1 (code over 50 chars only)
+	ConditionBlurCount     AntiCheatCondition = "BLUR_COUNT"           // Times the editor lost focus
+	ConditionSharedIP      AntiCheatCondition = "SHARED_IP"            // Other participants submitting from the same IP
+	ConditionSharedDevice  AntiCheatCondition = "SHARED_IP_USER_AGENT" // Other participants with the same IP and User-Agent
+	ConditionFastAccept    AntiCheatCondition = "FAST_ACCEPT"          // Seconds from the participant's start to a first-try AC (judged after the verdict)
+)
+
+// AntiCheatConditions lists every condition the engine can measure
+var AntiCheatConditions = []AntiCheatCondition{
+	ConditionDuplicateHash, ConditionPastedRatio, ConditionBlurCount,
+	ConditionSharedIP, ConditionSharedDevice, ConditionFastAccept,
+}
+
+// AntiCheatOperator compares a measured value with a rule's threshold
+type AntiCheatOperator string
+
+const (
+	OperatorGT  AntiCheatOperator = "GT"
+	OperatorGTE AntiCheatOperator = "GTE"
+	OperatorLT  AntiCheatOperator = "LT"
+	OperatorLTE AntiCheatOperator = "LTE"
+)
+
+// AntiCheatRule flags a submission whose measured condition passes the threshold and
+// deducts TrustPenalty from its author. Dry-run rules are evaluated and recorded but
+// never flag or penalize. Events can override Enabled and DryRun per rule.
+type AntiCheatRule struct {
+	ID           string             `gorm:"primaryKey;type:text" json:"id"`
+	Name         string             `json:"name"`
+	Condition    AntiCheatCondition `gorm:"index" json:"condition"`
+	Operator     AntiCheatOperator  `gorm:"default:GT" json:"operator"`
+	Threshold    float64            `json:"threshold"`
+	FlagType     SubmissionFlagType `json:"flagType"`
+	TrustPenalty int                `json:"trustPenalty"`
+	Enabled      bool               `json:"enabled"`
+	DryRun       bool               `gorm:"default:false" json:"dryRun"`
+	CreatedAt    time.Time          `json:"createdAt"`
+	UpdatedAt    time.Time          `json:"updatedAt"`
+}
+
+// Matches reports whether value passes the rule's threshold
+func (r *AntiCheatRule) Matches(value float64) bool {
+	switch r.Operator {
+	case OperatorGTE:
+		return value >= r.Threshold
+	case OperatorLT:
+		return value < r.Threshold
+	case OperatorLTE:
+		return value <= r.Threshold
+	default:
+		return value > r.Threshold
+	}
+}
+
+// EventAntiCheatRule overrides a rule's Enabled and DryRun for one event
+type EventAntiCheatRule struct {
+	EventID   string    `gorm:"primaryKey;type:text" json:"eventId"`
+	RuleID    string    `gorm:"primaryKey;type:text" json:"ruleId"`
+	Enabled   bool      `json:"enabled"`
+	DryRun    bool      `json:"dryRun"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// AntiCheatResult is one rule's evaluation of a submission, kept in its metrics so
+// moderators can see why it was (or wasn't) flagged
+type AntiCheatResult struct {
+	RuleID       string             `json:"ruleId"`
+	Name         string             `json:"name"`
+	Condition    AntiCheatCondition `json:"condition"`
+	Operator     AntiCheatOperator  `json:"operator"`
+	Threshold    float64            `json:"threshold"`
+	Value        float64            `json:"value"`
+	Triggered    bool               `json:"triggered"`
+	DryRun       bool               `json:"dryRun"`
+	FlagType     SubmissionFlagType `json:"flagType,omitempty"`
+	TrustPenalty int                `json:"trustPenalty"` // Deducted; 0 unless triggered outside dry run
+}
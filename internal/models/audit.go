@@ -5,20 +5,39 @@ import "time"
 type ActionType string
 
 const (
-	ActionStartContest   ActionType = "START_CONTEST"
-	ActionFreezeContest  ActionType = "FREEZE_CONTEST"
-	ActionEndContest     ActionType = "END_CONTEST"
-	ActionWarnSubmission ActionType = "WARN_SUBMISSION"
-	ActionDisqualifySub  ActionType = "DISQUALIFY_SUBMISSION"
-	ActionBanUser        ActionType = "BAN_USER"
-	ActionIgnoreFlag     ActionType = "IGNORE_FLAG"
-	ActionWarnUser       ActionType = "WARN_USER"
+	ActionStartContest    ActionType = "START_CONTEST"
+	ActionFreezeContest   ActionType = "FREEZE_CONTEST"
+	ActionEndContest      ActionType = "END_CONTEST"
+	ActionFinalizeContest ActionType = "FINALIZE_CONTEST"
+	ActionResolveContest  ActionType = "RESOLVE_CONTEST"
+	ActionUnfreezeContest ActionType = "UNFREEZE_CONTEST"
+	ActionRerankContest   ActionType = "RERANK_CONTEST"
+	ActionApplyRatings    ActionType = "APPLY_RATINGS"
+	ActionCheckPlagiarism ActionType = "CHECK_PLAGIARISM"
+	ActionCreateRule      ActionType = "CREATE_ANTICHEAT_RULE"
+	ActionUpdateRule      ActionType = "UPDATE_ANTICHEAT_RULE"
+	ActionDeleteRule      ActionType = "DELETE_ANTICHEAT_RULE"
+	ActionWarnSubmission  ActionType = "WARN_SUBMISSION"
+	ActionDisqualifySub   ActionType = "DISQUALIFY_SUBMISSION"
+	ActionBanUser         ActionType = "BAN_USER"
+	ActionIgnoreFlag      ActionType = "IGNORE_FLAG"
+	ActionWarnUser        ActionType = "WARN_USER"
 	// v1.2: New admin actions
-	ActionPinSnippet    ActionType = "PIN_SNIPPET"
-	ActionAdjustTrust   ActionType = "ADJUST_TRUST"
-	ActionCreateContest ActionType = "CREATE_CONTEST"
-	ActionUpdateContest ActionType = "UPDATE_CONTEST"
-	ActionDeleteContest ActionType = "DELETE_CONTEST"
+	ActionPinSnippet         ActionType = "PIN_SNIPPET"
+	ActionAdjustTrust        ActionType = "ADJUST_TRUST"
+	ActionReverseXP          ActionType = "REVERSE_XP"
+	ActionCreateStoreItem    ActionType = "CREATE_STORE_ITEM"
+	ActionUpdateStoreItem    ActionType = "UPDATE_STORE_ITEM"
+	ActionDeleteStoreItem    ActionType = "DELETE_STORE_ITEM"
+	ActionCreateBadge        ActionType = "CREATE_BADGE"
+	ActionUpdateBadge        ActionType = "UPDATE_BADGE"
+	ActionDeleteBadge        ActionType = "DELETE_BADGE"
+	ActionPlanDailyProblem   ActionType = "PLAN_DAILY_PROBLEM"
+	ActionUnplanDailyProblem ActionType = "UNPLAN_DAILY_PROBLEM"
+	ActionAutoFillDaily      ActionType = "AUTOFILL_DAILY_PROBLEMS"
+	ActionCreateContest      ActionType = "CREATE_CONTEST"
+	ActionUpdateContest      ActionType = "UPDATE_CONTEST"
+	ActionDeleteContest      ActionType = "DELETE_CONTEST"
 
 	ActionCreateProblem   ActionType = "CREATE_PROBLEM"
 	ActionUpdateProblem   ActionType = "UPDATE_PROBLEM"
@@ -31,6 +50,10 @@ const (
 	ActionDeleteSnippet     ActionType = "DELETE_SNIPPET"
 	ActionManageSystem      ActionType = "MANAGE_SYSTEM"
 	ActionManageModeration  ActionType = "MANAGE_MODERATION"
+
+	ActionCreateLanguage ActionType = "CREATE_LANGUAGE"
+	ActionUpdateLanguage ActionType = "UPDATE_LANGUAGE"
+	ActionDeleteLanguage ActionType = "DELETE_LANGUAGE"
 )
 
 type AdminAction struct {
@@ -14,6 +14,44 @@ const (
 	BadgeType3D BadgeType = "TROPHY"
 )
 
+// BadgeMetric is what a badge rule measures. Each user's value is kept up to date from
+// domain events (UserMetric), so evaluating a badge never recounts.
+type BadgeMetric string
+
+const (
+	MetricSnippetsCreated BadgeMetric = "SNIPPETS_CREATED" // Snippets authored, drafts included
+	MetricPracticeSolved  BadgeMetric = "PRACTICE_SOLVED"  // Distinct practice problems accepted
+	MetricFeedbackGiven   BadgeMetric = "FEEDBACK_GIVEN"   // Feedback wall posts
+	MetricContestsJoined  BadgeMetric = "CONTESTS_JOINED"  // Contest registrations
+	MetricSolveStreak     BadgeMetric = "SOLVE_STREAK"     // Consecutive days (UTC) with an accepted submission
+	MetricContestRank     BadgeMetric = "CONTEST_RANK"     // Best rank in a rated contest (lower is better)
+	MetricJoinRank        BadgeMetric = "JOIN_RANK"        // Position in sign-up order (lower is better)
+)
+
+// BadgeMetrics lists every metric the badge engine tracks
+var BadgeMetrics = []BadgeMetric{
+	MetricSnippetsCreated, MetricPracticeSolved, MetricFeedbackGiven, MetricContestsJoined,
+	MetricSolveStreak, MetricContestRank, MetricJoinRank,
+}
+
+// Ranked reports whether the metric keeps the best (lowest) value seen rather than a count
+func (m BadgeMetric) Ranked() bool {
+	return m == MetricContestRank || m == MetricJoinRank
+}
+
+// Windowable reports whether a badge on the metric can be limited to recent events
+func (m BadgeMetric) Windowable() bool {
+	return m != MetricSolveStreak && m != MetricJoinRank
+}
+
+// BadgeComparator compares a user's metric value with a badge's threshold
+type BadgeComparator string
+
+const (
+	ComparatorGTE BadgeComparator = "GTE" // At least Threshold ("5 snippets")
+	ComparatorLTE BadgeComparator = "LTE" // At most Threshold ("top 3")
+)
+
 type Badge struct {
 	ID          string        `gorm:"primaryKey;type:text" json:"id"`
 	Name        string        `json:"name"`
@@ -21,17 +59,51 @@ type Badge struct {
 	Icon        string        `json:"icon"` // Name of the Lucide icon
 	Category    BadgeCategory `gorm:"type:text" json:"category"`
 	Type        BadgeType     `gorm:"type:text;default:'BADGE'" json:"type"`
-	Condition   string        `json:"condition"` // e.g. "5_snippets"
+	Condition   string        `json:"condition"` // Legacy key, e.g. "5_snippets"; mapped to Metric on boot
 	Threshold   int           `json:"threshold"`
 	ModelPath   string        `json:"modelPath"` // Path to 3D model if Type is TROPHY
+
+	// Rule: the user's Metric value compared with Threshold, over the last WindowDays
+	// days of events when set (0 = all time). Badges without a metric are never awarded.
+	Metric     BadgeMetric     `gorm:"type:text;index" json:"metric"`
+	Comparator BadgeComparator `gorm:"type:text;default:'GTE'" json:"comparator"`
+	WindowDays int             `json:"windowDays"`
+}
+
+// Satisfied reports whether a metric value earns the badge
+func (b *Badge) Satisfied(value int) bool {
+	if b.Comparator == ComparatorLTE {
+		return value <= b.Threshold
+	}
+	return value >= b.Threshold
 }
 
+// UserBadge is a user's progress towards a badge; UnlockedAt is set once it is earned
+// and progress stops moving
 type UserBadge struct {
-	UserID     string    `gorm:"primaryKey;type:text" json:"userId"`
-	BadgeID    string    `gorm:"primaryKey;type:text" json:"badgeId"`
-	Progress   int       `gorm:"default:0" json:"progress"`
-	UnlockedAt time.Time `json:"unlockedAt"`
+	UserID     string     `gorm:"primaryKey;type:text" json:"userId"`
+	BadgeID    string     `gorm:"primaryKey;type:text" json:"badgeId"`
+	Progress   int        `gorm:"default:0" json:"progress"`
+	UnlockedAt *time.Time `json:"unlockedAt,omitempty"`
 
 	Badge Badge `gorm:"foreignKey:BadgeID" json:"badge"`
 	User  User  `gorm:"foreignKey:UserID" json:"-"`
 }
+
+// UserMetric is a user's running value of one badge metric
+type UserMetric struct {
+	UserID    string      `gorm:"primaryKey;type:text" json:"userId"`
+	Metric    BadgeMetric `gorm:"primaryKey;type:text" json:"metric"`
+	Value     int         `json:"value"`
+	LastAt    time.Time   `json:"lastAt"` // Last event; streaks compare its day
+	UpdatedAt time.Time   `json:"updatedAt"`
+}
+
+// BadgeEvent is one domain event fed to the badge engine, kept for windowed badges
+type BadgeEvent struct {
+	ID         string      `gorm:"primaryKey;type:text" json:"id"`
+	UserID     string      `gorm:"index:idx_badge_events_user_metric" json:"userId"`
+	Metric     BadgeMetric `gorm:"type:text;index:idx_badge_events_user_metric" json:"metric"`
+	Value      int         `json:"value"` // Delta for counters, rank for ranked metrics
+	OccurredAt time.Time   `gorm:"index:idx_badge_events_user_metric" json:"occurredAt"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// CheckerType selects how a program's output is compared with the expected output
+type CheckerType string
+
+const (
+	CheckerExact   CheckerType = "EXACT"   // Normalized string equality (default)
+	CheckerToken   CheckerType = "TOKEN"   // Whitespace-separated tokens must match
+	CheckerFloat   CheckerType = "FLOAT"   // Tokens match, numbers within CheckerEpsilon
+	CheckerProgram CheckerType = "PROGRAM" // A checker program decides (special judge)
+)
+
+// CheckerConfig is embedded in Problem and PracticeProblem.
+// For PROGRAM the checker receives {"input", "expected", "actual"} as JSON on stdin
+// and exits 0 to accept or 1 to reject; its first stdout line is used as the verdict message.
+type CheckerConfig struct {
+	CheckerType     CheckerType `gorm:"type:text;default:'EXACT'" json:"checkerType"`
+	CheckerEpsilon  float64     `json:"checkerEpsilon"`
+	CheckerLanguage string      `json:"checkerLanguage"`
+	CheckerCode     string      `gorm:"type:text" json:"-"` // Admin endpoints expose it explicitly
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// ContestResult is one row of a contest's final standings. Rows are written when the
+// contest is finalized and only replaced by an explicit admin re-rank, so later
+// moderation never silently rewrites history.
+type ContestResult struct {
+	ID      string  `gorm:"primaryKey;type:text" json:"id"`
+	EventID string  `gorm:"uniqueIndex:idx_contest_result_user;index" json:"eventId"`
+	UserID  string  `gorm:"uniqueIndex:idx_contest_result_user" json:"userId"`
+	TeamID  *string `gorm:"index" json:"teamId,omitempty"` // Team events: members share their team's row
+
+	Rank        int     `json:"rank"`
+	Score       int     `json:"score"`
+	SolvedCount int     `json:"solvedCount"`
+	Penalty     float64 `json:"penalty"` // Total time in minutes, including penalty
+	Status      string  `json:"status"`  // NORMAL, UNDER_REVIEW
+
+	Problems map[string]ProblemResult `gorm:"type:text;serializer:json" json:"problems"` // ProblemID -> cell
+
+	// Incremented by every re-rank; all rows of an event share it
+	Version int `gorm:"default:1" json:"version"`
+
+	User  User  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Team  *Team `gorm:"foreignKey:TeamID" json:"team,omitempty"`
+	Event Event `gorm:"foreignKey:EventID" json:"-"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ProblemResult is one scoreboard cell of a ContestResult
+type ProblemResult struct {
+	Status    string  `json:"status"`
+	Attempts  int     `json:"attempts"`
+	Score     int     `json:"score"`
+	TimeTaken float64 `json:"timeTaken"` // Minutes, including penalty
+	Penalty   int     `json:"penalty"`
+}
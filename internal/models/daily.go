@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// DailyProblemSource tells how a calendar day got its problem
+type DailyProblemSource string
+
+const (
+	DailySourcePlanned DailyProblemSource = "PLANNED" // Chosen by an admin
+	DailySourceAuto    DailyProblemSource = "AUTO"    // Filled in from rarely solved problems
+)
+
+// StreakFreezeItemID is the store item that buys one streak freeze
+const StreakFreezeItemID = "streak-freeze"
+
+// MaxStreakFreezes is how many unused freezes a user can hold
+const MaxStreakFreezes = 2
+
+// DailyProblem is one day of the daily-problem calendar. Date is the UTC day, YYYY-MM-DD.
+type DailyProblem struct {
+	Date      string             `gorm:"primaryKey;type:text" json:"date"`
+	ProblemID string             `gorm:"index" json:"problemId"`
+	Source    DailyProblemSource `gorm:"type:text" json:"source"`
+	CreatedBy string             `json:"createdBy,omitempty"`
+	CreatedAt time.Time          `json:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt"`
+
+	Problem *PracticeProblem `gorm:"foreignKey:ProblemID" json:"problem,omitempty"`
+}
+
+// DailySolve is one day of a user's streak history: the day's problem solved that day,
+// or a missed day covered by a streak freeze
+type DailySolve struct {
+	UserID    string    `gorm:"primaryKey;type:text" json:"userId"`
+	Date      string    `gorm:"primaryKey;type:text" json:"date"`
+	ProblemID string    `json:"problemId,omitempty"`
+	Frozen    bool      `json:"frozen"`
+	SolvedAt  time.Time `json:"solvedAt"`
+}
+
+// DailyStreak is a user's daily-problem streak
+type DailyStreak struct {
+	UserID        string    `gorm:"primaryKey;type:text" json:"-"`
+	Current       int       `json:"current"`
+	Longest       int       `json:"longest"`
+	LastDate      string    `gorm:"type:text" json:"lastDate,omitempty"` // Last day solved or frozen
+	Freezes       int       `json:"freezes"`                             // Unused
+	FreezesBought int       `json:"-"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+
+	SolvedToday bool `gorm:"-" json:"solvedToday"`
+}
@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type EventStatus string
@@ -14,6 +16,20 @@ const (
 	EventStatusEnded    EventStatus = "ENDED"
 )
 
+// ScoringMode selects how submissions turn into contest standings
+type ScoringMode string
+
+const (
+	ScoringICPC       ScoringMode = "ICPC"       // Solved count, then penalty time
+	ScoringIOI        ScoringMode = "IOI"        // Best partial score per problem, by test group
+	ScoringCodeforces ScoringMode = "CODEFORCES" // Problem points decay with time and wrong attempts
+)
+
+// Valid reports whether m is a known scoring mode
+func (m ScoringMode) Valid() bool {
+	return m == ScoringICPC || m == ScoringIOI || m == ScoringCodeforces
+}
+
 type Event struct {
 	ID          string `gorm:"primaryKey;type:text" json:"id"`
 	Title       string `json:"title"`
@@ -31,6 +47,31 @@ type Event struct {
 	Price  float64     `json:"price"` // 0 for free
 	Status EventStatus `gorm:"type:text;default:'UPCOMING'" json:"status"`
 
+	ScoringMode ScoringMode `gorm:"type:text;default:'ICPC'" json:"scoringMode"`
+
+	// Set once post-contest jobs (final standings, NO_SHOW marking) have run
+	FinalizedAt *time.Time `json:"finalizedAt"`
+
+	// Scoreboard resolver: reveal steps already shown on the public frozen board,
+	// and when the board was fully unfrozen
+	ResolverStep int        `gorm:"default:0" json:"resolverStep"`
+	UnfrozenAt   *time.Time `json:"unfrozenAt"`
+
+	// Rated contests update participants' ratings once results are final. The
+	// inclusive range (nil = open) limits who may register.
+	Rated     bool       `gorm:"default:false" json:"rated"`
+	RatingMin *int       `json:"ratingMin"`
+	RatingMax *int       `json:"ratingMax"`
+	RatedAt   *time.Time `json:"ratedAt"` // When rating changes were applied
+
+	// Team contests: registration is per team of TeamSizeMin..TeamSizeMax members.
+	// TeamSizeMax 0 means an individual contest.
+	TeamSizeMin int `gorm:"default:0" json:"teamSizeMin"`
+	TeamSizeMax int `gorm:"default:0" json:"teamSizeMax"`
+
+	// Language registry IDs accepted in this contest; empty allows every enabled language
+	AllowedLanguages pq.StringArray `gorm:"type:text[]" json:"allowedLanguages"`
+
 	// --- External Contest Fields ---
 	IsExternal            bool      `gorm:"column:isExternal;default:false" json:"isExternal"`
 	ExternalPlatform      string    `gorm:"column:externalPlatform" json:"externalPlatform"` // HACKERRANK, CODEFORCES, CUSTOM
@@ -46,6 +87,35 @@ type Event struct {
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// IsTeamEvent reports whether participants register and are ranked as teams
+func (e *Event) IsTeamEvent() bool {
+	return e.TeamSizeMax > 0
+}
+
+// RatingEligible reports whether a user with the given rating may register
+func (e *Event) RatingEligible(rating int) bool {
+	if e.RatingMin != nil && rating < *e.RatingMin {
+		return false
+	}
+	if e.RatingMax != nil && rating > *e.RatingMax {
+		return false
+	}
+	return true
+}
+
+// AllowsLanguage reports whether a canonical language ID may be used in this event
+func (e *Event) AllowsLanguage(languageID string) bool {
+	if len(e.AllowedLanguages) == 0 {
+		return true
+	}
+	for _, allowed := range e.AllowedLanguages {
+		if allowed == languageID {
+			return true
+		}
+	}
+	return false
+}
+
 type Problem struct {
 	ID          string `gorm:"primaryKey;type:text" json:"id"`
 	EventID     string `json:"eventId"`
@@ -62,6 +132,11 @@ type Problem struct {
 	// Content
 	StarterCode string     `json:"starterCode"` // JSON map[lang]code or just string
 	TestCases   []TestCase `gorm:"foreignKey:ProblemID" json:"testCases,omitempty"`
+	CheckerConfig
+
+	// IOI subtasks: a group's points are awarded only if all of its test cases pass.
+	// Empty = points are split evenly across test cases.
+	TestGroups []TestGroup `gorm:"type:text;serializer:json" json:"testGroups,omitempty"`
 
 	Order int `json:"order"`
 }
@@ -71,7 +146,14 @@ type TestCase struct {
 	ProblemID string `json:"problemId"`
 	Input     string `json:"input"`
 	Output    string `json:"output"`
-	IsHidden  bool   `json:"isHidden"` // Public vs Private test cases
+	IsHidden  bool   `json:"isHidden"`                       // Public vs Private test cases
+	Group     int    `gorm:"column:test_group" json:"group"` // IOI test group, 0 = not part of any group
+}
+
+// TestGroup is an IOI subtask worth Points
+type TestGroup struct {
+	Group  int `json:"group"`
+	Points int `json:"points"`
 }
 
 type RegistrationStatus string
@@ -81,6 +163,7 @@ const (
 	RegStatusPaid    RegistrationStatus = "PAID"
 	RegStatusJoined  RegistrationStatus = "JOINED"
 	RegStatusNoShow  RegistrationStatus = "NO_SHOW"
+	RegStatusBanned  RegistrationStatus = "BANNED" // Disqualified from the contest
 )
 
 type Registration struct {
@@ -91,6 +174,8 @@ type Registration struct {
 	Status    RegistrationStatus `gorm:"type:text" json:"status"`
 	PaymentID string             `json:"paymentId"` // Razorpay Payment ID or Order ID reference
 
+	TeamID *string `gorm:"index" json:"teamId,omitempty"` // Team events: every member has a row with the same team
+
 	RulesAccepted   bool      `json:"rulesAccepted"`
 	RulesAcceptedAt time.Time `json:"rulesAcceptedAt"`
 
@@ -113,14 +198,21 @@ const (
 	SubStatusTLE     SubmissionStatus = "TIME_LIMIT_EXCEEDED"
 	SubStatusRE      SubmissionStatus = "RUNTIME_ERROR"
 	SubStatusCE      SubmissionStatus = "COMPILATION_ERROR"
+	SubStatusMLE     SubmissionStatus = "MEMORY_LIMIT_EXCEEDED"
 	SubStatusPending SubmissionStatus = "PENDING"
 )
 
+// JudgeErrorVerdict prefixes the verdict of a submission the judge gave up on; its
+// RUNTIME_ERROR status is the judge's fault, not the participant's
+const JudgeErrorVerdict = "Judge Error: "
+
 type Submission struct {
-	ID        string `gorm:"primaryKey;type:text" json:"id"`
-	UserID    string `json:"userId"`
-	EventID   string `json:"eventId"`
-	ProblemID string `json:"problemId"`
+	ID        string  `gorm:"primaryKey;type:text" json:"id"`
+	UserID    string  `json:"userId"`
+	EventID   string  `json:"eventId"`
+	ProblemID string  `json:"problemId"`
+	TeamID    *string `gorm:"index" json:"teamId,omitempty"`    // Team events: the submitter's team
+	VirtualID *string `gorm:"index" json:"virtualId,omitempty"` // Set when made during a VirtualParticipation
 
 	Code     string `json:"code"`
 	Language string `json:"language"`
@@ -128,13 +220,18 @@ type Submission struct {
 	Status  SubmissionStatus `gorm:"type:text" json:"status"`
 	Verdict string           `json:"verdict"` // Detailed message
 
-	Runtime float64 `json:"runtime"` // ms
-	Memory  int     `json:"memory"`  // KB/MB
+	Runtime     float64 `json:"runtime"`     // ms, run time only
+	Memory      int     `json:"memory"`      // Peak KB across test cases
+	CompileTime float64 `json:"compileTime"` // ms, compiled languages only
+
+	CompileOutput string `gorm:"type:text" json:"compileOutput,omitempty"` // Compiler diagnostics on COMPILATION_ERROR
 
 	TestCasesPassed int `json:"testCasesPassed"`
 	TotalTestCases  int `json:"totalTestCases"`
+	Score           int `json:"score"` // Points earned in IOI mode (full points on AC otherwise)
 
-	OutputSnapshot string `gorm:"type:text" json:"outputSnapshot"` // Full execution result
+	OutputSnapshot string           `gorm:"type:text" json:"outputSnapshot"`                        // Full execution result
+	CaseResults    []TestCaseResult `gorm:"type:text;serializer:json" json:"caseResults,omitempty"` // Per-test-case report
 
 	// Anti-Cheat
 	CodeHash string `gorm:"type:text;index" json:"-"` // SHA-256 hash for similarity detection
@@ -142,6 +239,7 @@ type Submission struct {
 	CreatedAt time.Time `json:"createdAt"`
 
 	User    User              `gorm:"foreignKey:UserID" json:"-"`
+	Team    *Team             `gorm:"foreignKey:TeamID" json:"-"`
 	Problem Problem           `gorm:"foreignKey:ProblemID" json:"-"`
 	Flags   []SubmissionFlag  `gorm:"foreignKey:SubmissionID" json:"flags,omitempty"`
 	Metrics SubmissionMetrics `gorm:"foreignKey:SubmissionID" json:"metrics,omitempty"`
@@ -181,4 +279,7 @@ type SubmissionMetrics struct {
 	LineCount     int `json:"lineCount"`
 	FunctionCount int `json:"functionCount"`
 	LoopCount     int `json:"loopCount"`
+
+	// Anti-cheat rule evaluations, at submit time and after the verdict
+	RuleResults []AntiCheatResult `gorm:"type:text;serializer:json" json:"ruleResults"`
 }
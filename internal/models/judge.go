@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+type JudgeJobKind string
+
+const (
+	JudgeJobContest  JudgeJobKind = "CONTEST"  // models.Submission
+	JudgeJobPractice JudgeJobKind = "PRACTICE" // models.PracticeSubmission
+)
+
+type JudgeJobStatus string
+
+const (
+	JudgeJobQueued  JudgeJobStatus = "QUEUED"
+	JudgeJobRunning JudgeJobStatus = "RUNNING"
+	JudgeJobDone    JudgeJobStatus = "DONE"
+	JudgeJobFailed  JudgeJobStatus = "FAILED" // Gave up after max attempts
+)
+
+// JudgeJob is a durable work item for the judge queue.
+// Rows survive restarts so a crash mid-contest never leaves a submission PENDING forever.
+type JudgeJob struct {
+	ID           string         `gorm:"primaryKey;type:text" json:"id"`
+	Kind         JudgeJobKind   `gorm:"type:text;index" json:"kind"`
+	SubmissionID string         `gorm:"index" json:"submissionId"`
+	Status       JudgeJobStatus `gorm:"type:text;index" json:"status"`
+	Attempts     int            `gorm:"default:0" json:"attempts"`
+	LastError    string         `gorm:"type:text" json:"lastError"`
+	RunAfter     time.Time      `gorm:"index" json:"runAfter"` // Retry backoff
+	LockedBy     string         `json:"lockedBy"`              // Worker host holding the job
+	LockedAt     *time.Time     `json:"lockedAt"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TestCaseResult is the stored verdict for one executed test case.
+// Outputs are truncated by the judge; hidden-case contents are redacted for non-staff.
+type TestCaseResult struct {
+	Index    int              `json:"index"` // 1-based position in the problem's test cases
+	Hidden   bool             `json:"hidden"`
+	Group    int              `json:"group,omitempty"` // IOI test group
+	Status   SubmissionStatus `json:"status"`
+	Runtime  float64          `json:"runtime"` // ms
+	Memory   int              `json:"memory"`  // KB, 0 if the backend doesn't report it
+	Input    string           `json:"input,omitempty"`
+	Expected string           `json:"expected,omitempty"`
+	Stdout   string           `json:"stdout,omitempty"`
+	Stderr   string           `json:"stderr,omitempty"`
+	Diff     string           `json:"diff,omitempty"`    // First mismatch, for Wrong Answer
+	Message  string           `json:"message,omitempty"` // Checker program message
+}
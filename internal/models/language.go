@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Language is one entry of the runtime registry. ID is the canonical name clients send
+// (e.g. "cpp"); Runtime is the name the execution backend knows it by (e.g. "c++").
+type Language struct {
+	ID          string         `gorm:"primaryKey;type:text" json:"id"`
+	DisplayName string         `json:"displayName"`
+	Runtime     string         `json:"runtime"`
+	Aliases     pq.StringArray `gorm:"type:text[]" json:"aliases"`
+	FileName    string         `json:"fileName"`
+	Version     string         `gorm:"default:'*'" json:"version"` // Pinned backend version, "*" = latest installed
+
+	// Defaults used when the caller does not set its own limits
+	TimeLimit   float64 `gorm:"default:5" json:"timeLimit"`   // seconds
+	MemoryLimit int     `gorm:"default:0" json:"memoryLimit"` // MB, 0 = backend default
+
+	// Source patterns rejected before execution (e.g. "import numpy")
+	BlockedImports pq.StringArray `gorm:"type:text[]" json:"blockedImports"`
+
+	ClientSide bool `gorm:"default:false" json:"clientSide"` // Rendered in the browser, never executed
+	Enabled    bool `json:"enabled"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
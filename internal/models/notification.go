@@ -19,6 +19,7 @@ const (
 	NotificationTypeFollow      NotificationType = "FOLLOW"
 	NotificationTypeFork        NotificationType = "FORK"
 	NotificationTypeAchievement NotificationType = "ACHIEVEMENT"
+	NotificationTypeTeamInvite  NotificationType = "TEAM_INVITE"
 )
 
 type Notification struct {
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// PlagiarismReport is the similarity between two participants' submissions to one
+// problem. Reports are replaced whenever the contest is checked again.
+type PlagiarismReport struct {
+	ID            string  `gorm:"primaryKey;type:text" json:"id"`
+	EventID       string  `gorm:"index" json:"eventId"`
+	ProblemID     string  `gorm:"index" json:"problemId"`
+	SubmissionAID string  `json:"submissionAId"`
+	SubmissionBID string  `json:"submissionBId"`
+	UserAID       string  `json:"userAId"`
+	UserBID       string  `json:"userBId"`
+	Similarity    float64 `gorm:"index" json:"similarity"` // 0..1
+	Flagged       bool    `json:"flagged"`                 // Above the threshold: both submissions got a flag
+
+	Matches []PlagiarismMatch `gorm:"type:text;serializer:json" json:"matches"`
+
+	UserA   User    `gorm:"foreignKey:UserAID" json:"userA,omitempty"`
+	UserB   User    `gorm:"foreignKey:UserBID" json:"userB,omitempty"`
+	Problem Problem `gorm:"foreignKey:ProblemID" json:"-"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// PlagiarismMatch is a pair of line ranges (1-based, inclusive) the two submissions share
+type PlagiarismMatch struct {
+	AStart int `json:"aStart"`
+	AEnd   int `json:"aEnd"`
+	BStart int `json:"bStart"`
+	BEnd   int `json:"bEnd"`
+}
@@ -30,6 +30,8 @@ type Playlist struct {
 	IsVerified        bool   `gorm:"default:false" json:"isVerified"`
 	AwardsEndorsement string `json:"awardsEndorsement"`
 	CompletionBonusXP int    `gorm:"default:0" json:"completionBonusXP"`
+
+	Progress *PlaylistProgressSummary `gorm:"-" json:"progress,omitempty"` // The viewer's, when signed in
 }
 
 type PlaylistSnippet struct {
@@ -39,6 +41,35 @@ type PlaylistSnippet struct {
 	Snippet     Snippet `gorm:"foreignKey:SnippetID" json:"snippet"`
 	Order       int     `json:"order"`
 	IsCompleted bool    `gorm:"-" json:"isCompleted"`
+
+	CompletedAt *time.Time `gorm:"-" json:"completedAt,omitempty"`
+}
+
+type PlaylistProgressSource string
+
+const (
+	ProgressSourceView   PlaylistProgressSource = "VIEW"
+	ProgressSourceRun    PlaylistProgressSource = "RUN"
+	ProgressSourceManual PlaylistProgressSource = "MANUAL"
+)
+
+// PlaylistProgress records that a user completed one item of a track. Items are keyed by
+// snippet so progress survives reordering and re-adding.
+type PlaylistProgress struct {
+	ID          string                 `gorm:"primaryKey;type:text" json:"id"`
+	UserID      string                 `gorm:"uniqueIndex:idx_playlist_progress_item" json:"userId"`
+	PlaylistID  string                 `gorm:"uniqueIndex:idx_playlist_progress_item;index" json:"playlistId"`
+	SnippetID   string                 `gorm:"uniqueIndex:idx_playlist_progress_item" json:"snippetId"`
+	Source      PlaylistProgressSource `json:"source"`
+	CompletedAt time.Time              `json:"completedAt"`
+}
+
+// PlaylistProgressSummary is how far a user is through a track
+type PlaylistProgressSummary struct {
+	Completed int  `json:"completed"`
+	Total     int  `json:"total"`
+	Percent   int  `json:"percent"` // 0..100, rounded down
+	Done      bool `json:"done"`    // Every item completed (empty tracks never are)
 }
 
 func (Playlist) TableName() string {
@@ -48,3 +79,7 @@ func (Playlist) TableName() string {
 func (PlaylistSnippet) TableName() string {
 	return "PlaylistSnippet"
 }
+
+func (PlaylistProgress) TableName() string {
+	return "PlaylistProgress"
+}
@@ -26,6 +26,7 @@ type PracticeProblem struct {
 	Language     string `json:"language"`                       // Default language
 	TimeLimit    int    `gorm:"default:2" json:"timeLimit"`     // Seconds
 	MemoryLimit  int    `gorm:"default:128" json:"memoryLimit"` // MB
+	CheckerConfig
 
 	// Metadata
 	IsDailyProblem bool `gorm:"default:false" json:"isDailyProblem"`
@@ -57,16 +58,22 @@ type PracticeSubmission struct {
 	Language string `json:"language"`
 
 	// Result
-	Status        string `gorm:"default:'PENDING'" json:"status"` // PENDING, RUNNING, ACCEPTED, WRONG_ANSWER, ERROR, TIMEOUT
+	Status        string `gorm:"default:'PENDING'" json:"status"` // PENDING, RUNNING, then a SubmissionStatus verdict, or ERROR if judging failed
 	Verdict       string `json:"verdict"`
 	ExecutionTime int    `json:"executionTime"` // ms
 	MemoryUsed    int    `json:"memoryUsed"`    // KB
+	CompileTime   int    `json:"compileTime"`   // ms
+	CompileOutput string `gorm:"type:text" json:"compileOutput,omitempty"`
 	Output        string `gorm:"type:text" json:"output"`
 	Error         string `gorm:"type:text" json:"error"`
 
 	// Test results
-	TestsPassed int `json:"testsPassed"`
-	TestsTotal  int `json:"testsTotal"`
+	TestsPassed int              `json:"testsPassed"`
+	TestsTotal  int              `json:"testsTotal"`
+	CaseResults []TestCaseResult `gorm:"type:text;serializer:json" json:"caseResults,omitempty"`
+
+	// Recommended next problem, picked when the submission is accepted
+	NextProblemID string `json:"nextProblemId,omitempty"`
 }
 
 func (PracticeSubmission) TableName() string {
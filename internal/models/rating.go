@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// RatingHistory is one user's rating change from one rated contest
+type RatingHistory struct {
+	ID      string `gorm:"primaryKey;type:text" json:"id"`
+	UserID  string `gorm:"uniqueIndex:idx_rating_history_user_event;index" json:"userId"`
+	EventID string `gorm:"uniqueIndex:idx_rating_history_user_event" json:"eventId"`
+
+	Rank      int `json:"rank"`
+	OldRating int `json:"oldRating"`
+	NewRating int `json:"newRating"`
+	Delta     int `json:"delta"`
+
+	User  User  `gorm:"foreignKey:UserID" json:"-"`
+	Event Event `gorm:"foreignKey:EventID" json:"event,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// SystemUserID is the actor recorded for automated actions (e.g. scheduled contest transitions)
+const SystemUserID = "system"
+
+// SchedulerLease elects a single replica to run a background loop.
+// The holder renews ExpiresAt on every tick; another replica takes over once it lapses.
+type SchedulerLease struct {
+	Name      string    `gorm:"primaryKey;type:text" json:"name"`
+	Holder    string    `json:"holder"` // hostname:pid
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// StoreCategory groups store items by what they unlock
+type StoreCategory string
+
+const (
+	StoreCategoryAura      StoreCategory = "AURA"
+	StoreCategoryTheme     StoreCategory = "THEME"
+	StoreCategoryComponent StoreCategory = "COMPONENT"
+	StoreCategoryPowerup   StoreCategory = "POWERUP"
+)
+
+// StoreItem is something users can unlock with XP. Its ID is what ends up in
+// User.PurchasedComponentIds and in EquippedAura / EquippedTheme.
+type StoreItem struct {
+	ID          string        `gorm:"primaryKey;type:text" json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Category    StoreCategory `gorm:"index" json:"category"`
+	Price       int           `json:"price"`    // XP
+	MinLevel    int           `json:"minLevel"` // 0 or 1: anyone
+
+	// Availability window; nil bounds are open
+	AvailableFrom  *time.Time `json:"availableFrom,omitempty"`
+	AvailableUntil *time.Time `json:"availableUntil,omitempty"`
+	Enabled        bool       `json:"enabled"` // Disabled items can't be bought; owners keep them
+
+	// Legacy items were backfilled from purchases made before the catalog existed; their
+	// category is only a guess until an admin sets it
+	Legacy bool `json:"legacy"`
+
+	Data map[string]interface{} `gorm:"type:text;serializer:json" json:"data,omitempty"` // Presentation: gradient, colors, preview...
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ForSale reports whether the item can be bought at t
+func (s *StoreItem) ForSale(t time.Time) bool {
+	if !s.Enabled {
+		return false
+	}
+	if s.AvailableFrom != nil && t.Before(*s.AvailableFrom) {
+		return false
+	}
+	if s.AvailableUntil != nil && !t.Before(*s.AvailableUntil) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+type TeamRole string
+
+const (
+	TeamRoleOwner  TeamRole = "OWNER"
+	TeamRoleMember TeamRole = "MEMBER"
+)
+
+// Team is a group of users that registers for team contests together
+type Team struct {
+	ID      string `gorm:"primaryKey;type:text" json:"id"`
+	Name    string `json:"name"`
+	OwnerID string `gorm:"index" json:"ownerId"`
+
+	Members []TeamMember `gorm:"foreignKey:TeamID" json:"members,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type TeamMember struct {
+	TeamID string   `gorm:"primaryKey;type:text" json:"teamId"`
+	UserID string   `gorm:"primaryKey;type:text;index" json:"userId"`
+	Role   TeamRole `gorm:"type:text;default:'MEMBER'" json:"role"`
+
+	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+type TeamInviteStatus string
+
+const (
+	TeamInvitePending  TeamInviteStatus = "PENDING"
+	TeamInviteAccepted TeamInviteStatus = "ACCEPTED"
+	TeamInviteDeclined TeamInviteStatus = "DECLINED"
+)
+
+// TeamInvite asks a user to join a team; membership starts when they accept
+type TeamInvite struct {
+	ID        string           `gorm:"primaryKey;type:text" json:"id"`
+	TeamID    string           `gorm:"index" json:"teamId"`
+	UserID    string           `gorm:"index" json:"userId"` // Invitee
+	InvitedBy string           `json:"invitedBy"`
+	Status    TeamInviteStatus `gorm:"type:text;default:'PENDING'" json:"status"`
+
+	Team Team `gorm:"foreignKey:TeamID" json:"team,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"time"
 
 	"github.com/lib/pq"
@@ -25,6 +26,57 @@ const (
 
 const XPPerLevel = 1000 // 1000 XP per level
 
+// LevelCurve sets the XP each level costs: Base for level 2, then Growth times the
+// previous level's cost. Growth 1 is the flat XPPerLevel curve.
+type LevelCurve struct {
+	Base   int     `json:"base"`
+	Growth float64 `json:"growth"`
+}
+
+var DefaultLevelCurve = LevelCurve{Base: XPPerLevel, Growth: 1}
+
+// Valid reports whether the curve can be used; steeper than 3x per level is rejected
+func (lc LevelCurve) Valid() bool {
+	return lc.Base > 0 && lc.Growth >= 1 && lc.Growth <= 3
+}
+
+// MinXP is the XP at which a level is reached
+func (lc LevelCurve) MinXP(level int) int {
+	if level <= 1 {
+		return 0
+	}
+	if lc.Growth == 1 {
+		return (level - 1) * lc.Base
+	}
+	total, cost := 0, float64(lc.Base)
+	for l := 2; l <= level; l++ {
+		total += int(math.Round(cost))
+		cost *= lc.Growth
+	}
+	return total
+}
+
+// LevelFor is the level a balance of xp reaches
+func (lc LevelCurve) LevelFor(xp int) int {
+	if xp <= 0 {
+		return 1
+	}
+	if lc.Growth == 1 {
+		return xp/lc.Base + 1
+	}
+	level, total, cost := 1, 0, float64(lc.Base)
+	for {
+		total += int(math.Round(cost))
+		if xp < total {
+			return level
+		}
+		level++
+		cost *= lc.Growth
+	}
+}
+
+const InitialRating = 1500 // Rating of a user before their first rated contest
+
 type User struct {
 	ID        string         `gorm:"primaryKey;type:text" json:"id"`
 	CreatedAt time.Time      `gorm:"column:createdAt" json:"createdAt"`
@@ -55,6 +107,11 @@ type User struct {
 	// Anti-Cheat (MVP)
 	TrustScore int `gorm:"default:100;index" json:"trustScore"`
 
+	// Contest Rating (Codeforces-style), updated after each rated contest
+	Rating        int `gorm:"default:1500;index" json:"rating"`
+	MaxRating     int `gorm:"default:1500" json:"maxRating"`
+	RatedContests int `gorm:"default:0" json:"ratedContests"` // 0 = unrated, Rating is the starting value
+
 	// Arrays (Postgres String Array)
 	SelectedPublicSnippetIds pq.StringArray `gorm:"type:text[]" json:"selectedPublicSnippetIds"` // GORM might need custom handling for arrays or simpler approach
 	PurchasedComponentIds    pq.StringArray `gorm:"type:text[]" json:"purchasedComponentIds"`
@@ -115,17 +172,21 @@ func (User) TableName() string {
 	return "User"
 }
 
-// SyncLevelXP ensures XP and Level are consistent.
+// SyncLevelXP ensures XP and Level are consistent on the given curve.
 // If mode is "XP", Level is updated based on XP.
-// If mode is "Level", XP is updated to the minimum XP for that level if current XP is lower.
-func (u *User) SyncLevelXP(mode string) {
+// If mode is "Level", XP is moved into that level's range if it falls outside it.
+func (u *User) SyncLevelXP(mode string, curve LevelCurve) {
 	switch mode {
 	case "XP":
-		u.Level = (u.XP / XPPerLevel) + 1
+		u.Level = curve.LevelFor(u.XP)
 	case "Level":
-		minXP := (u.Level - 1) * XPPerLevel
-		if u.XP < minXP {
+		if u.Level < 1 {
+			u.Level = 1
+		}
+		if minXP := curve.MinXP(u.Level); u.XP < minXP {
 			u.XP = minXP
+		} else if next := curve.MinXP(u.Level + 1); u.XP >= next {
+			u.XP = next - 1
 		}
 	}
 }
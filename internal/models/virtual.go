@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// VirtualParticipation is a user's re-run of an ended contest under timed conditions:
+// a personal clock as long as the original contest, started when they chose. Their
+// submissions are judged normally but tagged with it and ranked on a ghost leaderboard.
+type VirtualParticipation struct {
+	ID        string    `gorm:"primaryKey;type:text" json:"id"`
+	UserID    string    `gorm:"uniqueIndex:idx_virtual_user_event" json:"userId"`
+	EventID   string    `gorm:"uniqueIndex:idx_virtual_user_event;index" json:"eventId"`
+	StartedAt time.Time `json:"startedAt"`
+	EndsAt    time.Time `json:"endsAt"`
+
+	Event Event `gorm:"foreignKey:EventID" json:"-"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Active reports whether the personal clock is running at t
+func (v *VirtualParticipation) Active(t time.Time) bool {
+	return !t.Before(v.StartedAt) && t.Before(v.EndsAt)
+}
+
+// ContestTime maps a moment of the virtual run onto the original contest's clock
+func (v *VirtualParticipation) ContestTime(event *Event, t time.Time) time.Time {
+	return event.StartTime.Add(t.Sub(v.StartedAt))
+}
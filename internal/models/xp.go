@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+type XPSource string
+
+const (
+	XPSourceOpeningBalance XPSource = "OPENING_BALANCE" // XP earned before the ledger existed
+	XPSourceSnippet        XPSource = "SNIPPET"
+	XPSourceLink           XPSource = "USER_LINK"
+	XPSourcePlaylist       XPSource = "PLAYLIST_COMPLETION"
+	XPSourcePurchase       XPSource = "PURCHASE"
+	XPSourceAdmin          XPSource = "ADMIN"
+	XPSourceReversal       XPSource = "REVERSAL"
+)
+
+// XPLedgerEntry is one change to a user's XP. The ledger is append-only: mistakes are
+// undone by a reversing entry, and User.XP always equals the sum of a user's entries.
+type XPLedgerEntry struct {
+	ID            string   `gorm:"primaryKey;type:text" json:"id"`
+	UserID        string   `gorm:"index" json:"userId"`
+	Source        XPSource `gorm:"index" json:"source"`
+	Amount        int      `json:"amount"`       // Signed
+	BalanceAfter  int      `json:"balanceAfter"` // User.XP once applied
+	LevelAfter    int      `json:"levelAfter"`
+	ReferenceType string   `json:"referenceType,omitempty"` // "snippet", "playlist", "user", "store_item", "xp_entry"
+	ReferenceID   string   `gorm:"index" json:"referenceId,omitempty"`
+	Reason        string   `json:"reason,omitempty"`
+
+	// A repeated key returns the first entry instead of applying the change again
+	IdempotencyKey *string `gorm:"uniqueIndex" json:"-"`
+
+	ReversedByID *string `json:"reversedById,omitempty"` // Set on the original once reversed
+	CreatedBy    string  `json:"createdBy,omitempty"`    // Admin behind manual entries
+
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+}
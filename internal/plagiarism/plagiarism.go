@@ -0,0 +1,344 @@
+// Package plagiarism measures how similar two programs are, MOSS style: code is
+// tokenized with comments and whitespace dropped and identifiers, numbers and strings
+// renamed, token k-grams are hashed, and winnowing keeps a small set of fingerprints
+// that two programs must share for any common run of at least K+W-1 tokens.
+package plagiarism
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// K is the k-gram length in tokens
+	K = 8
+	// W is the winnowing window in k-grams
+	W = 4
+	// MinTokens is the size below which programs are too short to judge
+	MinTokens = 20
+)
+
+// keywords survive normalization; every other identifier becomes "V"
+var keywords = map[string]bool{}
+
+func init() {
+	for _, kw := range strings.Fields(`
+		if else for while do switch case default break continue return goto
+		func function def class struct interface enum type var let const static
+		public private protected void int long short char float double bool boolean
+		string auto unsigned signed new delete try catch finally throw throws raise except
+		import package include using namespace from as with yield lambda pass in is not and or
+		go defer chan select range map true false nil null None True False this self super
+		template typename final extends implements async await of typeof instanceof`) {
+		keywords[kw] = true
+	}
+}
+
+type token struct {
+	text string
+	line int
+}
+
+type fingerprint struct {
+	hash uint64
+	pos  int // Index of the k-gram's first token
+}
+
+// Document is a program prepared for comparison
+type Document struct {
+	ID     string
+	tokens []token
+	prints []fingerprint
+	set    map[uint64][]int // Fingerprint hash -> k-gram positions
+}
+
+// Match is a pair of line ranges (1-based, inclusive) found in both programs
+type Match struct {
+	AStart int `json:"aStart"`
+	AEnd   int `json:"aEnd"`
+	BStart int `json:"bStart"`
+	BEnd   int `json:"bEnd"`
+}
+
+// Pair is the similarity of two documents, by their indexes in the compared slice
+type Pair struct {
+	A, B       int
+	Similarity float64
+	Matches    []Match
+}
+
+// NewDocument normalizes and fingerprints code written in the given language
+func NewDocument(id, language, code string) *Document {
+	doc := &Document{ID: id, tokens: tokenize(stripComments(language, code)), set: map[uint64][]int{}}
+	doc.prints = winnow(doc.tokens)
+	for _, fp := range doc.prints {
+		doc.set[fp.hash] = append(doc.set[fp.hash], fp.pos)
+	}
+	return doc
+}
+
+// Exclude drops the fingerprints the document shares with base, code every participant
+// was handed (a starter template), so only what they wrote themselves is compared.
+// This is MOSS's base file.
+func (d *Document) Exclude(base *Document) {
+	if base == nil {
+		return
+	}
+	for hash := range base.set {
+		delete(d.set, hash)
+	}
+	kept := d.prints[:0]
+	for _, fp := range d.prints {
+		if _, ok := d.set[fp.hash]; ok {
+			kept = append(kept, fp)
+		}
+	}
+	d.prints = kept
+}
+
+// Comparable reports whether the program is long enough to be judged
+func (d *Document) Comparable() bool {
+	return len(d.tokens) >= MinTokens && len(d.set) > 0
+}
+
+// stripComments blanks out comments while keeping line numbers
+func stripComments(language, code string) string {
+	hashComments := language == "python" || language == "ruby" || language == "bash"
+	var out strings.Builder
+	runes := []rune(code)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '"' || r == '\'' || r == '`':
+			// Copy string literals verbatim so comment markers inside them survive
+			out.WriteRune(r)
+			for i++; i < len(runes); i++ {
+				out.WriteRune(runes[i])
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					out.WriteRune(runes[i])
+					continue
+				}
+				if runes[i] == r || (runes[i] == '\n' && r != '`') {
+					break
+				}
+			}
+		case hashComments && r == '#', !hashComments && r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			if i < len(runes) {
+				out.WriteRune('\n')
+			}
+		case !hashComments && r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			for i += 2; i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/'); i++ {
+				if runes[i] == '\n' {
+					out.WriteRune('\n')
+				}
+			}
+			i++ // Skip the closing '/'
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+// tokenize splits code into normalized tokens: keywords and punctuation as written,
+// identifiers as V, numbers as N and string literals as S
+func tokenize(code string) []token {
+	var tokens []token
+	runes := []rune(code)
+	line := 1
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '\n':
+			line++
+			i++
+		case unicode.IsSpace(r):
+			i++
+		case r == '_' || unicode.IsLetter(r):
+			j := i
+			for j < len(runes) && (runes[j] == '_' || unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+				j++
+			}
+			word := string(runes[i:j])
+			if !keywords[word] {
+				word = "V"
+			}
+			tokens = append(tokens, token{word, line})
+			i = j
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && (runes[j] == '.' || runes[j] == '_' || unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+				j++
+			}
+			tokens = append(tokens, token{"N", line})
+			i = j
+		case r == '"' || r == '\'' || r == '`':
+			start := line
+			j := i + 1
+			for j < len(runes) && runes[j] != r {
+				if runes[j] == '\\' {
+					j++
+				} else if runes[j] == '\n' {
+					line++
+					if r != '`' {
+						break
+					}
+				}
+				j++
+			}
+			tokens = append(tokens, token{"S", start})
+			i = j + 1
+		default:
+			tokens = append(tokens, token{string(r), line})
+			i++
+		}
+	}
+	return tokens
+}
+
+// winnow hashes every k-gram and keeps the minimum of each window of W hashes
+// (rightmost on ties), recording each selected k-gram once
+func winnow(tokens []token) []fingerprint {
+	if len(tokens) < K {
+		return nil
+	}
+	hashes := make([]uint64, len(tokens)-K+1)
+	for i := range hashes {
+		h := fnv.New64a()
+		for _, t := range tokens[i : i+K] {
+			h.Write([]byte(t.text))
+			h.Write([]byte{0})
+		}
+		hashes[i] = h.Sum64()
+	}
+
+	window := W
+	if len(hashes) < window {
+		window = len(hashes)
+	}
+	var prints []fingerprint
+	last := -1
+	for start := 0; start+window <= len(hashes); start++ {
+		best := start
+		for i := start; i < start+window; i++ {
+			if hashes[i] <= hashes[best] {
+				best = i
+			}
+		}
+		if best != last {
+			prints = append(prints, fingerprint{hashes[best], best})
+			last = best
+		}
+	}
+	return prints
+}
+
+// Compare returns the Dice similarity of two documents' fingerprint sets (0..1) and the
+// line ranges they share
+func Compare(a, b *Document) (float64, []Match) {
+	if len(a.set) == 0 || len(b.set) == 0 {
+		return 0, nil
+	}
+	shared := 0
+	var matches []Match
+	for hash, aPositions := range a.set {
+		bPositions, ok := b.set[hash]
+		if !ok {
+			continue
+		}
+		shared++
+		for _, ap := range aPositions {
+			for _, bp := range bPositions {
+				matches = append(matches, Match{
+					AStart: a.tokens[ap].line, AEnd: a.tokens[ap+K-1].line,
+					BStart: b.tokens[bp].line, BEnd: b.tokens[bp+K-1].line,
+				})
+			}
+		}
+	}
+	similarity := 2 * float64(shared) / float64(len(a.set)+len(b.set))
+	return similarity, mergeMatches(matches)
+}
+
+// mergeMatches joins overlapping or touching ranges that are adjacent on both sides
+func mergeMatches(matches []Match) []Match {
+	if len(matches) == 0 {
+		return []Match{}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].AStart != matches[j].AStart {
+			return matches[i].AStart < matches[j].AStart
+		}
+		return matches[i].BStart < matches[j].BStart
+	})
+
+	merged := []Match{matches[0]}
+	for _, m := range matches[1:] {
+		cur := &merged[len(merged)-1]
+		if m.AStart <= cur.AEnd+1 && m.BStart <= cur.BEnd+1 && m.BEnd >= cur.BStart-1 {
+			cur.AEnd = max(cur.AEnd, m.AEnd)
+			cur.BStart = min(cur.BStart, m.BStart)
+			cur.BEnd = max(cur.BEnd, m.BEnd)
+			continue
+		}
+		merged = append(merged, m)
+	}
+	return merged
+}
+
+// ComparePairs compares every pair of comparable documents that share a fingerprint and
+// returns those at least minSimilarity alike, most similar first. skip excludes pairs
+// that are expected to match (the same author or team). Fingerprints present in over
+// half of a large set are boilerplate and don't count as evidence.
+func ComparePairs(docs []*Document, minSimilarity float64, skip func(i, j int) bool) []Pair {
+	index := map[uint64][]int{}
+	for i, doc := range docs {
+		if !doc.Comparable() {
+			continue
+		}
+		for hash := range doc.set {
+			index[hash] = append(index[hash], i)
+		}
+	}
+
+	common := len(docs) / 2
+	candidates := map[[2]int]bool{}
+	for _, holders := range index {
+		if len(docs) >= 10 && len(holders) > common {
+			continue
+		}
+		for x := 0; x < len(holders); x++ {
+			for y := x + 1; y < len(holders); y++ {
+				candidates[[2]int{holders[x], holders[y]}] = true
+			}
+		}
+	}
+
+	pairs := []Pair{}
+	for key := range candidates {
+		i, j := key[0], key[1]
+		if skip != nil && skip(i, j) {
+			continue
+		}
+		similarity, matches := Compare(docs[i], docs[j])
+		if similarity >= minSimilarity {
+			pairs = append(pairs, Pair{A: i, B: j, Similarity: similarity, Matches: matches})
+		}
+	}
+	sort.Slice(pairs, func(x, y int) bool {
+		if pairs[x].Similarity != pairs[y].Similarity {
+			return pairs[x].Similarity > pairs[y].Similarity
+		}
+		if pairs[x].A != pairs[y].A {
+			return pairs[x].A < pairs[y].A
+		}
+		return pairs[x].B < pairs[y].B
+	})
+	return pairs
+}
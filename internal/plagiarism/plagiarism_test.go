@@ -0,0 +1,145 @@
+package plagiarism
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const original = `#include <bits/stdc++.h>
+using namespace std;
+
+int main() {
+    int n; cin >> n;
+    vector<long long> a(n);
+    for (int i = 0; i < n; i++) cin >> a[i];
+    long long best = a[0], cur = 0;
+    for (int i = 0; i < n; i++) {
+        cur = max(a[i], cur + a[i]);
+        best = max(best, cur);
+    }
+    cout << best << endl;
+}
+`
+
+// The same program with renamed variables, a different layout and comments
+const disguised = `#include <bits/stdc++.h>
+using namespace std;
+// Kadane's algorithm, written from scratch ;)
+int main() {
+    int count; cin >> count;
+    vector<long long> values(count);
+    for (int k = 0; k < count; k++) cin >> values[k];
+    /* running maximum */
+    long long answer = values[0], run = 0;
+    for (int k = 0; k < count; k++)
+    {
+        run = max(values[k], run + values[k]);
+        answer = max(answer, run);
+    }
+    cout << answer << endl;
+}
+`
+
+const unrelated = `#include <bits/stdc++.h>
+using namespace std;
+
+int main() {
+    string s; getline(cin, s);
+    map<char, int> freq;
+    for (char ch : s) if (isalpha(ch)) freq[tolower(ch)]++;
+    while (!freq.empty()) {
+        auto it = freq.begin();
+        printf("%c:%d\n", it->first, it->second);
+        freq.erase(it);
+    }
+    return 0;
+}
+`
+
+func TestCompare_SeesThroughRenamingAndComments(t *testing.T) {
+	a := NewDocument("a", "cpp", original)
+	b := NewDocument("b", "cpp", disguised)
+	c := NewDocument("c", "cpp", unrelated)
+	require.True(t, a.Comparable())
+
+	similarity, matches := Compare(a, b)
+	assert.Greater(t, similarity, 0.9)
+	covered := false
+	for _, m := range matches {
+		// The Kadane update sits on line 10 of one and line 12 of the other
+		covered = covered || (m.AStart <= 10 && m.AEnd >= 10 && m.BStart <= 12 && m.BEnd >= 12)
+	}
+	assert.True(t, covered, "%+v", matches)
+
+	similarity, _ = Compare(a, c)
+	assert.Less(t, similarity, 0.3)
+}
+
+func TestStripComments_KeepsLinesAndStrings(t *testing.T) {
+	code := "x = \"# not a comment\" # comment\ny = 1"
+	assert.Equal(t, "x = \"# not a comment\" \ny = 1", stripComments("python", code))
+
+	code = "a /* one\ntwo */ b // end\nc"
+	assert.Equal(t, "a \n b \nc", stripComments("cpp", code))
+}
+
+func TestComparePairs_SkipsExpectedPairs(t *testing.T) {
+	docs := []*Document{
+		NewDocument("a", "cpp", original),
+		NewDocument("b", "cpp", disguised),
+		NewDocument("c", "cpp", unrelated),
+		NewDocument("tiny", "cpp", "int main() {}"),
+	}
+
+	pairs := ComparePairs(docs, 0.5, nil)
+	require.Len(t, pairs, 1)
+	assert.Equal(t, 0, pairs[0].A)
+	assert.Equal(t, 1, pairs[0].B)
+
+	assert.Empty(t, ComparePairs(docs, 0.5, func(i, j int) bool { return true }))
+}
+
+// A fast I/O template both programs below were handed
+const template = `#include <bits/stdc++.h>
+using namespace std;
+static char buf[1 << 25];
+int bufLen = 0, bufPos = 0;
+inline int readChar() {
+    if (bufPos == bufLen) { bufLen = fread(buf, 1, sizeof(buf), stdin); bufPos = 0; }
+    return bufPos < bufLen ? buf[bufPos++] : -1;
+}
+inline long long readInt() {
+    int c = readChar(); while (c != '-' && (c < '0' || c > '9')) c = readChar();
+    bool neg = c == '-'; if (neg) c = readChar();
+    long long x = 0; while (c >= '0' && c <= '9') { x = x * 10 + (c - '0'); c = readChar(); }
+    return neg ? -x : x;
+}
+`
+
+func TestExclude_IgnoresStarterTemplate(t *testing.T) {
+	sum := template + `int main() {
+    long long n = readInt(), total = 0;
+    for (long long i = 0; i < n; i++) total += readInt();
+    printf("%lld\n", total);
+}
+`
+	maxPair := template + `int main() {
+    int n = readInt();
+    vector<long long> v(n);
+    for (auto &x : v) x = readInt();
+    sort(v.begin(), v.end());
+    cout << v[n - 1] * v[n - 2] << endl;
+}
+`
+	a, b := NewDocument("a", "cpp", sum), NewDocument("b", "cpp", maxPair)
+	withTemplate, _ := Compare(a, b)
+	assert.Greater(t, withTemplate, 0.6)
+
+	base := NewDocument("base", "cpp", template)
+	a.Exclude(base)
+	b.Exclude(base)
+	without, _ := Compare(a, b)
+	assert.Less(t, without, 0.3)
+}
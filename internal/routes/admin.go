@@ -34,6 +34,8 @@ func RegisterAdminRoutes(rg *gin.RouterGroup) {
 		users.POST("/:id/ban-contest", handlers.AdminBanContest)
 		users.POST("/:id/trust", handlers.AdminAdjustTrustScore)
 		users.POST("/:id/grant-xp", handlers.AdminGrantUserXP)
+		users.GET("/:id/xp", handlers.AdminGetUserXPHistory)
+		users.POST("/:id/xp/:entryId/reverse", handlers.AdminReverseXPEntry)
 		users.POST("/:id/message", handlers.AdminSendMessageToUser)
 		users.PUT("/:id", handlers.AdminUpdateUser)
 		users.DELETE("/:id", handlers.AdminDeleteUser)
@@ -60,6 +62,12 @@ func RegisterAdminRoutes(rg *gin.RouterGroup) {
 		contests.POST("/contests/:id/freeze", handlers.AdminFreezeContest)
 		contests.POST("/contests/:id/end", handlers.AdminEndContest)
 		contests.GET("/contests/:id/participants", handlers.AdminGetContestParticipants)
+		contests.GET("/contests/:id/resolver", handlers.AdminGetResolver)
+		contests.POST("/contests/:id/resolver/step", handlers.AdminResolverStep)
+		contests.POST("/contests/:id/resolver/finish", handlers.AdminResolverFinish)
+		contests.POST("/contests/:id/rerank", handlers.AdminRerankContest)
+		contests.GET("/contests/:id/ratings/preview", handlers.AdminPreviewRatings)
+		contests.POST("/contests/:id/ratings/apply", handlers.AdminApplyRatings)
 
 		// Problems
 		contests.GET("/problems/:id", handlers.AdminGetProblem)
@@ -79,6 +87,12 @@ func RegisterAdminRoutes(rg *gin.RouterGroup) {
 		contests.POST("/practice-problems", handlers.AdminCreatePracticeProblem)
 		contests.PUT("/practice-problems/:id", handlers.AdminUpdatePracticeProblem)
 		contests.DELETE("/practice-problems/:id", handlers.AdminDeletePracticeProblem)
+
+		// Daily Problem Calendar
+		contests.GET("/daily-problems", handlers.AdminListDailyProblems)
+		contests.POST("/daily-problems/autofill", handlers.AdminAutoFillDailyProblems)
+		contests.PUT("/daily-problems/:date", handlers.AdminPlanDailyProblem)
+		contests.DELETE("/daily-problems/:date", handlers.AdminUnplanDailyProblem)
 	}
 
 	// Flag Review & Submissions (Moderation)
@@ -91,6 +105,20 @@ func RegisterAdminRoutes(rg *gin.RouterGroup) {
 		moderation.POST("/flags/:id/disqualify-submission", handlers.AdminDisqualifySubmission)
 		moderation.POST("/flags/:id/disqualify-user", handlers.AdminDisqualifyUser)
 
+		// Plagiarism reports back the flag review
+		moderation.POST("/contests/:id/plagiarism", handlers.AdminCheckPlagiarism)
+		moderation.GET("/contests/:id/plagiarism", handlers.AdminListPlagiarismReports)
+		moderation.GET("/plagiarism/:id/diff", handlers.AdminGetPlagiarismDiff)
+
+		// Anti-cheat rules, globally and per contest
+		moderation.GET("/anticheat/rules", handlers.AdminListAntiCheatRules)
+		moderation.POST("/anticheat/rules", handlers.AdminCreateAntiCheatRule)
+		moderation.PUT("/anticheat/rules/:id", handlers.AdminUpdateAntiCheatRule)
+		moderation.DELETE("/anticheat/rules/:id", handlers.AdminDeleteAntiCheatRule)
+		moderation.GET("/contests/:id/anticheat", handlers.AdminGetEventAntiCheat)
+		moderation.PUT("/contests/:id/anticheat/:ruleId", handlers.AdminSetEventAntiCheat)
+		moderation.DELETE("/contests/:id/anticheat/:ruleId", handlers.AdminResetEventAntiCheat)
+
 		moderation.GET("/submissions", handlers.AdminListSubmissions)
 		moderation.GET("/submissions/:id", handlers.AdminGetSubmissionDetail)
 		moderation.POST("/submissions/:id/restore", handlers.AdminRestoreSubmission)
@@ -125,6 +153,24 @@ func RegisterAdminRoutes(rg *gin.RouterGroup) {
 		restricted.PUT("/system", handlers.AdminUpdateSystemSettings)
 		restricted.POST("/system/redeploy", handlers.AdminTriggerRedeploy)
 
+		// Language Registry
+		restricted.GET("/languages", handlers.AdminListLanguages)
+		restricted.POST("/languages", handlers.AdminCreateLanguage)
+		restricted.PUT("/languages/:id", handlers.AdminUpdateLanguage)
+		restricted.DELETE("/languages/:id", handlers.AdminDeleteLanguage)
+
+		// Badges
+		restricted.GET("/badges", handlers.AdminListBadges)
+		restricted.POST("/badges", handlers.AdminCreateBadge)
+		restricted.PUT("/badges/:id", handlers.AdminUpdateBadge)
+		restricted.DELETE("/badges/:id", handlers.AdminDeleteBadge)
+
+		// XP Store Catalog
+		restricted.GET("/store/items", handlers.AdminListStoreItems)
+		restricted.POST("/store/items", handlers.AdminCreateStoreItem)
+		restricted.PUT("/store/items/:id", handlers.AdminUpdateStoreItem)
+		restricted.DELETE("/store/items/:id", handlers.AdminDeleteStoreItem)
+
 		// Analytics (Full)
 		restricted.GET("/analytics/top-snippets", handlers.AdminGetTopSnippets)
 
@@ -12,14 +12,21 @@ func RegisterArenaRoutes(r gin.IRouter) {
 		// Public (Optional Auth for Registration Status)
 		arena.GET("", middleware.OptionalAuthMiddleware(), handlers.ListEvents)
 		arena.GET("/:id", middleware.OptionalAuthMiddleware(), handlers.GetEvent)
+		arena.GET("/:id/results", middleware.OptionalAuthMiddleware(), handlers.GetContestResults)
+		arena.GET("/:id/results/export", middleware.OptionalAuthMiddleware(), handlers.ExportContestResults)
+		arena.GET("/:id/rating-changes", middleware.OptionalAuthMiddleware(), handlers.GetContestRatingChanges)
 
 		// Protected
 		protected := arena.Group("/")
 		protected.Use(middleware.AuthMiddleware())
 		{
 			protected.POST("/:id/register", middleware.RequireContestsEnabled(), handlers.RegisterForEvent)
+			protected.POST("/:id/register-team", middleware.RequireContestsEnabled(), handlers.RegisterTeamForEvent)
 			protected.POST("/:id/rules", middleware.RequireContestsEnabled(), handlers.AcceptRules)
 			protected.POST("/:id/join-external", handlers.JoinExternalContest)
+			protected.POST("/:id/virtual", middleware.RequireContestsEnabled(), handlers.StartVirtualContest)
+			protected.GET("/:id/virtual", handlers.GetVirtualContest)
+			protected.GET("/:id/virtual/leaderboard", handlers.GetVirtualLeaderboard)
 			protected.GET("/:id/access", handlers.GetEventAccess) // THE GATEKEEPER
 
 			// Admin only
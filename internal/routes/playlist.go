@@ -22,6 +22,8 @@ func RegisterPlaylistRoutes(r *gin.RouterGroup) {
 			protected.POST("/:id/snippets", handlers.AddSnippetToPlaylist)
 			protected.DELETE("/:id/snippets/:snippetId", handlers.RemoveSnippetFromPlaylist)
 			protected.POST("/:id/reorder", handlers.ReorderPlaylist)
+			protected.POST("/:id/snippets/:snippetId/complete", handlers.MarkPlaylistItem)
+			protected.DELETE("/:id/snippets/:snippetId/complete", handlers.UnmarkPlaylistItem)
 			protected.POST("/:id/claim", handlers.ClaimEndorsement)
 		}
 	}
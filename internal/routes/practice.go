@@ -15,6 +15,7 @@ func RegisterPracticeRoutes(r gin.IRouter) {
 		practice.GET("/problems", middleware.OptionalAuthMiddleware(), handlers.ListPracticeProblems)
 		practice.GET("/problems/:id", middleware.OptionalAuthMiddleware(), handlers.GetPracticeProblem)
 		practice.GET("/daily", middleware.OptionalAuthMiddleware(), handlers.GetDailyProblem)
+		practice.GET("/daily/calendar", handlers.GetDailyCalendar)
 
 		// Protected: Submit solutions and view history
 		protected := practice.Group("")
@@ -23,6 +24,9 @@ func RegisterPracticeRoutes(r gin.IRouter) {
 			protected.POST("/run", handlers.RunPracticeSolution)
 			protected.POST("/submit", handlers.SubmitPracticeSolution)
 			protected.GET("/submissions", handlers.GetUserPracticeSubmissions)
+			protected.GET("/submissions/:id", handlers.GetPracticeSubmission)
+			protected.GET("/daily/streak", handlers.GetMyDailyStreak)
+			protected.GET("/recommendations", handlers.GetPracticeRecommendations)
 		}
 	}
 }
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pushp314/devconnect-backend/internal/handlers"
+	"github.com/pushp314/devconnect-backend/internal/middleware"
+	"github.com/pushp314/devconnect-backend/internal/models"
+)
+
+func RegisterStoreRoutes(r *gin.RouterGroup) {
+	store := r.Group("/store")
+	store.Use(middleware.FeatureGate(models.SettingFeatureSidebarXPStore, "XP Store"))
+	{
+		store.GET("/items", handlers.ListStoreItems)
+	}
+}
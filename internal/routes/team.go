@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pushp314/devconnect-backend/internal/handlers"
+	"github.com/pushp314/devconnect-backend/internal/middleware"
+)
+
+// RegisterTeamRoutes sets up team management for team contests
+func RegisterTeamRoutes(r gin.IRouter) {
+	teams := r.Group("/teams")
+	teams.Use(middleware.AuthMiddleware())
+	{
+		teams.POST("", handlers.CreateTeam)
+		teams.GET("/my", handlers.GetMyTeams)
+		teams.GET("/invites", handlers.GetMyTeamInvites)
+		teams.POST("/invites/:inviteId/accept", handlers.RespondToTeamInvite(true))
+		teams.POST("/invites/:inviteId/decline", handlers.RespondToTeamInvite(false))
+
+		teams.GET("/:id", handlers.GetTeam)
+		teams.POST("/:id/invites", handlers.InviteToTeam)
+		teams.POST("/:id/leave", handlers.LeaveTeam)
+	}
+}
@@ -29,9 +29,11 @@ func RegisterUserRoutes(r gin.IRouter) {
 		users.GET("/:username", handlers.GetProfile)
 		users.GET("/:username/snippets", handlers.GetUserSnippets)
 		users.GET("/:username/badges", handlers.GetBadges)
+		users.GET("/:username/rating-history", handlers.GetRatingHistory)
 
 		// History (Authenticated)
 		users.GET("/me/contests", middleware.AuthMiddleware(), handlers.GetMyContestHistory)
+		users.GET("/me/xp", middleware.AuthMiddleware(), handlers.GetMyXPHistory)
 
 		// Onboarding (Authenticated)
 		users.POST("/onboarding", middleware.AuthMiddleware(), handlers.CompleteOnboarding)
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// AntiCheatSignals are the values measured on one submission, by condition. Rules whose
+// condition wasn't measured are not evaluated.
+type AntiCheatSignals map[models.AntiCheatCondition]float64
+
+// DefaultAntiCheatRules are the heuristics SubmitSolution used to hard-code. The fast
+// accept rule starts in dry run so its threshold can be tuned on real contests first.
+func DefaultAntiCheatRules() []models.AntiCheatRule {
+	return []models.AntiCheatRule{
+		{ID: "duplicate-hash", Name: "Duplicate code hash", Condition: models.ConditionDuplicateHash, Operator: models.OperatorGT, Threshold: 0, FlagType: models.FlagTypeHash, TrustPenalty: 20, Enabled: true},
+		{ID: "excessive-paste", Name: "Mostly pasted code", Condition: models.ConditionPastedRatio, Operator: models.OperatorGT, Threshold: 0.5, FlagType: models.FlagTypePaste, TrustPenalty: 10, Enabled: true},
+		{ID: "focus-loss", Name: "Frequent focus loss", Condition: models.ConditionBlurCount, Operator: models.OperatorGT, Threshold: 10, FlagType: models.FlagTypeBlur, TrustPenalty: 15, Enabled: true},
+		{ID: "shared-ip", Name: "IP shared with other participants", Condition: models.ConditionSharedIP, Operator: models.OperatorGT, Threshold: 0, FlagType: models.FlagTypeSuspicious, TrustPenalty: 30, Enabled: true},
+		{ID: "shared-device", Name: "Same IP and User-Agent as other participants", Condition: models.ConditionSharedDevice, Operator: models.OperatorGT, Threshold: 0, FlagType: models.FlagTypeSuspicious, TrustPenalty: 25, Enabled: true},
+		{ID: "fast-accept", Name: "Accepted on the first try too fast", Condition: models.ConditionFastAccept, Operator: models.OperatorLT, Threshold: 60, FlagType: models.FlagTypeSuspicious, TrustPenalty: 10, Enabled: true, DryRun: true},
+	}
+}
+
+// SeedAntiCheatRules creates the default rules on first boot
+func SeedAntiCheatRules(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&models.AntiCheatRule{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	rules := DefaultAntiCheatRules()
+	now := time.Now()
+	for i := range rules {
+		rules[i].CreatedAt, rules[i].UpdatedAt = now, now
+	}
+	return db.Create(&rules).Error
+}
+
+// AntiCheatRulesForEvent returns the rules that apply to an event, with the event's
+// overrides of Enabled and DryRun applied. Disabled rules are left out.
+func AntiCheatRulesForEvent(db *gorm.DB, eventID string) ([]models.AntiCheatRule, error) {
+	var rules []models.AntiCheatRule
+	if err := db.Order("created_at asc, id asc").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	var overrides []models.EventAntiCheatRule
+	if err := db.Where("event_id = ?", eventID).Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+	byRule := make(map[string]models.EventAntiCheatRule, len(overrides))
+	for _, o := range overrides {
+		byRule[o.RuleID] = o
+	}
+
+	active := rules[:0]
+	for _, rule := range rules {
+		if o, ok := byRule[rule.ID]; ok {
+			rule.Enabled, rule.DryRun = o.Enabled, o.DryRun
+		}
+		if rule.Enabled {
+			active = append(active, rule)
+		}
+	}
+	return active, nil
+}
+
+// EvaluateAntiCheat runs each rule against the measured signals
+func EvaluateAntiCheat(rules []models.AntiCheatRule, signals AntiCheatSignals) []models.AntiCheatResult {
+	results := []models.AntiCheatResult{}
+	for _, rule := range rules {
+		value, ok := signals[rule.Condition]
+		if !ok {
+			continue
+		}
+		result := models.AntiCheatResult{
+			RuleID:    rule.ID,
+			Name:      rule.Name,
+			Condition: rule.Condition,
+			Operator:  rule.Operator,
+			Threshold: rule.Threshold,
+			Value:     value,
+			Triggered: rule.Matches(value),
+			DryRun:    rule.DryRun,
+			FlagType:  rule.FlagType,
+		}
+		if result.Triggered && !rule.DryRun {
+			result.TrustPenalty = rule.TrustPenalty
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// ApplyAntiCheat flags the submission once per triggered rule outside dry run and
+// deducts the rules' penalties from its author's trust score (floored at 0)
+func ApplyAntiCheat(db *gorm.DB, sub *models.Submission, results []models.AntiCheatResult) error {
+	penalty := 0
+	for _, r := range results {
+		if !r.Triggered || r.DryRun {
+			continue
+		}
+		flag := models.SubmissionFlag{
+			ID:           uuid.New().String(),
+			SubmissionID: sub.ID,
+			Type:         r.FlagType,
+			Details:      fmt.Sprintf("%s: %s = %g (%s %g)", r.Name, r.Condition, r.Value, r.Operator, r.Threshold),
+			CreatedAt:    time.Now(),
+		}
+		if err := db.Create(&flag).Error; err != nil {
+			return err
+		}
+		penalty += r.TrustPenalty
+	}
+	if penalty == 0 {
+		return nil
+	}
+
+	return db.Model(&models.User{}).Where("id = ?", sub.UserID).
+		Update("trust_score", gorm.Expr("CASE WHEN trust_score > ? THEN trust_score - ? ELSE 0 END", penalty, penalty)).Error
+}
+
+// RecordAntiCheat appends results to a submission's metrics, creating them if needed
+func RecordAntiCheat(db *gorm.DB, submissionID string, results []models.AntiCheatResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	var metrics models.SubmissionMetrics
+	if err := db.Where("submission_id = ?", submissionID).Attrs(models.SubmissionMetrics{SubmissionID: submissionID}).
+		FirstOrInit(&metrics).Error; err != nil {
+		return err
+	}
+	metrics.RuleResults = append(metrics.RuleResults, results...)
+	return db.Save(&metrics).Error
+}
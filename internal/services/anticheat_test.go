@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAntiCheatRules_EventOverridesAndDryRun(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, SeedAntiCheatRules(db))
+	require.NoError(t, SeedAntiCheatRules(db)) // Seeds once
+
+	var count int64
+	db.Model(&models.AntiCheatRule{}).Count(&count)
+	assert.EqualValues(t, len(DefaultAntiCheatRules()), count)
+
+	db.Create(&models.Event{ID: "ac", Slug: "ac", Status: models.EventStatusLive,
+		StartTime: time.Now().Add(-time.Hour), EndTime: time.Now().Add(time.Hour)})
+	db.Create(&models.User{ID: "mallory", Username: "mallory", Email: "mallory@example.com", TrustScore: 100})
+	sub := models.Submission{ID: "ac-1", UserID: "mallory", EventID: "ac", ProblemID: "p", CreatedAt: time.Now()}
+	db.Create(&sub)
+
+	// This contest doesn't judge shared IPs and only watches duplicate hashes
+	db.Create(&models.EventAntiCheatRule{EventID: "ac", RuleID: "shared-ip", Enabled: false})
+	db.Create(&models.EventAntiCheatRule{EventID: "ac", RuleID: "duplicate-hash", Enabled: true, DryRun: true})
+
+	rules, err := AntiCheatRulesForEvent(db, "ac")
+	require.NoError(t, err)
+	for _, rule := range rules {
+		assert.NotEqual(t, "shared-ip", rule.ID)
+	}
+
+	results := EvaluateAntiCheat(rules, AntiCheatSignals{
+		models.ConditionDuplicateHash: 2,
+		models.ConditionPastedRatio:   0.4,
+		models.ConditionBlurCount:     12,
+		models.ConditionSharedIP:      3,
+		models.ConditionSharedDevice:  0,
+	})
+	byRule := map[string]models.AntiCheatResult{}
+	for _, r := range results {
+		byRule[r.RuleID] = r
+	}
+	require.Len(t, byRule, 4) // fast-accept wasn't measured, shared-ip is off
+	assert.True(t, byRule["duplicate-hash"].Triggered)
+	assert.True(t, byRule["duplicate-hash"].DryRun)
+	assert.Zero(t, byRule["duplicate-hash"].TrustPenalty)
+	assert.False(t, byRule["excessive-paste"].Triggered)
+	assert.True(t, byRule["focus-loss"].Triggered)
+	assert.Equal(t, 15, byRule["focus-loss"].TrustPenalty)
+
+	require.NoError(t, RecordAntiCheat(db, sub.ID, results))
+	require.NoError(t, ApplyAntiCheat(db, &sub, results))
+
+	// Only the live rule flagged and penalized
+	var flags []models.SubmissionFlag
+	db.Where("submission_id = ?", sub.ID).Find(&flags)
+	require.Len(t, flags, 1)
+	assert.Equal(t, models.FlagTypeBlur, flags[0].Type)
+	var user models.User
+	db.First(&user, "id = ?", "mallory")
+	assert.Equal(t, 85, user.TrustScore)
+
+	// Verdict-time results are appended to the same metrics
+	fast := EvaluateAntiCheat(rules, AntiCheatSignals{models.ConditionFastAccept: 30})
+	require.Len(t, fast, 1)
+	assert.True(t, fast[0].Triggered)
+	assert.True(t, fast[0].DryRun)
+	require.NoError(t, RecordAntiCheat(db, sub.ID, fast))
+	var metrics models.SubmissionMetrics
+	require.NoError(t, db.First(&metrics, "submission_id = ?", sub.ID).Error)
+	assert.Len(t, metrics.RuleResults, 5)
+}
@@ -1,94 +1,307 @@
 package services
 
 import (
+	"errors"
 	"time"
 
-	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/google/uuid"
 	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// CheckBadges checks if the user has earned any new badges after an action
-func CheckBadges(userID string) ([]models.Badge, error) {
-	var newBadges []models.Badge
+// RecordBadgeEvent folds one domain event into the user's metric and re-evaluates only
+// the badges on that metric, returning the ones it unlocked. Counters take a delta,
+// ranked metrics a rank (the best is kept) and streaks ignore value and use at's day.
+func RecordBadgeEvent(db *gorm.DB, userID string, metric models.BadgeMetric, value int, at time.Time) ([]models.Badge, error) {
+	var unlocked []models.Badge
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var m models.UserMetric
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND metric = ?", userID, metric).First(&m).Error
+		fresh := errors.Is(err, gorm.ErrRecordNotFound)
+		if err != nil && !fresh {
+			return err
+		}
+		if fresh {
+			m = models.UserMetric{UserID: userID, Metric: metric}
+		}
 
-	// 1. Get user's current badges
-	var existingBadgeIDs []string
-	database.DB.Model(&models.UserBadge{}).Where("user_id = ?", userID).Pluck("badge_id", &existingBadgeIDs)
+		foldBadgeMetric(&m, fresh, value, at)
+		m.UpdatedAt = time.Now()
+		if err := tx.Save(&m).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.BadgeEvent{
+			ID:         uuid.New().String(),
+			UserID:     userID,
+			Metric:     metric,
+			Value:      value,
+			OccurredAt: at,
+		}).Error; err != nil {
+			return err
+		}
 
-	existingSet := make(map[string]bool)
-	for _, id := range existingBadgeIDs {
-		existingSet[id] = true
+		var badges []models.Badge
+		if err := tx.Where("metric = ?", metric).Find(&badges).Error; err != nil {
+			return err
+		}
+		unlocked, err = evaluateBadges(tx, userID, badges, map[models.BadgeMetric]models.UserMetric{metric: m})
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	return unlocked, nil
+}
 
-	// 2. Fetch Stats
-	var solvedCount int64
-	database.DB.Model(&models.Submission{}).Where("user_id = ? AND status = 'ACCEPTED'", userID).Count(&solvedCount)
+// foldBadgeMetric applies one event to a running metric value
+func foldBadgeMetric(m *models.UserMetric, fresh bool, value int, at time.Time) {
+	switch {
+	case m.Metric == models.MetricSolveStreak:
+		day, last := at.UTC().Truncate(24*time.Hour), m.LastAt.UTC().Truncate(24*time.Hour)
+		switch {
+		case fresh:
+			m.Value = 1
+		case day.Before(last) || day.Equal(last):
+			return // Same day, or an older event arriving late
+		case day.Equal(last.Add(24 * time.Hour)):
+			m.Value++
+		default:
+			m.Value = 1
+		}
+	case m.Metric.Ranked():
+		if !fresh && m.Value <= value {
+			return
+		}
+		m.Value = value
+	default:
+		m.Value += value
+		if m.Value < 0 {
+			m.Value = 0
+		}
+	}
+	m.LastAt = at
+}
 
-	var snippetCount int64
-	database.DB.Model(&models.Snippet{}).Where("\"authorId\" = ?", userID).Count(&snippetCount)
+// EvaluateBadges brings a user's progress on every badge up to date from their stored
+// metrics, e.g. after badges were added or edited, and returns the newly unlocked ones
+func EvaluateBadges(db *gorm.DB, userID string) ([]models.Badge, error) {
+	var metrics []models.UserMetric
+	if err := db.Where("user_id = ?", userID).Find(&metrics).Error; err != nil {
+		return nil, err
+	}
+	byMetric := make(map[models.BadgeMetric]models.UserMetric, len(metrics))
+	for _, m := range metrics {
+		byMetric[m.Metric] = m
+	}
 
-	var feedbackCount int64
-	database.DB.Model(&models.FeedbackMessage{}).Where("user_id = ?", userID).Count(&feedbackCount)
+	var badges []models.Badge
+	if err := db.Where("metric <> ''").Find(&badges).Error; err != nil {
+		return nil, err
+	}
+	var unlocked []models.Badge
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		unlocked, err = evaluateBadges(tx, userID, badges, byMetric)
+		return err
+	})
+	return unlocked, err
+}
 
-	var contestCount int64
-	database.DB.Model(&models.Registration{}).Where("user_id = ? AND status != 'BANNED'", userID).Count(&contestCount)
+// evaluateBadges updates the user's progress on the given badges and unlocks the ones
+// they now satisfy. Unlocked badges are never re-evaluated.
+func evaluateBadges(tx *gorm.DB, userID string, badges []models.Badge, metrics map[models.BadgeMetric]models.UserMetric) ([]models.Badge, error) {
+	if len(badges) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, len(badges))
+	for i, b := range badges {
+		ids[i] = b.ID
+	}
+	var existing []models.UserBadge
+	if err := tx.Where("user_id = ? AND badge_id IN ?", userID, ids).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	byBadge := make(map[string]models.UserBadge, len(existing))
+	for _, ub := range existing {
+		byBadge[ub.BadgeID] = ub
+	}
 
-	// Count Early Adopter qualification (First 1000 Users)
-	var rank int64
-	database.DB.Model(&models.User{}).
-		Where("\"createdAt\" <= (SELECT \"createdAt\" FROM \"User\" WHERE id = ?)", userID).
-		Count(&rank)
-
-	earlyAdopterStatus := int64(0)
-	if rank <= 1000 {
-		earlyAdopterStatus = 1
-	}
-
-	// 3. Define mapping of conditions to stats
-	stats := map[string]int64{
-		"1_snippet":          snippetCount,
-		"5_snippets":         snippetCount,
-		"25_snippets":        snippetCount,
-		"1_practice_solved":  solvedCount,
-		"5_practice_solved":  solvedCount,
-		"25_practice_solved": solvedCount,
-		"feedback_given":     feedbackCount,
-		"5_feedback":         feedbackCount,
-		"early_adopter":      earlyAdopterStatus,
-		"1_contest":          contestCount,
-		"5_contests":         contestCount,
-	}
-
-	// 4. Fetch all system badge definitions
-	var systemBadges []models.Badge
-	database.DB.Find(&systemBadges)
-
-	// 5. Evaluate each badge
-	for _, badge := range systemBadges {
-		// Skip if already owned
-		if existingSet[badge.ID] {
+	var unlocked []models.Badge
+	now := time.Now()
+	for _, badge := range badges {
+		ub, exists := byBadge[badge.ID]
+		if exists && ub.UnlockedAt != nil {
 			continue
 		}
+		progress, ok, err := badgeProgress(tx, userID, &badge, metrics)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		earned := badge.Satisfied(progress)
+		if exists && !earned && ub.Progress == progress {
+			continue
+		}
+
+		ub = models.UserBadge{UserID: userID, BadgeID: badge.ID, Progress: progress}
+		if earned {
+			ub.UnlockedAt = &now
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "badge_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"progress", "unlocked_at"}),
+		}).Create(&ub).Error; err != nil {
+			return nil, err
+		}
+		if earned {
+			unlocked = append(unlocked, badge)
+		}
+	}
+	return unlocked, nil
+}
+
+// badgeProgress is the user's value for a badge: the running metric, or the events in
+// the badge's window. ok is false while there is nothing to measure (e.g. no rank yet).
+func badgeProgress(tx *gorm.DB, userID string, badge *models.Badge, metrics map[models.BadgeMetric]models.UserMetric) (int, bool, error) {
+	if badge.WindowDays <= 0 || !badge.Metric.Windowable() {
+		m, ok := metrics[badge.Metric]
+		return m.Value, ok, nil
+	}
 
-		progress, ok := stats[badge.Condition]
+	since := time.Now().AddDate(0, 0, -badge.WindowDays)
+	var agg struct {
+		Events int64
+		Value  int
+	}
+	fn := "COALESCE(SUM(value), 0)"
+	if badge.Metric.Ranked() {
+		fn = "COALESCE(MIN(value), 0)"
+	}
+	if err := tx.Model(&models.BadgeEvent{}).Select("COUNT(*) AS events, "+fn+" AS value").
+		Where("user_id = ? AND metric = ? AND occurred_at >= ?", userID, badge.Metric, since).
+		Scan(&agg).Error; err != nil {
+		return 0, false, err
+	}
+	if badge.Metric.Ranked() && agg.Events == 0 {
+		return 0, false, nil
+	}
+	return max(agg.Value, 0), true, nil
+}
+
+// legacyBadgeRules maps the condition strings badges used before the rule engine
+var legacyBadgeRules = map[string]struct {
+	Metric     models.BadgeMetric
+	Comparator models.BadgeComparator
+	Threshold  int // Overrides the stored threshold when non-zero
+}{
+	"1_snippet":          {models.MetricSnippetsCreated, models.ComparatorGTE, 0},
+	"5_snippets":         {models.MetricSnippetsCreated, models.ComparatorGTE, 0},
+	"10_snippets":        {models.MetricSnippetsCreated, models.ComparatorGTE, 0},
+	"25_snippets":        {models.MetricSnippetsCreated, models.ComparatorGTE, 0},
+	"snippet_master":     {models.MetricSnippetsCreated, models.ComparatorGTE, 0},
+	"1_practice_solved":  {models.MetricPracticeSolved, models.ComparatorGTE, 0},
+	"5_practice_solved":  {models.MetricPracticeSolved, models.ComparatorGTE, 0},
+	"25_practice_solved": {models.MetricPracticeSolved, models.ComparatorGTE, 0},
+	"feedback_given":     {models.MetricFeedbackGiven, models.ComparatorGTE, 0},
+	"5_feedback":         {models.MetricFeedbackGiven, models.ComparatorGTE, 0},
+	"1_contest":          {models.MetricContestsJoined, models.ComparatorGTE, 0},
+	"5_contests":         {models.MetricContestsJoined, models.ComparatorGTE, 0},
+	"early_adopter":      {models.MetricJoinRank, models.ComparatorLTE, 1000},
+	"contest_winner":     {models.MetricContestRank, models.ComparatorLTE, 1},
+}
+
+// MigrateLegacyBadges gives badges that only have a legacy condition string the
+// equivalent rule
+func MigrateLegacyBadges(db *gorm.DB) error {
+	var badges []models.Badge
+	if err := db.Where("metric IS NULL OR metric = ''").Find(&badges).Error; err != nil {
+		return err
+	}
+	for _, badge := range badges {
+		rule, ok := legacyBadgeRules[badge.Condition]
 		if !ok {
+			logger.Warn().Str("badge", badge.ID).Str("condition", badge.Condition).Msg("Badge has no rule and won't be awarded")
 			continue
 		}
+		threshold := badge.Threshold
+		if rule.Threshold != 0 {
+			threshold = rule.Threshold
+		}
+		if err := db.Model(&models.Badge{}).Where("id = ?", badge.ID).Updates(map[string]interface{}{
+			"metric":     rule.Metric,
+			"comparator": rule.Comparator,
+			"threshold":  threshold,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BackfillBadgeMetrics computes every user's metrics from existing data, once, on the
+// first boot with the metrics table. Streaks and windowed badges start from there.
+func BackfillBadgeMetrics(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&models.UserMetric{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
 
-		if progress >= int64(badge.Threshold) {
-			// Award badge
-			userBadge := models.UserBadge{
-				UserID:     userID,
-				BadgeID:    badge.ID,
-				Progress:   int(progress),
-				UnlockedAt: time.Now(),
-			}
+	type row struct {
+		UserID string
+		Value  int
+	}
+	queries := map[models.BadgeMetric]*gorm.DB{
+		models.MetricSnippetsCreated: db.Model(&models.Snippet{}).
+			Select(`"authorId" AS user_id, COUNT(*) AS value`).Group("user_id"),
+		models.MetricPracticeSolved: db.Model(&models.PracticeSubmission{}).
+			Select(`"userId" AS user_id, COUNT(DISTINCT "problemId") AS value`).
+			Where("status = ?", models.SubStatusAC).Group("user_id"),
+		models.MetricFeedbackGiven: db.Model(&models.FeedbackMessage{}).
+			Select("user_id, COUNT(*) AS value").Group("user_id"),
+		models.MetricContestsJoined: db.Model(&models.Registration{}).
+			Select("user_id, COUNT(*) AS value").Where("status <> ?", models.RegStatusBanned).Group("user_id"),
+		models.MetricContestRank: db.Model(&models.RatingHistory{}).
+			Select("user_id, MIN(rank) AS value").Group("user_id"),
+	}
 
-			if err := database.DB.Create(&userBadge).Error; err == nil {
-				newBadges = append(newBadges, badge)
-			}
+	now := time.Now()
+	var metrics []models.UserMetric
+	for metric, query := range queries {
+		var rows []row
+		if err := query.Scan(&rows).Error; err != nil {
+			return err
+		}
+		for _, r := range rows {
+			metrics = append(metrics, models.UserMetric{UserID: r.UserID, Metric: metric, Value: r.Value, LastAt: now, UpdatedAt: now})
 		}
 	}
 
-	return newBadges, nil
+	var userIDs []string
+	if err := db.Model(&models.User{}).Where("id <> ?", models.SystemUserID).
+		Order(`"createdAt" asc, id asc`).Pluck("id", &userIDs).Error; err != nil {
+		return err
+	}
+	for i, id := range userIDs {
+		metrics = append(metrics, models.UserMetric{UserID: id, Metric: models.MetricJoinRank, Value: i + 1, LastAt: now, UpdatedAt: now})
+	}
+
+	if len(metrics) == 0 {
+		return nil
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&metrics, 500).Error
+}
+
+// JoinRank is a user's position in sign-up order, not counting the system user
+func JoinRank(db *gorm.DB, user *models.User) (int, error) {
+	var rank int64
+	err := db.Model(&models.User{}).Where(`"createdAt" <= ? AND id <> ?`, user.CreatedAt, models.SystemUserID).Count(&rank).Error
+	return int(rank), err
 }
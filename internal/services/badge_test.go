@@ -0,0 +1,95 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordBadgeEvent_IncrementalProgress(t *testing.T) {
+	db := setupTestDB(t)
+	db.Create(&[]models.Badge{
+		{ID: "three-snippets", Name: "Three Snippets", Metric: models.MetricSnippetsCreated, Comparator: models.ComparatorGTE, Threshold: 3},
+		{ID: "week-streak", Name: "7-day Streak", Metric: models.MetricSolveStreak, Comparator: models.ComparatorGTE, Threshold: 7},
+		{ID: "podium", Name: "Podium", Metric: models.MetricContestRank, Comparator: models.ComparatorLTE, Threshold: 3},
+	})
+
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		unlocked, err := RecordBadgeEvent(db, "u1", models.MetricSnippetsCreated, 1, now)
+		require.NoError(t, err)
+		assert.Empty(t, unlocked)
+	}
+	var ub models.UserBadge
+	db.First(&ub, "user_id = ? AND badge_id = ?", "u1", "three-snippets")
+	assert.Equal(t, 2, ub.Progress)
+	assert.Nil(t, ub.UnlockedAt)
+
+	unlocked, err := RecordBadgeEvent(db, "u1", models.MetricSnippetsCreated, 1, now)
+	require.NoError(t, err)
+	require.Len(t, unlocked, 1)
+	assert.Equal(t, "three-snippets", unlocked[0].ID)
+
+	// Deleting a snippet lowers the count but an unlocked badge stays unlocked
+	_, err = RecordBadgeEvent(db, "u1", models.MetricSnippetsCreated, -1, now)
+	require.NoError(t, err)
+	db.First(&ub, "user_id = ? AND badge_id = ?", "u1", "three-snippets")
+	assert.NotNil(t, ub.UnlockedAt)
+
+	// Seven consecutive days, with a same-day repeat that doesn't count twice
+	start := now.AddDate(0, 0, -10)
+	for day := 0; day < 7; day++ {
+		unlocked, err = RecordBadgeEvent(db, "u1", models.MetricSolveStreak, 1, start.AddDate(0, 0, day))
+		require.NoError(t, err)
+		if day == 2 {
+			_, err = RecordBadgeEvent(db, "u1", models.MetricSolveStreak, 1, start.AddDate(0, 0, day))
+			require.NoError(t, err)
+		}
+	}
+	require.Len(t, unlocked, 1)
+	assert.Equal(t, "week-streak", unlocked[0].ID)
+
+	// A gap restarts the streak
+	_, err = RecordBadgeEvent(db, "u1", models.MetricSolveStreak, 1, now)
+	require.NoError(t, err)
+	var streak models.UserMetric
+	db.First(&streak, "user_id = ? AND metric = ?", "u1", models.MetricSolveStreak)
+	assert.Equal(t, 1, streak.Value)
+
+	// Ranked metrics keep the best rank
+	unlocked, err = RecordBadgeEvent(db, "u1", models.MetricContestRank, 5, now)
+	require.NoError(t, err)
+	assert.Empty(t, unlocked)
+	unlocked, err = RecordBadgeEvent(db, "u1", models.MetricContestRank, 2, now)
+	require.NoError(t, err)
+	require.Len(t, unlocked, 1)
+	assert.Equal(t, "podium", unlocked[0].ID)
+}
+
+func TestEvaluateBadges_WindowAndLegacy(t *testing.T) {
+	db := setupTestDB(t)
+	now := time.Now()
+	_, err := RecordBadgeEvent(db, "u2", models.MetricFeedbackGiven, 1, now.AddDate(0, 0, -40))
+	require.NoError(t, err)
+	_, err = RecordBadgeEvent(db, "u2", models.MetricFeedbackGiven, 1, now.AddDate(0, 0, -1))
+	require.NoError(t, err)
+
+	db.Create(&[]models.Badge{
+		{ID: "monthly-feedback", Name: "Monthly Voice", Metric: models.MetricFeedbackGiven, Comparator: models.ComparatorGTE, Threshold: 2, WindowDays: 30},
+		{ID: "feedback_given", Name: "Voice", Condition: "feedback_given", Threshold: 2},
+	})
+	require.NoError(t, MigrateLegacyBadges(db))
+
+	unlocked, err := EvaluateBadges(db, "u2")
+	require.NoError(t, err)
+	require.Len(t, unlocked, 1)
+	assert.Equal(t, "feedback_given", unlocked[0].ID)
+
+	var windowed models.UserBadge
+	db.First(&windowed, "user_id = ? AND badge_id = ?", "u2", "monthly-feedback")
+	assert.Equal(t, 1, windowed.Progress)
+	assert.Nil(t, windowed.UnlockedAt)
+}
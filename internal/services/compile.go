@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// CompiledProgram is a submission prepared once and then run against many inputs
+type CompiledProgram interface {
+	// Compile returns the compile stage: nil for interpreted languages, or until the
+	// first Run on backends that only report it alongside a run (see ExecutionResult.Compile)
+	Compile() *StageResult
+	// Run executes the program with stdin. A failed compile is returned as a result with
+	// a failing Compile stage and no run, like a single Execute would.
+	Run(ctx context.Context, stdin string) (*ExecutionResult, error)
+	// Close releases build artifacts
+	Close() error
+}
+
+// Compiler is implemented by executors that can build once and run many times.
+// Executors without it (Piston's API compiles on every request) get a per-run fallback.
+type Compiler interface {
+	Compile(ctx context.Context, req ExecutionRequest) (CompiledProgram, error)
+}
+
+// BatchExecutor is implemented by backends that compile on every request but can run
+// one build against several inputs per call (see PistonExecutor.ExecuteBatch)
+type BatchExecutor interface {
+	// CanBatch reports whether req's language can be batched
+	CanBatch(req ExecutionRequest) bool
+	// ExecuteBatch builds req once and runs it on stdins in order. It returns the call's
+	// result (language, version and compile stage) and the runs it finished, which may
+	// be fewer than asked; the rest go in another call. No runs are made if the build fails.
+	ExecuteBatch(ctx context.Context, req ExecutionRequest, stdins []string) (*ExecutionResult, []StageResult, error)
+}
+
+// BatchProgram is a compiled program that runs several inputs per backend call
+type BatchProgram interface {
+	CompiledProgram
+	// RunBatch runs the program on stdins in order and returns the results of a prefix
+	// of them, at least one
+	RunBatch(ctx context.Context, stdins []string) ([]*ExecutionResult, error)
+}
+
+// CompileCode prepares code for repeated runs with the same limits, applying the same
+// language guards and normalization as ExecuteCode
+func CompileCode(exec Executor, language, code string, opts ExecuteOptions) (CompiledProgram, error) {
+	if err := checkLanguageGuards(language, code); err != nil {
+		return nil, err
+	}
+	if isWebLanguage(language) {
+		return &perRunProgram{exec: exec, language: language, code: code, opts: opts}, nil
+	}
+	if exec == nil {
+		return nil, fmt.Errorf("%w: no executor configured", ErrExecutorUnavailable)
+	}
+
+	if compiler, ok := exec.(Compiler); ok {
+		return compiler.Compile(context.Background(), buildRequest(language, code, "", opts))
+	}
+	prog := &perRunProgram{exec: exec, language: language, code: code, opts: opts}
+	if batcher, ok := exec.(BatchExecutor); ok {
+		if req := buildRequest(language, code, "", opts); batcher.CanBatch(req) {
+			return &batchProgram{perRunProgram: prog, batcher: batcher, req: req}, nil
+		}
+	}
+	return prog, nil
+}
+
+func stageFailed(stage *StageResult) bool {
+	return stage != nil && (stage.Code != 0 || stage.Signal != "")
+}
+
+// perRunProgram executes the full program for every input. Once a run reports a failed
+// compile, later runs return that result without executing again.
+type perRunProgram struct {
+	exec     Executor
+	language string
+	code     string
+	opts     ExecuteOptions
+
+	mu      sync.Mutex
+	compile *StageResult
+	failed  *ExecutionResult
+}
+
+func (p *perRunProgram) Compile() *StageResult {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.compile
+}
+
+func (p *perRunProgram) Run(ctx context.Context, stdin string) (*ExecutionResult, error) {
+	p.mu.Lock()
+	if p.failed != nil {
+		p.mu.Unlock()
+		return p.failed, nil
+	}
+	p.mu.Unlock()
+
+	res, err := ExecuteCode(p.exec, p.language, p.code, stdin, p.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if res.Compile != nil {
+		p.compile = res.Compile
+		if res.Compile.Code != 0 || res.Compile.Signal != "" {
+			p.failed = res
+		}
+	}
+	return res, nil
+}
+
+func (p *perRunProgram) Close() error {
+	return nil
+}
+
+// batchProgram sends many inputs per request to a BatchExecutor, so the backend compiles
+// once per batch instead of once per input. Single runs go through perRunProgram.
+type batchProgram struct {
+	*perRunProgram
+	batcher BatchExecutor
+	req     ExecutionRequest
+	single  bool // Batching failed for this program; run one input per request
+}
+
+func (p *batchProgram) RunBatch(ctx context.Context, stdins []string) ([]*ExecutionResult, error) {
+	if !p.single {
+		base, runs, err := p.batcher.ExecuteBatch(ctx, p.req, stdins)
+		switch {
+		case err == nil && !stageFailed(base.Compile) && len(runs) > 0:
+			p.mu.Lock()
+			p.compile = base.Compile
+			p.mu.Unlock()
+			results := make([]*ExecutionResult, len(runs))
+			for i, run := range runs {
+				results[i] = &ExecutionResult{Language: base.Language, Version: base.Version, Compile: base.Compile, Run: run}
+			}
+			return results, nil
+		case err != nil && !errors.Is(err, ErrExecutionRejected):
+			return nil, err
+		case err != nil || stageFailed(base.Compile):
+			// Refused, or the build failed: a plain run tells a compile error apart
+			// from a problem with batching
+			p.single = true
+		}
+		// Otherwise the first input couldn't be reported in a batch (e.g. its output
+		// was too large); run it alone and keep batching after it
+	}
+	res, err := p.Run(ctx, stdins[0])
+	if err != nil {
+		return nil, err
+	}
+	return []*ExecutionResult{res}, nil
+}
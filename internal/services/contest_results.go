@@ -0,0 +1,143 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// SnapshotResults replaces an event's ContestResult rows with the given unfrozen standings
+// and mirrors score and rank onto the registrations. Participants banned from the contest
+// are left out and the remaining ranks closed up; a team is left out if any member is.
+// Team rows are stored once per member, sharing the rank. Returns the new results version.
+func SnapshotResults(tx *gorm.DB, eventID string, standings []LeaderboardEntry) (int, error) {
+	var regs []models.Registration
+	if err := tx.Select("user_id", "team_id", "status").
+		Where("event_id = ?", eventID).Find(&regs).Error; err != nil {
+		return 0, err
+	}
+	var banned []string
+	excluded := make(map[string]bool)
+	members := make(map[string][]string) // TeamID -> member user IDs
+	for _, reg := range regs {
+		if reg.TeamID != nil {
+			members[*reg.TeamID] = append(members[*reg.TeamID], reg.UserID)
+		}
+		if reg.Status == models.RegStatusBanned {
+			banned = append(banned, reg.UserID)
+			excluded[reg.UserID] = true
+			if reg.TeamID != nil {
+				excluded[*reg.TeamID] = true
+			}
+		}
+	}
+
+	var version int
+	if err := tx.Model(&models.ContestResult{}).Where("event_id = ?", eventID).
+		Select("COALESCE(MAX(version), 0)").Scan(&version).Error; err != nil {
+		return 0, err
+	}
+	version++
+
+	if err := tx.Where("event_id = ?", eventID).Delete(&models.ContestResult{}).Error; err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	results := make([]models.ContestResult, 0, len(standings))
+	rank := 0
+	for _, entry := range standings {
+		if excluded[entry.UserID] {
+			continue
+		}
+		rank++
+		problems := make(map[string]models.ProblemResult, len(entry.Problems))
+		for id, stat := range entry.Problems {
+			problems[id] = models.ProblemResult{
+				Status:    stat.Status,
+				Attempts:  stat.Attempts,
+				Score:     stat.Score,
+				TimeTaken: stat.TimeTaken,
+				Penalty:   stat.Penalty,
+			}
+		}
+
+		userIDs, teamID := []string{entry.UserID}, (*string)(nil)
+		if entry.TeamID != "" {
+			id := entry.TeamID
+			userIDs, teamID = members[id], &id
+		}
+		for _, userID := range userIDs {
+			results = append(results, models.ContestResult{
+				ID:          uuid.New().String(),
+				EventID:     eventID,
+				UserID:      userID,
+				TeamID:      teamID,
+				Rank:        rank,
+				Score:       entry.TotalScore,
+				SolvedCount: entry.SolvedCount,
+				Penalty:     entry.TotalTime,
+				Status:      entry.Status,
+				Problems:    problems,
+				Version:     version,
+				CreatedAt:   now,
+			})
+		}
+	}
+	if len(results) > 0 {
+		if err := tx.CreateInBatches(&results, 200).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	// Registrations mirror the snapshot; banned participants lose their rank
+	for _, result := range results {
+		if err := tx.Model(&models.Registration{}).
+			Where("event_id = ? AND user_id = ?", eventID, result.UserID).
+			Updates(map[string]interface{}{"score": result.Score, "rank": result.Rank}).Error; err != nil {
+			return 0, err
+		}
+	}
+	if len(banned) > 0 {
+		if err := tx.Model(&models.Registration{}).
+			Where("event_id = ? AND user_id IN ?", eventID, banned).
+			Update("rank", 0).Error; err != nil {
+			return 0, err
+		}
+	}
+	return version, nil
+}
+
+// RerankContest recomputes an ended contest's final standings from its submissions, after
+// moderation (disqualifications, restores, bans), and replaces the snapshot
+func RerankContest(db *gorm.DB, eventID string) (int, error) {
+	var event models.Event
+	if err := db.First(&event, "id = ?", eventID).Error; err != nil {
+		return 0, err
+	}
+	if event.Status != models.EventStatusEnded {
+		return 0, ErrContestNotEnded
+	}
+
+	InvalidateLeaderboardCache(eventID)
+	standings, err := GetLeaderboard(eventID, true)
+	if err != nil {
+		return 0, err
+	}
+
+	var version int
+	err = db.Transaction(func(tx *gorm.DB) error {
+		version, err = SnapshotResults(tx, eventID, standings)
+		return err
+	})
+	return version, err
+}
+
+// ContestResults returns an event's snapshot in rank order with users and teams loaded
+func ContestResults(db *gorm.DB, eventID string) ([]models.ContestResult, error) {
+	var results []models.ContestResult
+	err := db.Preload("User").Preload("Team").Where("event_id = ?", eventID).Order("rank asc").Find(&results).Error
+	return results, err
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRerankContest_AppliesModeration(t *testing.T) {
+	db := setupTestDB(t)
+
+	start := time.Now().Add(-3 * time.Hour)
+	db.Create(&models.Event{ID: "rr", Slug: "rr", Status: models.EventStatusEnded,
+		StartTime: start, EndTime: start.Add(2 * time.Hour)})
+	db.Create(&models.Problem{ID: "rr-a", EventID: "rr", Points: 100})
+	for i, id := range []string{"alice", "bob", "carol"} {
+		db.Create(&models.User{ID: id, Username: id, Email: id + "@example.com", TrustScore: 100})
+		db.Create(&models.Registration{ID: "rr-" + id, UserID: id, EventID: "rr", Status: models.RegStatusPaid})
+		db.Create(&models.Submission{ID: "rr-s-" + id, UserID: id, EventID: "rr", ProblemID: "rr-a",
+			Status: models.SubStatusAC, Score: 100, CreatedAt: start.Add(time.Duration(10*(i+1)) * time.Minute)})
+	}
+
+	version, err := RerankContest(db, "rr")
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+
+	// Moderation after the fact leaves the snapshot alone...
+	db.Model(&models.Registration{}).Where("id = ?", "rr-alice").Update("status", models.RegStatusBanned)
+	results, _ := ContestResults(db, "rr")
+	require.Len(t, results, 3)
+	assert.Equal(t, "alice", results[0].UserID)
+
+	// ...until an explicit re-rank, which drops the banned participant and closes the gap
+	version, err = RerankContest(db, "rr")
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+
+	results, _ = ContestResults(db, "rr")
+	require.Len(t, results, 2)
+	assert.Equal(t, "bob", results[0].UserID)
+	assert.Equal(t, 1, results[0].Rank)
+	assert.Equal(t, 2, results[1].Version)
+
+	var alice, bob models.Registration
+	db.First(&alice, "id = ?", "rr-alice")
+	db.First(&bob, "id = ?", "rr-bob")
+	assert.Equal(t, 0, alice.Rank)
+	assert.Equal(t, 1, bob.Rank)
+}
+
+func TestRerankContest_RequiresEnded(t *testing.T) {
+	db := setupTestDB(t)
+	db.Create(&models.Event{ID: "rr2", Slug: "rr2", Status: models.EventStatusLive,
+		StartTime: time.Now(), EndTime: time.Now().Add(time.Hour)})
+
+	_, err := RerankContest(db, "rr2")
+	assert.ErrorIs(t, err, ErrContestNotEnded)
+}
+
+func TestSnapshotResults_TeamMembersShareRank(t *testing.T) {
+	db := setupTestDB(t)
+
+	start := time.Now().Add(-3 * time.Hour)
+	db.Create(&models.Event{ID: "tm", Slug: "tm", Status: models.EventStatusEnded, TeamSizeMin: 1, TeamSizeMax: 2,
+		StartTime: start, EndTime: start.Add(2 * time.Hour)})
+	db.Create(&models.Problem{ID: "tm-a", EventID: "tm", Points: 100})
+	for i, id := range []string{"red", "blue"} {
+		db.Create(&models.Team{ID: id, Name: id, OwnerID: id + "-1"})
+		for _, member := range []string{id + "-1", id + "-2"} {
+			team := id
+			db.Create(&models.User{ID: member, Username: member, Email: member + "@example.com", TrustScore: 100})
+			db.Create(&models.Registration{ID: "tm-" + member, UserID: member, EventID: "tm", TeamID: &team, Status: models.RegStatusPaid})
+		}
+		team := id
+		db.Create(&models.Submission{ID: "tm-s-" + id, UserID: id + "-2", TeamID: &team, EventID: "tm", ProblemID: "tm-a",
+			Status: models.SubStatusAC, Score: 100, CreatedAt: start.Add(time.Duration(10*(i+1)) * time.Minute)})
+	}
+
+	_, err := RerankContest(db, "tm")
+	require.NoError(t, err)
+
+	results, _ := ContestResults(db, "tm")
+	require.Len(t, results, 4, "one row per member")
+	for _, r := range results {
+		require.NotNil(t, r.TeamID)
+		want := map[string]int{"red": 1, "blue": 2}[*r.TeamID]
+		assert.Equal(t, want, r.Rank, r.UserID)
+	}
+
+	// Banning one member drops the whole team
+	db.Model(&models.Registration{}).Where("id = ?", "tm-red-1").Update("status", models.RegStatusBanned)
+	_, err = RerankContest(db, "tm")
+	require.NoError(t, err)
+
+	results, _ = ContestResults(db, "tm")
+	require.Len(t, results, 2)
+	assert.Equal(t, "blue", *results[0].TeamID)
+	assert.Equal(t, 1, results[0].Rank)
+
+	var silent models.Registration
+	db.First(&silent, "id = ?", "tm-blue-1")
+	assert.Equal(t, 1, silent.Rank, "members who never submitted still share the team's rank")
+}
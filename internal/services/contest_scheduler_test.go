@@ -0,0 +1,180 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContestScheduler_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewContestScheduler(db, ContestSchedulerOptions{})
+	require.NoError(t, EnsureSystemUser(db))
+
+	// Simulated ticks in the past, so the final standings (cut off at now) see every submission
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	freeze := start.Add(90 * time.Minute)
+	event := models.Event{ID: "sched1", Slug: "sched1", Status: models.EventStatusUpcoming,
+		StartTime: start, FreezeTime: &freeze, EndTime: start.Add(2 * time.Hour)}
+	db.Create(&event)
+	db.Create(&models.Problem{ID: "sched1-a", EventID: "sched1", Points: 100})
+	for _, id := range []string{"alice", "bob"} {
+		db.Create(&models.User{ID: id, Username: id, Email: id + "@example.com"})
+		db.Create(&models.Registration{ID: "reg-" + id, UserID: id, EventID: "sched1", Status: models.RegStatusPaid})
+	}
+
+	status := func() models.EventStatus {
+		var e models.Event
+		db.First(&e, "id = ?", "sched1")
+		return e.Status
+	}
+
+	next, ok := s.nextDue(start.Add(-time.Minute))
+	assert.True(t, ok)
+	assert.True(t, next.Equal(start))
+
+	require.NoError(t, s.Tick(start.Add(time.Second)))
+	assert.Equal(t, models.EventStatusLive, status())
+
+	db.Create(&models.Submission{ID: "sched1-s", UserID: "alice", EventID: "sched1", ProblemID: "sched1-a",
+		Status: models.SubStatusAC, Score: 100, CreatedAt: start.Add(10 * time.Minute)})
+
+	require.NoError(t, s.Tick(freeze))
+	assert.Equal(t, models.EventStatusFrozen, status())
+
+	end := event.EndTime.Add(time.Second)
+	require.NoError(t, s.Tick(end))
+	require.NoError(t, s.Tick(end)) // Nothing fires twice
+	assert.Equal(t, models.EventStatusEnded, status())
+
+	var logs []models.AdminAuditLog
+	db.Where("entity_id = ?", "sched1").Order("created_at asc").Find(&logs)
+	if assert.Len(t, logs, 4) {
+		assert.Equal(t, models.ActionStartContest, logs[0].ActionType)
+		assert.Equal(t, models.ActionFinalizeContest, logs[3].ActionType)
+		assert.Equal(t, models.SystemUserID, logs[0].AdminID)
+	}
+
+	var alice, bob models.Registration
+	db.First(&alice, "id = ?", "reg-alice")
+	db.First(&bob, "id = ?", "reg-bob")
+	assert.Equal(t, 1, alice.Rank)
+	assert.Equal(t, 100, alice.Score)
+	assert.Equal(t, models.RegStatusPaid, alice.Status)
+	assert.Equal(t, models.RegStatusNoShow, bob.Status)
+
+	results, err := ContestResults(db, "sched1")
+	require.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "alice", results[0].UserID)
+		assert.Equal(t, 1, results[0].Version)
+		assert.Equal(t, string(models.SubStatusAC), results[0].Problems["sched1-a"].Status)
+	}
+}
+
+func TestContestScheduler_SingleLeader(t *testing.T) {
+	db := setupTestDB(t)
+	a := NewContestScheduler(db, ContestSchedulerOptions{Lease: time.Minute})
+	b := NewContestScheduler(db, ContestSchedulerOptions{Lease: time.Minute})
+	b.host = a.host + "-other"
+
+	now := time.Now()
+	assert.True(t, a.acquireLease(now))
+	assert.False(t, b.acquireLease(now))
+	assert.True(t, a.acquireLease(now.Add(30*time.Second)), "the holder renews")
+
+	// Once the lease lapses another replica takes over
+	assert.True(t, b.acquireLease(now.Add(2*time.Minute)))
+	assert.False(t, a.acquireLease(now.Add(2*time.Minute)))
+}
+
+func TestFinalizeContest_WaitsForPendingJudging(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, EnsureSystemUser(db))
+
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	event := models.Event{ID: "sched2", Slug: "sched2", Status: models.EventStatusEnded, StartTime: start, EndTime: start.Add(2 * time.Hour)}
+	db.Create(&event)
+	db.Create(&models.Problem{ID: "sched2-a", EventID: "sched2", Points: 100})
+	db.Create(&models.User{ID: "carol", Username: "carol", Email: "carol@example.com"})
+	db.Create(&models.Registration{ID: "reg-carol", UserID: "carol", EventID: "sched2", Status: models.RegStatusPaid})
+	db.Create(&models.Submission{ID: "sched2-s", UserID: "carol", EventID: "sched2", ProblemID: "sched2-a",
+		Status: models.SubStatusPending, CreatedAt: event.EndTime.Add(-time.Minute)})
+
+	// Submitted on time but still queued: nothing is snapshotted yet
+	require.NoError(t, FinalizeContest(db, "sched2", event.EndTime.Add(time.Minute)))
+	var e models.Event
+	db.First(&e, "id = ?", "sched2")
+	assert.Nil(t, e.FinalizedAt)
+	results, err := ContestResults(db, "sched2")
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	// Once judged, the next attempt snapshots the verdict
+	db.Model(&models.Submission{}).Where("id = ?", "sched2-s").Updates(map[string]interface{}{"status": models.SubStatusAC, "score": 100})
+	require.NoError(t, FinalizeContest(db, "sched2", event.EndTime.Add(2*time.Minute)))
+	db.First(&e, "id = ?", "sched2")
+	assert.NotNil(t, e.FinalizedAt)
+
+	var reg models.Registration
+	db.First(&reg, "id = ?", "reg-carol")
+	assert.Equal(t, 1, reg.Rank)
+	assert.Equal(t, 100, reg.Score)
+	assert.Equal(t, models.RegStatusPaid, reg.Status)
+}
+
+func TestFinalizeContest_StopsWaitingAfterGrace(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, EnsureSystemUser(db))
+
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	event := models.Event{ID: "sched3", Slug: "sched3", Status: models.EventStatusEnded, StartTime: start, EndTime: start.Add(2 * time.Hour)}
+	db.Create(&event)
+	db.Create(&models.Problem{ID: "sched3-a", EventID: "sched3", Points: 100})
+	db.Create(&models.User{ID: "erin", Username: "erin", Email: "erin@example.com"})
+	db.Create(&models.Registration{ID: "reg-erin", UserID: "erin", EventID: "sched3", Status: models.RegStatusPaid})
+	db.Create(&models.Submission{ID: "sched3-ac", UserID: "erin", EventID: "sched3", ProblemID: "sched3-a",
+		Status: models.SubStatusAC, Score: 100, CreatedAt: start.Add(time.Hour)})
+	db.Create(&models.Submission{ID: "sched3-stuck", UserID: "erin", EventID: "sched3", ProblemID: "sched3-a",
+		Status: models.SubStatusPending, CreatedAt: start.Add(30 * time.Minute)})
+	db.Create(&models.JudgeJob{ID: "sched3-job", Kind: models.JudgeJobContest, SubmissionID: "sched3-stuck", Status: models.JudgeJobQueued})
+
+	require.NoError(t, FinalizeContest(db, "sched3", event.EndTime.Add(FinalizeGrace)))
+	var e models.Event
+	db.First(&e, "id = ?", "sched3")
+	assert.NotNil(t, e.FinalizedAt)
+
+	var stuck models.Submission
+	db.First(&stuck, "id = ?", "sched3-stuck")
+	assert.Equal(t, models.SubStatusRE, stuck.Status)
+	assert.Contains(t, stuck.Verdict, models.JudgeErrorVerdict)
+	var job models.JudgeJob
+	db.First(&job, "id = ?", "sched3-job")
+	assert.Equal(t, models.JudgeJobFailed, job.Status)
+
+	// The straggler costs no attempt, so the AC carries no penalty
+	var reg models.Registration
+	db.First(&reg, "id = ?", "reg-erin")
+	assert.Equal(t, 1, reg.Rank)
+	assert.Equal(t, 100, reg.Score)
+}
+
+func TestEnsureSystemUser_IsHidden(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, EnsureSystemUser(db))
+	require.NoError(t, EnsureSystemUser(db), "safe to run on every start")
+
+	var system models.User
+	require.NoError(t, db.First(&system, "id = ?", models.SystemUserID).Error)
+	assert.False(t, system.SearchVisible)
+	assert.False(t, system.PublicProfileEnabled)
+
+	user := models.User{ID: "dave", Username: "dave", Email: "dave@example.com", CreatedAt: time.Now().Add(time.Minute)}
+	db.Create(&user)
+	rank, err := JoinRank(db, &user)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rank, "the system user doesn't take a sign-up slot")
+}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrDailyDate         = errors.New("date must be YYYY-MM-DD")
+	ErrDailyPast         = errors.New("past days of the calendar can't be changed")
+	ErrNoPracticeProblem = errors.New("no practice problems available")
+	ErrStreakFreezeCap   = errors.New("you already hold the maximum number of streak freezes")
+)
+
+const dailyDateLayout = "2006-01-02"
+
+// dailyRepeatDays is how recently featured a problem can be before auto-fill reuses it
+const dailyRepeatDays = 90
+
+// DailyDate is the calendar day (UTC) t falls on
+func DailyDate(t time.Time) string {
+	return t.UTC().Format(dailyDateLayout)
+}
+
+// ParseDailyDate validates a calendar day
+func ParseDailyDate(date string) (time.Time, error) {
+	day, err := time.Parse(dailyDateLayout, date)
+	if err != nil {
+		return day, ErrDailyDate
+	}
+	return day, nil
+}
+
+// dailyDifficulty is the difficulty auto-fill aims for: easy early in the week, hard
+// going into the weekend
+func dailyDifficulty(day time.Time) string {
+	switch day.Weekday() {
+	case time.Monday, time.Tuesday:
+		return "EASY"
+	case time.Friday, time.Saturday:
+		return "HARD"
+	default:
+		return "MEDIUM"
+	}
+}
+
+// DailyProblemFor returns a day's calendar entry with its problem, auto-filling the day
+// when nobody planned it
+func DailyProblemFor(db *gorm.DB, date string) (*models.DailyProblem, error) {
+	var entry models.DailyProblem
+	err := db.Preload("Problem").First(&entry, "date = ?", date).Error
+	if err == nil {
+		return &entry, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err := autoFillDailyProblem(db, date); err != nil {
+		return nil, err
+	}
+	if err := db.Preload("Problem").First(&entry, "date = ?", date).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// autoFillDailyProblem picks the least solved problem of the day's difficulty that wasn't
+// featured recently, relaxing the difficulty and then recency when nothing qualifies.
+// The choice is deterministic, so concurrent fills agree; the first insert wins.
+func autoFillDailyProblem(db *gorm.DB, date string) error {
+	day, err := ParseDailyDate(date)
+	if err != nil {
+		return err
+	}
+	var recent []string
+	if err := db.Model(&models.DailyProblem{}).
+		Where("date >= ? AND date <> ?", DailyDate(day.AddDate(0, 0, -dailyRepeatDays)), date).
+		Pluck("problem_id", &recent).Error; err != nil {
+		return err
+	}
+
+	pick := func(difficulty string, exclude []string) (*models.PracticeProblem, error) {
+		query := db.Model(&models.PracticeProblem{})
+		if len(exclude) > 0 {
+			query = query.Where("id NOT IN ?", exclude)
+		}
+		if difficulty != "" {
+			query = query.Where("difficulty = ?", difficulty)
+		}
+		var problem models.PracticeProblem
+		err := query.Order(`solve_count asc, "createdAt" asc, id asc`).First(&problem).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return &problem, err
+	}
+
+	var problem *models.PracticeProblem
+	for _, attempt := range []struct {
+		difficulty string
+		exclude    []string
+	}{{dailyDifficulty(day), recent}, {"", recent}, {"", nil}} {
+		if problem, err = pick(attempt.difficulty, attempt.exclude); err != nil {
+			return err
+		}
+		if problem != nil {
+			break
+		}
+	}
+	if problem == nil {
+		return ErrNoPracticeProblem
+	}
+
+	now := time.Now()
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.DailyProblem{
+		Date:      date,
+		ProblemID: problem.ID,
+		Source:    models.DailySourceAuto,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}).Error
+}
+
+// dailyFlag remembers which problem PracticeProblem.IsDailyProblem was last synced to
+var dailyFlag struct {
+	sync.Mutex
+	date, problemID string
+}
+
+// TodaysDailyProblem returns today's calendar entry and keeps the legacy
+// IsDailyProblem flag on exactly that problem
+func TodaysDailyProblem(db *gorm.DB) (*models.DailyProblem, error) {
+	today := DailyDate(time.Now())
+	entry, err := DailyProblemFor(db, today)
+	if err != nil {
+		return nil, err
+	}
+
+	dailyFlag.Lock()
+	defer dailyFlag.Unlock()
+	if dailyFlag.date != today || dailyFlag.problemID != entry.ProblemID {
+		if err := db.Model(&models.PracticeProblem{}).
+			Where("is_daily_problem <> (id = ?)", entry.ProblemID).
+			Update("is_daily_problem", gorm.Expr("id = ?", entry.ProblemID)).Error; err != nil {
+			return nil, err
+		}
+		dailyFlag.date, dailyFlag.problemID = today, entry.ProblemID
+	}
+	return entry, nil
+}
+
+// DailyCalendar returns the planned or filled days between from and to, inclusive
+func DailyCalendar(db *gorm.DB, from, to string) ([]models.DailyProblem, error) {
+	var days []models.DailyProblem
+	err := db.Preload("Problem").Where("date >= ? AND date <= ?", from, to).Order("date asc").Find(&days).Error
+	return days, err
+}
+
+// PlanDailyProblem sets the problem for today or a future day
+func PlanDailyProblem(db *gorm.DB, date, problemID, adminID string) (*models.DailyProblem, error) {
+	if _, err := ParseDailyDate(date); err != nil {
+		return nil, err
+	}
+	if date < DailyDate(time.Now()) {
+		return nil, ErrDailyPast
+	}
+	var problem models.PracticeProblem
+	if err := db.Select("id").First(&problem, "id = ?", problemID).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entry := models.DailyProblem{
+		Date:      date,
+		ProblemID: problemID,
+		Source:    models.DailySourcePlanned,
+		CreatedBy: adminID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"problem_id", "source", "created_by", "updated_at"}),
+	}).Create(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// UnplanDailyProblem clears a future day, leaving it to auto-fill
+func UnplanDailyProblem(db *gorm.DB, date string) error {
+	if _, err := ParseDailyDate(date); err != nil {
+		return err
+	}
+	if date <= DailyDate(time.Now()) {
+		return ErrDailyPast
+	}
+	res := db.Delete(&models.DailyProblem{}, "date = ?", date)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// AutoFillDailyCalendar fills the days from `from` on that have no problem yet
+func AutoFillDailyCalendar(db *gorm.DB, from time.Time, days int) (int, error) {
+	var planned []string
+	if err := db.Model(&models.DailyProblem{}).
+		Where("date >= ? AND date < ?", DailyDate(from), DailyDate(from.AddDate(0, 0, days))).
+		Pluck("date", &planned).Error; err != nil {
+		return 0, err
+	}
+	have := make(map[string]bool, len(planned))
+	for _, d := range planned {
+		have[d] = true
+	}
+
+	filled := 0
+	for i := 0; i < days; i++ {
+		date := DailyDate(from.AddDate(0, 0, i))
+		if have[date] {
+			continue
+		}
+		if err := autoFillDailyProblem(db, date); err != nil {
+			return filled, err
+		}
+		filled++
+	}
+	return filled, nil
+}
+
+// daysBetween counts calendar days from a to b
+func daysBetween(a, b string) int {
+	from, _ := time.Parse(dailyDateLayout, a)
+	to, _ := time.Parse(dailyDateLayout, b)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// RecordDailySolve extends the user's streak when an accepted submission solves the
+// daily problem of the day it was made. Days missed since the last solve are covered
+// by streak freezes when the user holds enough; otherwise the streak restarts.
+// counted is false for anything but the day's first solve of its problem.
+func RecordDailySolve(db *gorm.DB, userID, problemID string, at time.Time) (streak *models.DailyStreak, counted bool, err error) {
+	date := DailyDate(at)
+	entry, err := DailyProblemFor(db, date)
+	if err != nil || entry.ProblemID != problemID {
+		return nil, false, err
+	}
+
+	var s models.DailyStreak
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).
+			Attrs(models.DailyStreak{UserID: userID}).FirstOrInit(&s).Error; err != nil {
+			return err
+		}
+		if s.LastDate >= date {
+			return nil // Already solved (or frozen) today
+		}
+
+		var solves []models.DailySolve
+		switch missed := daysBetween(s.LastDate, date) - 1; {
+		case s.LastDate == "":
+			s.Current = 1
+		case missed == 0:
+			s.Current++
+		case missed <= s.Freezes:
+			last, _ := time.Parse(dailyDateLayout, s.LastDate)
+			for i := 1; i <= missed; i++ {
+				solves = append(solves, models.DailySolve{UserID: userID, Date: DailyDate(last.AddDate(0, 0, i)), Frozen: true, SolvedAt: at})
+			}
+			s.Freezes -= missed
+			s.Current++
+		default:
+			s.Current = 1
+		}
+		s.Longest = max(s.Longest, s.Current)
+		s.LastDate = date
+		s.UpdatedAt = time.Now()
+
+		solves = append(solves, models.DailySolve{UserID: userID, Date: date, ProblemID: problemID, SolvedAt: at})
+		if err := tx.Create(&solves).Error; err != nil {
+			return err
+		}
+		counted = true
+		return tx.Save(&s).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &s, counted, nil
+}
+
+// StreakFor returns the user's streak as of now: a streak whose missed days outnumber
+// the user's freezes is reported as broken (0) even before the next solve resets it
+func StreakFor(db *gorm.DB, userID string, now time.Time) (models.DailyStreak, error) {
+	var s models.DailyStreak
+	if err := db.Where("user_id = ?", userID).Limit(1).Find(&s).Error; err != nil {
+		return s, err
+	}
+	today := DailyDate(now)
+	s.SolvedToday = s.LastDate == today
+	if s.LastDate != "" && daysBetween(s.LastDate, today)-1 > s.Freezes {
+		s.Current = 0
+	}
+	return s, nil
+}
+
+// DailyHistory returns the user's solved and frozen days over the last `days` days
+func DailyHistory(db *gorm.DB, userID string, days int, now time.Time) ([]models.DailySolve, error) {
+	var history []models.DailySolve
+	err := db.Where("user_id = ? AND date > ?", userID, DailyDate(now.AddDate(0, 0, -days))).
+		Order("date desc").Find(&history).Error
+	return history, err
+}
+
+// buyStreakFreeze charges price XP for one more streak freeze, up to MaxStreakFreezes
+func buyStreakFreeze(tx *gorm.DB, userID string, price int) error {
+	var s models.DailyStreak
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).
+		Attrs(models.DailyStreak{UserID: userID}).FirstOrInit(&s).Error; err != nil {
+		return err
+	}
+	if s.Freezes >= models.MaxStreakFreezes {
+		return ErrStreakFreezeCap
+	}
+
+	s.FreezesBought++
+	if price > 0 {
+		if _, err := ApplyXP(tx, XPChange{
+			UserID:         userID,
+			Amount:         -price,
+			Source:         models.XPSourcePurchase,
+			ReferenceType:  "store_item",
+			ReferenceID:    models.StreakFreezeItemID,
+			IdempotencyKey: "purchase:" + userID + ":" + models.StreakFreezeItemID + ":" + strconv.Itoa(s.FreezesBought),
+		}); err != nil {
+			return err
+		}
+	}
+	s.Freezes++
+	s.UpdatedAt = time.Now()
+	return tx.Save(&s).Error
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDailyProblemFor_AutoFill(t *testing.T) {
+	db := setupTestDB(t)
+	db.Create(&[]models.PracticeProblem{
+		{ID: "easy-popular", Title: "A", Difficulty: "EASY", SolveCount: 50},
+		{ID: "easy-rare", Title: "B", Difficulty: "EASY", SolveCount: 2},
+		{ID: "hard-rare", Title: "C", Difficulty: "HARD", SolveCount: 0},
+	})
+
+	// 2030-01-07 is a Monday: easy day
+	monday, err := DailyProblemFor(db, "2030-01-07")
+	require.NoError(t, err)
+	assert.Equal(t, "easy-rare", monday.ProblemID)
+	assert.Equal(t, models.DailySourceAuto, monday.Source)
+	require.NotNil(t, monday.Problem)
+
+	// Stable once filled, and not repeated the next day
+	again, err := DailyProblemFor(db, "2030-01-07")
+	require.NoError(t, err)
+	assert.Equal(t, "easy-rare", again.ProblemID)
+	tuesday, err := DailyProblemFor(db, "2030-01-08")
+	require.NoError(t, err)
+	assert.Equal(t, "easy-popular", tuesday.ProblemID)
+
+	// Planned days win; the past can't be planned
+	planned, err := PlanDailyProblem(db, "2030-01-11", "easy-popular", "admin")
+	require.NoError(t, err)
+	assert.Equal(t, models.DailySourcePlanned, planned.Source)
+	_, err = PlanDailyProblem(db, "2001-01-01", "easy-popular", "admin")
+	assert.ErrorIs(t, err, ErrDailyPast)
+
+	filled, err := AutoFillDailyCalendar(db, time.Date(2030, 1, 7, 12, 0, 0, 0, time.UTC), 7)
+	require.NoError(t, err)
+	assert.Equal(t, 4, filled) // 7 days minus the two auto-filled and one planned
+}
+
+func TestRecordDailySolve_StreakAndFreezes(t *testing.T) {
+	db := setupTestDB(t)
+	db.Create(&models.User{ID: "streaker", Username: "streaker", Email: "s@example.com", XP: 500, Level: 1})
+	db.Create(&models.PracticeProblem{ID: "p1", Title: "P1", Difficulty: "EASY"})
+	require.NoError(t, SeedStoreCatalog(db))
+
+	day := func(i int) time.Time { return time.Now().UTC().AddDate(0, 0, 10+i) }
+	for i := 0; i < 8; i++ {
+		_, err := PlanDailyProblem(db, DailyDate(day(i)), "p1", "admin")
+		require.NoError(t, err)
+	}
+
+	s, counted, err := RecordDailySolve(db, "streaker", "p1", day(0))
+	require.NoError(t, err)
+	assert.True(t, counted)
+	s, _, err = RecordDailySolve(db, "streaker", "p1", day(1))
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.Current)
+	_, counted, err = RecordDailySolve(db, "streaker", "p1", day(1))
+	require.NoError(t, err)
+	assert.False(t, counted, "a second solve the same day doesn't count")
+
+	// Freezes come from the store, for XP, up to the cap
+	user, _, err := PurchaseStoreItem(db, "streaker", models.StreakFreezeItemID, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 300, user.XP)
+	_, _, err = PurchaseStoreItem(db, "streaker", models.StreakFreezeItemID, time.Now())
+	require.NoError(t, err)
+	_, _, err = PurchaseStoreItem(db, "streaker", models.StreakFreezeItemID, time.Now())
+	assert.ErrorIs(t, err, ErrStreakFreezeCap)
+
+	// Missing day 2 and 3 costs both freezes and keeps the streak
+	s, _, err = RecordDailySolve(db, "streaker", "p1", day(4))
+	require.NoError(t, err)
+	assert.Equal(t, 3, s.Current)
+	assert.Equal(t, 0, s.Freezes)
+	history, err := DailyHistory(db, "streaker", 30, day(4))
+	require.NoError(t, err)
+	require.Len(t, history, 5)
+	assert.True(t, history[1].Frozen)
+
+	// Without freezes a gap restarts it
+	s, _, err = RecordDailySolve(db, "streaker", "p1", day(7))
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.Current)
+	assert.Equal(t, 3, s.Longest)
+
+	status, err := StreakFor(db, "streaker", day(7))
+	require.NoError(t, err)
+	assert.True(t, status.SolvedToday)
+	status, err = StreakFor(db, "streaker", day(9))
+	require.NoError(t, err)
+	assert.Equal(t, 0, status.Current, "broken by a missed day with no freezes")
+}
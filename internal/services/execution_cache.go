@@ -0,0 +1,188 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/database"
+)
+
+const (
+	executionCachePrefix  = "exec:v1:" // Bump when the key or stored shape changes
+	executionCacheTTL     = 1 * time.Hour
+	defaultExecCacheSize  = 1000
+	runtimeVersionsMaxAge = 10 * time.Minute
+	// runtimeVersionsRetry is how long a failed runtime lookup is remembered, so a
+	// down backend isn't asked again by every execution
+	runtimeVersionsRetry = 30 * time.Second
+)
+
+// ExecutionCache is a bounded LRU of execution results with an optional Redis tier
+// (via database.CacheSet/CacheGet) so replicas share hits.
+type ExecutionCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	order    *list.List // Front = most recently used
+	items    map[string]*list.Element
+	shared   bool // Also read/write Redis
+}
+
+type execCacheEntry struct {
+	key      string
+	result   *ExecutionResult
+	storedAt time.Time
+}
+
+func NewExecutionCache(capacity int, ttl time.Duration, shared bool) *ExecutionCache {
+	if capacity <= 0 {
+		capacity = defaultExecCacheSize
+	}
+	return &ExecutionCache{
+		capacity: capacity,
+		ttl:      ttl,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+		shared:   shared,
+	}
+}
+
+var executionCache = NewExecutionCache(defaultExecCacheSize, executionCacheTTL, false)
+
+// InitExecutionCache sizes the process-wide cache; shared should only be true when
+// Redis answered a ping, otherwise every miss would wait on a dead connection.
+func InitExecutionCache(capacity int, shared bool) {
+	executionCache = NewExecutionCache(capacity, executionCacheTTL, shared)
+}
+
+// Get returns a cached result, falling back to Redis and promoting Redis hits locally
+func (c *ExecutionCache) Get(key string) (*ExecutionResult, bool) {
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*execCacheEntry)
+		if time.Since(entry.storedAt) < c.ttl {
+			c.order.MoveToFront(el)
+			c.mu.Unlock()
+			return entry.result, true
+		}
+		c.order.Remove(el)
+		delete(c.items, key)
+	}
+	c.mu.Unlock()
+
+	if !c.shared {
+		return nil, false
+	}
+	var result ExecutionResult
+	if err := database.CacheGet(executionCachePrefix+key, &result); err != nil {
+		return nil, false // redis.Nil or Redis trouble: treat as a miss
+	}
+	c.setLocal(key, &result)
+	return &result, true
+}
+
+// Set stores a result locally and, when shared, in Redis
+func (c *ExecutionCache) Set(key string, result *ExecutionResult) {
+	c.setLocal(key, result)
+	if c.shared {
+		database.CacheSet(executionCachePrefix+key, result, c.ttl)
+	}
+}
+
+func (c *ExecutionCache) setLocal(key string, result *ExecutionResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value = &execCacheEntry{key: key, result: result, storedAt: time.Now()}
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&execCacheEntry{key: key, result: result, storedAt: time.Now()})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*execCacheEntry).key)
+	}
+}
+
+// Len returns the number of locally cached entries
+func (c *ExecutionCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// executionCacheKey covers everything that can change the outcome of a run:
+// the resolved runtime versions, the limits, and the program with its input
+func executionCacheKey(versions string, req ExecutionRequest) string {
+	raw := fmt.Sprintf("%s\x00%s\x00%s\x00%d\x00%d\x00%d\x00%s\x00%s",
+		req.Language, req.Version, versions, req.RunTimeout, req.CompileTimeout, req.MemoryLimit, req.Code, req.Stdin)
+	hash := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(hash[:])
+}
+
+// runtimeVersions memoizes each executor's runtime list so "*" requests can be keyed
+// on the versions actually installed; an upgrade on the backend changes every key
+var runtimeVersions = struct {
+	sync.Mutex
+	byExecutor map[Executor]runtimeSnapshot
+}{byExecutor: make(map[Executor]runtimeSnapshot)}
+
+type runtimeSnapshot struct {
+	versions  map[string]string // language -> sorted, comma-joined versions
+	fetchedAt time.Time
+	failed    bool // The lookup errored; versions is empty until the retry
+}
+
+func (s runtimeSnapshot) stale() bool {
+	maxAge := runtimeVersionsMaxAge
+	if s.failed {
+		maxAge = runtimeVersionsRetry
+	}
+	return time.Since(s.fetchedAt) > maxAge
+}
+
+func resolveVersions(exec Executor, language string) string {
+	runtimeVersions.Lock()
+	snap, ok := runtimeVersions.byExecutor[exec]
+	runtimeVersions.Unlock()
+
+	if !ok || snap.stale() {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		runtimes, err := exec.Runtimes(ctx)
+		cancel()
+		if err != nil {
+			// Unknown; the key still includes the requested version
+			runtimeVersions.Lock()
+			runtimeVersions.byExecutor[exec] = runtimeSnapshot{fetchedAt: time.Now(), failed: true}
+			runtimeVersions.Unlock()
+			return ""
+		}
+
+		all := make(map[string][]string)
+		for _, rt := range runtimes {
+			all[rt.Language] = append(all[rt.Language], rt.Version)
+			for _, alias := range rt.Aliases {
+				all[alias] = append(all[alias], rt.Version)
+			}
+		}
+		snap = runtimeSnapshot{versions: make(map[string]string, len(all)), fetchedAt: time.Now()}
+		for lang, versions := range all {
+			sort.Strings(versions)
+			snap.versions[lang] = strings.Join(versions, ",")
+		}
+
+		runtimeVersions.Lock()
+		runtimeVersions.byExecutor[exec] = snap
+		runtimeVersions.Unlock()
+	}
+	return snap.versions[language]
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutionCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewExecutionCache(2, time.Hour, false)
+	c.Set("a", &ExecutionResult{Version: "a"})
+	c.Set("b", &ExecutionResult{Version: "b"})
+
+	_, ok := c.Get("a") // a is now most recent
+	assert.True(t, ok)
+	c.Set("c", &ExecutionResult{Version: "c"})
+
+	_, ok = c.Get("b")
+	assert.False(t, ok, "b should have been evicted")
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestExecutionCache_Expires(t *testing.T) {
+	c := NewExecutionCache(10, time.Millisecond, false)
+	c.Set("a", &ExecutionResult{})
+	time.Sleep(5 * time.Millisecond)
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestExecuteCode_CacheKeyAndBypass(t *testing.T) {
+	prev := executionCache
+	executionCache = NewExecutionCache(10, time.Hour, false)
+	t.Cleanup(func() { executionCache = prev })
+
+	exec := NewFakeExecutor()
+	code := "print(input())"
+
+	ExecuteCode(exec, "python", code, "x", ExecuteOptions{TimeLimit: 1})
+	ExecuteCode(exec, "python", code, "x", ExecuteOptions{TimeLimit: 1})
+	assert.Len(t, exec.Requests(), 1, "identical call should hit the cache")
+
+	ExecuteCode(exec, "python", code, "x", ExecuteOptions{TimeLimit: 2})
+	assert.Len(t, exec.Requests(), 2, "different limits must not share a cache entry")
+
+	ExecuteCode(exec, "python", code, "x", ExecuteOptions{TimeLimit: 1, NoCache: true})
+	assert.Len(t, exec.Requests(), 3, "NoCache must always execute")
+}
+
+// downExecutor is a backend whose runtime list can't be fetched
+type downExecutor struct {
+	*FakeExecutor
+	lookups int
+}
+
+func (d *downExecutor) Runtimes(ctx context.Context) ([]Runtime, error) {
+	d.lookups++
+	return nil, errors.New("connection refused")
+}
+
+func TestResolveVersions_RemembersFailure(t *testing.T) {
+	exec := &downExecutor{FakeExecutor: NewFakeExecutor()}
+	t.Cleanup(func() {
+		runtimeVersions.Lock()
+		delete(runtimeVersions.byExecutor, exec)
+		runtimeVersions.Unlock()
+	})
+
+	assert.Equal(t, "", resolveVersions(exec, "python"))
+	assert.Equal(t, "", resolveVersions(exec, "python"))
+	assert.Equal(t, 1, exec.lookups, "a failed lookup should be reused until the retry")
+
+	runtimeVersions.Lock()
+	snap := runtimeVersions.byExecutor[exec]
+	snap.fetchedAt = time.Now().Add(-runtimeVersionsRetry - time.Second)
+	runtimeVersions.byExecutor[exec] = snap
+	runtimeVersions.Unlock()
+
+	resolveVersions(exec, "python")
+	assert.Equal(t, 2, exec.lookups)
+}
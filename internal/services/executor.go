@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pushp314/devconnect-backend/internal/config"
+	"github.com/pushp314/devconnect-backend/pkg/logger"
+)
+
+// ErrExecutorUnavailable marks infrastructure failures (backend down, bad response)
+// as opposed to problems with the submitted code. The judge queue retries these.
+var ErrExecutorUnavailable = errors.New("execution service unavailable")
+
+// ErrExecutionRejected marks a request the backend refused as invalid (unknown language
+// or version, limits out of range). Retrying won't help; it's reported as a verdict.
+var ErrExecutionRejected = errors.New("execution rejected")
+
+// Executor is a code-execution backend (Piston, a local sandbox, or a fake in tests).
+// Handlers receive one by injection instead of calling a hard-wired service.
+type Executor interface {
+	// Execute runs a single program and returns its stage results
+	Execute(ctx context.Context, req ExecutionRequest) (*ExecutionResult, error)
+	// Runtimes lists the languages the backend can currently run
+	Runtimes(ctx context.Context) ([]Runtime, error)
+	// Health returns an error if the backend cannot accept work
+	Health(ctx context.Context) error
+}
+
+// ExecutionRequest is a backend-agnostic execution job.
+// Language is already normalized (see normalizePistonLanguage).
+type ExecutionRequest struct {
+	Language       string
+	Version        string
+	FileName       string
+	Code           string
+	Stdin          string
+	RunTimeout     int // milliseconds
+	CompileTimeout int // milliseconds
+	MemoryLimit    int // bytes, 0 = backend default
+}
+
+// StageResult is the outcome of one execution stage (compile or run)
+type StageResult struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	Code     int    `json:"code"`
+	Signal   string `json:"signal"`
+	Memory   int    `json:"memory,omitempty"`    // Peak bytes, if the backend reports it
+	WallTime int    `json:"wall_time,omitempty"` // ms, if the backend reports it
+}
+
+// ExecutionResult mirrors the Piston response shape, which is also our public API contract
+type ExecutionResult struct {
+	Language string       `json:"language"`
+	Version  string       `json:"version"`
+	Compile  *StageResult `json:"compile,omitempty"` // Only for compiled languages
+	Run      StageResult  `json:"run"`
+}
+
+// Runtime describes a language available on an executor
+type Runtime struct {
+	Language string   `json:"language"`
+	Version  string   `json:"version"`
+	Aliases  []string `json:"aliases"`
+}
+
+// NewExecutor builds the executor selected by EXECUTOR_BACKEND (piston by default)
+func NewExecutor(cfg *config.Config) Executor {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+
+	switch cfg.ExecutorBackend {
+	case "local":
+		logger.Warn().Msg("Using local process executor - code is NOT sandboxed")
+		return NewLocalExecutor()
+	case "fake":
+		return NewFakeExecutor()
+	default:
+		piston := NewPistonExecutor(cfg.PistonURL)
+		piston.MaxRunTimeout = cfg.PistonMaxRunTimeout
+		piston.MaxOutput = cfg.PistonMaxOutput
+		return piston
+	}
+}
+
+// normalizePistonLanguage converts a client language name to the backend runtime name
+// using the language registry; unknown names are passed through as-is
+func normalizePistonLanguage(lang string) string {
+	if l, ok := LookupLanguage(lang); ok {
+		return l.Runtime
+	}
+	return lang
+}
+
+// getFileExtension returns the registry file name for a language
+func getFileExtension(lang string) string {
+	if l, ok := LookupLanguage(lang); ok && l.FileName != "" {
+		return l.FileName
+	}
+	return "code.txt"
+}
+
+// ExecuteOptions are the per-call constraints for ExecuteCode
+type ExecuteOptions struct {
+	TimeLimit   float64 // seconds, 0 = language registry default
+	MemoryLimit int     // MB, 0 = language registry default (or the backend's)
+	NoCache     bool    // Always execute; set for contest judging so verdicts and runtimes are real
+}
+
+// isWebLanguage reports languages that are rendered on the client instead of executed
+func isWebLanguage(language string) bool {
+	l, ok := LookupLanguage(language)
+	return ok && l.ClientSide
+}
+
+// webPreviewResult is the mocked "success" execution for web languages
+func webPreviewResult(language string) *ExecutionResult {
+	return &ExecutionResult{
+		Language: language,
+		Version:  "web-n/a",
+		Run: StageResult{
+			Stdout: "Pre-check passed. Rendering preview on client.",
+			Stderr: "",
+			Code:   0,
+		},
+	}
+}
+
+// checkLanguageGuards rejects disabled languages and code using the language's blocked imports
+func checkLanguageGuards(language, code string) error {
+	l, ok := LookupLanguage(language)
+	if !ok {
+		return nil // Unregistered languages are left to the backend to accept or refuse
+	}
+	if !l.Enabled {
+		return fmt.Errorf("%w: %s", ErrLanguageDisabled, l.ID)
+	}
+	for _, blocked := range l.BlockedImports {
+		if blocked != "" && strings.Contains(code, blocked) {
+			return fmt.Errorf("this environment does not support %q", blocked)
+		}
+	}
+	return nil
+}
+
+// buildRequest normalizes the language and converts limits to backend units.
+// Zero limits fall back to the registry defaults for the language.
+func buildRequest(language, code, stdin string, opts ExecuteOptions) ExecutionRequest {
+	version := "*"
+	if l, ok := LookupLanguage(language); ok {
+		if l.Version != "" {
+			version = l.Version
+		}
+		if opts.TimeLimit <= 0 {
+			opts.TimeLimit = l.TimeLimit
+		}
+		if opts.MemoryLimit <= 0 {
+			opts.MemoryLimit = l.MemoryLimit
+		}
+	}
+
+	// Convert limits
+	// timeLimit is seconds (float). Backends want ms (int).
+	runTimeout := 5000 // Default 5s
+	if opts.TimeLimit > 0 {
+		runTimeout = int(opts.TimeLimit * 1000)
+	}
+
+	runMemory := 0 // 0 is omitted from JSON, so Piston uses its own safe default
+	if opts.MemoryLimit > 0 {
+		runMemory = opts.MemoryLimit * 1024 * 1024 // MB -> bytes
+	}
+
+	// Normalize language name for the backend
+	pistonLang := normalizePistonLanguage(language)
+
+	return ExecutionRequest{
+		Language:       pistonLang,
+		Version:        version,
+		FileName:       getFileExtension(pistonLang), // Use normalized lang for consistent file extension
+		Code:           code,
+		Stdin:          stdin,
+		RunTimeout:     runTimeout,
+		CompileTimeout: 10000,
+		MemoryLimit:    runMemory,
+	}
+}
+
+// ExecuteCode runs code on the given executor with optional constraints
+func ExecuteCode(exec Executor, language, code, stdin string, opts ExecuteOptions) (*ExecutionResult, error) {
+	// 1. Language Guards (disabled languages, blocked imports)
+	if err := checkLanguageGuards(language, code); err != nil {
+		return nil, err
+	}
+
+	// 2. Bypass for Web/Visual Languages
+	// These are rendered on the client, but we mock a "success" execution for correctness/storage.
+	if isWebLanguage(language) {
+		return webPreviewResult(language), nil
+	}
+
+	if exec == nil {
+		return nil, fmt.Errorf("%w: no executor configured", ErrExecutorUnavailable)
+	}
+
+	req := buildRequest(language, code, stdin, opts)
+
+	// Check cache
+	var cacheKey string
+	if !opts.NoCache {
+		cacheKey = executionCacheKey(resolveVersions(exec, req.Language), req)
+		if cached, ok := executionCache.Get(cacheKey); ok {
+			logger.Debug().Str("lang", language).Msg("Cache hit for code execution")
+			return cached, nil
+		}
+	}
+
+	result, err := exec.Execute(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Timeouts can be load-related flukes, so only deterministic outcomes are cached
+	if !opts.NoCache && result.Run.Signal != "SIGKILL" {
+		executionCache.Set(cacheKey, result)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/pkg/logger"
+)
+
+// ExecutorHealth probes the execution backend in the background so health checks can
+// report its last known status without calling out on every probe
+type ExecutorHealth struct {
+	exec     Executor
+	interval time.Duration
+
+	mu        sync.RWMutex
+	err       error
+	checkedAt time.Time
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewExecutorHealth checks exec every interval (default 15s). Until the first check
+// finishes the backend is reported healthy.
+func NewExecutorHealth(exec Executor, interval time.Duration) *ExecutorHealth {
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	return &ExecutorHealth{exec: exec, interval: interval, stop: make(chan struct{})}
+}
+
+func (h *ExecutorHealth) Start() {
+	h.wg.Add(1)
+	go h.run()
+}
+
+func (h *ExecutorHealth) Stop() {
+	close(h.stop)
+	h.wg.Wait()
+}
+
+func (h *ExecutorHealth) run() {
+	defer h.wg.Done()
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		h.Check()
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check probes the backend now and records the result
+func (h *ExecutorHealth) Check() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	err := h.exec.Health(ctx)
+	cancel()
+
+	h.mu.Lock()
+	if err != nil && h.err == nil {
+		logger.Warn().Err(err).Msg("Code execution backend is unhealthy")
+	}
+	h.err, h.checkedAt = err, time.Now()
+	h.mu.Unlock()
+	return err
+}
+
+// Status returns when the last check ran and the error it found
+func (h *ExecutorHealth) Status() (time.Time, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.checkedAt, h.err
+}
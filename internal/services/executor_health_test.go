@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyExecutor is a backend whose health can be switched off
+type flakyExecutor struct {
+	*FakeExecutor
+	probes int
+	down   bool
+}
+
+func (f *flakyExecutor) Health(ctx context.Context) error {
+	f.probes++
+	if f.down {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestExecutorHealth_ReportsLastCheck(t *testing.T) {
+	exec := &flakyExecutor{FakeExecutor: NewFakeExecutor()}
+	health := NewExecutorHealth(exec, 0)
+
+	checkedAt, err := health.Status()
+	assert.NoError(t, err, "healthy until the first check says otherwise")
+	assert.True(t, checkedAt.IsZero())
+
+	exec.down = true
+	assert.Error(t, health.Check())
+	for i := 0; i < 3; i++ {
+		_, err = health.Status()
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 1, exec.probes, "reading the status must not probe the backend")
+
+	exec.down = false
+	health.Check()
+	_, err = health.Status()
+	assert.NoError(t, err)
+}
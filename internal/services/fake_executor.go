@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeExecutor is an in-memory executor for tests and offline development.
+// By default it echoes stdin back as stdout; set Handle to script other behaviour.
+type FakeExecutor struct {
+	Handle func(req ExecutionRequest) (*ExecutionResult, error)
+
+	mu       sync.Mutex
+	requests []ExecutionRequest
+}
+
+func NewFakeExecutor() *FakeExecutor {
+	return &FakeExecutor{}
+}
+
+func (f *FakeExecutor) Execute(ctx context.Context, req ExecutionRequest) (*ExecutionResult, error) {
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	f.mu.Unlock()
+
+	if f.Handle != nil {
+		return f.Handle(req)
+	}
+	return &ExecutionResult{
+		Language: req.Language,
+		Version:  "fake",
+		Run:      StageResult{Stdout: req.Stdin},
+	}, nil
+}
+
+// Requests returns every request the fake has received so far
+func (f *FakeExecutor) Requests() []ExecutionRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]ExecutionRequest(nil), f.requests...)
+}
+
+func (f *FakeExecutor) Runtimes(ctx context.Context) ([]Runtime, error) {
+	var runtimes []Runtime
+	for name := range localLanguages {
+		runtimes = append(runtimes, Runtime{Language: name, Version: "fake"})
+	}
+	return runtimes, nil
+}
+
+func (f *FakeExecutor) Health(ctx context.Context) error {
+	return nil
+}
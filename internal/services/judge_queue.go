@@ -0,0 +1,443 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/pkg/logger"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// ErrQueueFull is returned when the judge backlog exceeds MaxQueued (back-pressure)
+var ErrQueueFull = errors.New("judge queue is full")
+
+const judgeWakeChannel = "judge:wake"
+
+// JudgeHandler processes one kind of job.
+// Judge returns an error only for infrastructure failures (e.g. ErrExecutorUnavailable);
+// the job is then retried with backoff, and GiveUp runs once MaxAttempts is reached.
+type JudgeHandler struct {
+	Judge  func(job models.JudgeJob) error
+	GiveUp func(job models.JudgeJob, err error)
+}
+
+type JudgeQueueOptions struct {
+	Workers      int           // Concurrent judges (default 4)
+	MaxAttempts  int           // Attempts before giving up (default 3)
+	MaxQueued    int64         // Back-pressure threshold (default 1000)
+	PollInterval time.Duration // Fallback polling when no wake-up arrives (default 1s)
+	Lease        time.Duration // RUNNING jobs not renewed for this long are considered abandoned (default 5m)
+	// RecoverInterval is how often orphaned work is re-queued while running (default 1m)
+	RecoverInterval time.Duration
+}
+
+// orphanGrace is how old a PENDING submission without a job must be before recovery
+// queues it, so a submission between its insert and its Enqueue isn't queued twice
+const orphanGrace = 30 * time.Second
+
+// JudgeQueue is a durable, bounded worker pool for judging submissions.
+// Jobs are stored in Postgres (judge_jobs) so they survive restarts; when Redis is
+// available it is used to wake idle workers on every replica instead of waiting for the poll.
+type JudgeQueue struct {
+	db       *gorm.DB
+	redis    *redis.Client
+	opts     JudgeQueueOptions
+	host     string // hostname:pid:random, identifies this process as a lock holder
+	handlers map[models.JudgeJobKind]JudgeHandler
+
+	wake chan struct{}
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewJudgeQueue(db *gorm.DB, rdb *redis.Client, opts JudgeQueueOptions) *JudgeQueue {
+	if opts.Workers <= 0 {
+		opts.Workers = 4
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.MaxQueued <= 0 {
+		opts.MaxQueued = 1000
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = 5 * time.Minute
+	}
+	if opts.RecoverInterval <= 0 {
+		opts.RecoverInterval = time.Minute
+	}
+
+	// The random part keeps processes apart when containers share a hostname and PID
+	host, _ := os.Hostname()
+	return &JudgeQueue{
+		db:       db,
+		redis:    rdb,
+		opts:     opts,
+		host:     fmt.Sprintf("%s:%d:%s", host, os.Getpid(), uuid.NewString()[:8]),
+		handlers: make(map[models.JudgeJobKind]JudgeHandler),
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+	}
+}
+
+// Handle registers the handler for a job kind. Must be called before Start.
+func (q *JudgeQueue) Handle(kind models.JudgeJobKind, h JudgeHandler) {
+	q.handlers[kind] = h
+}
+
+// Depth returns the number of jobs waiting to be judged
+func (q *JudgeQueue) Depth() int64 {
+	var count int64
+	q.db.Model(&models.JudgeJob{}).Where("status = ?", models.JudgeJobQueued).Count(&count)
+	return count
+}
+
+// Full reports whether new submissions should be rejected
+func (q *JudgeQueue) Full() bool {
+	return q.Depth() >= q.opts.MaxQueued
+}
+
+// Enqueue persists a job for the given submission and wakes a worker
+func (q *JudgeQueue) Enqueue(kind models.JudgeJobKind, submissionID string) error {
+	if q.Full() {
+		return ErrQueueFull
+	}
+	return q.enqueue(kind, submissionID)
+}
+
+func (q *JudgeQueue) enqueue(kind models.JudgeJobKind, submissionID string) error {
+	now := time.Now()
+	job := models.JudgeJob{
+		ID:           uuid.New().String(),
+		Kind:         kind,
+		SubmissionID: submissionID,
+		Status:       models.JudgeJobQueued,
+		RunAfter:     now,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := q.db.Create(&job).Error; err != nil {
+		return err
+	}
+	q.notify()
+	return nil
+}
+
+// notify wakes a local worker and, via Redis, workers on other replicas
+func (q *JudgeQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	if q.redis != nil {
+		// Async so a slow Redis never delays the submit request
+		go q.redis.Publish(context.Background(), judgeWakeChannel, q.host)
+	}
+}
+
+// Start recovers abandoned work and launches the worker pool
+func (q *JudgeQueue) Start() {
+	if err := q.Recover(); err != nil {
+		logger.Error().Err(err).Msg("Judge queue recovery failed")
+	}
+
+	if q.redis != nil {
+		q.wg.Add(1)
+		go q.listen()
+	}
+
+	q.wg.Add(1)
+	go q.recoverLoop()
+
+	for i := 0; i < q.opts.Workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+
+	logger.Info().Int("workers", q.opts.Workers).Msg("Judge queue started")
+}
+
+// Stop signals workers to finish their current job and waits for them (bounded by ctx)
+func (q *JudgeQueue) Stop(ctx context.Context) {
+	close(q.stop)
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logger.Warn().Msg("Judge queue stopped with jobs in flight; they will be recovered on restart")
+	}
+}
+
+// Recover makes crashed work runnable again after a restart:
+// 1. RUNNING jobs whose lease expired are re-queued. Workers renew their leases, so
+// only a dead process's jobs expire.
+// 2. Submissions still PENDING without an active job get a new job
+func (q *JudgeQueue) Recover() error {
+	return q.recoverWork()
+}
+
+// recoverLoop re-runs recovery while the queue is up, so a submission whose Enqueue
+// failed or a job whose worker vanished is judged without waiting for a restart
+func (q *JudgeQueue) recoverLoop() {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.opts.RecoverInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-ticker.C:
+			if err := q.recoverWork(); err != nil {
+				logger.Error().Err(err).Msg("Judge queue recovery failed")
+			}
+		}
+	}
+}
+
+// recoverWork re-queues abandoned work: RUNNING jobs whose lease expired and PENDING
+// submissions without an active job
+func (q *JudgeQueue) recoverWork() error {
+	now := time.Now()
+	res := q.db.Model(&models.JudgeJob{}).
+		Where("status = ? AND locked_at < ?", models.JudgeJobRunning, now.Add(-q.opts.Lease)).
+		Updates(map[string]interface{}{
+			"status":    models.JudgeJobQueued,
+			"locked_by": "",
+			"locked_at": nil,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	active := q.db.Model(&models.JudgeJob{}).Select("submission_id").
+		Where("status IN ?", []models.JudgeJobStatus{models.JudgeJobQueued, models.JudgeJobRunning})
+
+	var contestIDs []string
+	if err := q.db.Model(&models.Submission{}).
+		Where("status = ? AND created_at < ? AND id NOT IN (?)", models.SubStatusPending, now.Add(-orphanGrace), active).
+		Pluck("id", &contestIDs).Error; err != nil {
+		return err
+	}
+
+	var practiceIDs []string
+	if err := q.db.Model(&models.PracticeSubmission{}).
+		Where(`status IN ? AND "createdAt" < ? AND id NOT IN (?)`, []string{"PENDING", "RUNNING"}, now.Add(-orphanGrace), active).
+		Pluck("id", &practiceIDs).Error; err != nil {
+		return err
+	}
+
+	for _, id := range contestIDs {
+		q.enqueue(models.JudgeJobContest, id)
+	}
+	for _, id := range practiceIDs {
+		q.enqueue(models.JudgeJobPractice, id)
+	}
+
+	if res.RowsAffected > 0 || len(contestIDs) > 0 || len(practiceIDs) > 0 {
+		logger.Info().
+			Int64("requeuedJobs", res.RowsAffected).
+			Int("contestSubmissions", len(contestIDs)).
+			Int("practiceSubmissions", len(practiceIDs)).
+			Msg("Recovered pending judge work")
+	}
+	return nil
+}
+
+// listen relays Redis wake-ups from other replicas to local workers
+func (q *JudgeQueue) listen() {
+	defer q.wg.Done()
+
+	sub := q.redis.Subscribe(context.Background(), judgeWakeChannel)
+	defer sub.Close()
+	ch := sub.Channel()
+
+	for {
+		select {
+		case <-q.stop:
+			return
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+			select {
+			case q.wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (q *JudgeQueue) work() {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain everything runnable before sleeping
+		for {
+			select {
+			case <-q.stop:
+				return
+			default:
+			}
+			job, err := q.claim()
+			if err != nil {
+				logger.Error().Err(err).Msg("Judge queue claim failed")
+				break
+			}
+			if job == nil {
+				break
+			}
+			q.process(*job)
+		}
+
+		select {
+		case <-q.stop:
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim atomically moves the oldest runnable job to RUNNING.
+// The conditional UPDATE makes it safe across workers and replicas without row locks.
+func (q *JudgeQueue) claim() (*models.JudgeJob, error) {
+	for i := 0; i < 5; i++ {
+		var job models.JudgeJob
+		err := q.db.Where("status = ? AND run_after <= ?", models.JudgeJobQueued, time.Now()).
+			Order("created_at asc").First(&job).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		res := q.db.Model(&models.JudgeJob{}).
+			Where("id = ? AND status = ?", job.ID, models.JudgeJobQueued).
+			Updates(map[string]interface{}{
+				"status":     models.JudgeJobRunning,
+				"locked_by":  q.host,
+				"locked_at":  now,
+				"attempts":   gorm.Expr("attempts + 1"),
+				"updated_at": now,
+			})
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 1 {
+			job.Status = models.JudgeJobRunning
+			job.Attempts++
+			return &job, nil
+		}
+		// Another worker won the race, try the next one
+	}
+	return nil, nil
+}
+
+func (q *JudgeQueue) process(job models.JudgeJob) {
+	h, ok := q.handlers[job.Kind]
+	if !ok {
+		q.finish(job, models.JudgeJobFailed, "no handler for job kind "+string(job.Kind), time.Time{})
+		return
+	}
+
+	done := q.renewLease(job)
+	err := q.safeJudge(h, job)
+	done()
+	if err == nil {
+		q.finish(job, models.JudgeJobDone, "", time.Time{})
+		return
+	}
+
+	if job.Attempts < q.opts.MaxAttempts {
+		// Quadratic backoff: 5s, 20s, 45s...
+		backoff := time.Duration(job.Attempts*job.Attempts) * 5 * time.Second
+		logger.Warn().Err(err).Str("job", job.ID).Int("attempt", job.Attempts).Dur("retryIn", backoff).Msg("Judge job failed, retrying")
+		q.finish(job, models.JudgeJobQueued, err.Error(), time.Now().Add(backoff))
+		return
+	}
+
+	logger.Error().Err(err).Str("job", job.ID).Str("submission", job.SubmissionID).Msg("Judge job failed permanently")
+	q.finish(job, models.JudgeJobFailed, err.Error(), time.Time{})
+	if h.GiveUp != nil {
+		h.GiveUp(job, err)
+	}
+}
+
+// renewLease keeps the job's lock fresh while it is judged, so recovery on another
+// process only re-queues it if this one dies. The returned func stops renewing.
+func (q *JudgeQueue) renewLease(job models.JudgeJob) func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(q.opts.Lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				err := q.db.Model(&models.JudgeJob{}).
+					Where("id = ? AND status = ? AND locked_by = ?", job.ID, models.JudgeJobRunning, q.host).
+					Update("locked_at", time.Now()).Error
+				if err != nil {
+					logger.Warn().Err(err).Str("job", job.ID).Msg("Failed to renew judge job lease")
+				}
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
+// safeJudge converts a panicking judge into a retryable error so one bad job can't kill a worker
+func (q *JudgeQueue) safeJudge(h JudgeHandler, job models.JudgeJob) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("judge panic: %v", r)
+		}
+	}()
+	return h.Judge(job)
+}
+
+func (q *JudgeQueue) finish(job models.JudgeJob, status models.JudgeJobStatus, lastError string, runAfter time.Time) {
+	updates := map[string]interface{}{
+		"status":     status,
+		"last_error": lastError,
+		"locked_by":  "",
+		"locked_at":  nil,
+		"updated_at": time.Now(),
+	}
+	if !runAfter.IsZero() {
+		updates["run_after"] = runAfter
+	}
+	// A job whose lease lapsed may have been re-queued and claimed elsewhere; leave it be
+	if err := q.db.Model(&models.JudgeJob{}).Where("id = ? AND locked_by = ?", job.ID, q.host).Updates(updates).Error; err != nil {
+		logger.Error().Err(err).Str("job", job.ID).Msg("Failed to update judge job")
+	}
+}
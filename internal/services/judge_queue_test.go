@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupQueueDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	db.AutoMigrate(&models.JudgeJob{}, &models.Submission{}, &models.PracticeSubmission{})
+
+	// Closing the last connection discards the named in-memory DB between runs
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestJudgeQueue_RetriesInfraErrors(t *testing.T) {
+	db := setupQueueDB(t)
+	q := NewJudgeQueue(db, nil, JudgeQueueOptions{Workers: 1, PollInterval: 10 * time.Millisecond})
+
+	var calls int32
+	q.Handle(models.JudgeJobContest, JudgeHandler{
+		Judge: func(job models.JudgeJob) error {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return ErrExecutorUnavailable
+			}
+			return nil
+		},
+	})
+
+	assert.NoError(t, q.Enqueue(models.JudgeJobContest, "sub1"))
+
+	q.Start()
+	defer q.Stop(t.Context())
+
+	assert.Eventually(t, func() bool {
+		// Retry is scheduled in the future; pull it forward once it has been requeued
+		db.Model(&models.JudgeJob{}).Where("status = ?", models.JudgeJobQueued).Update("run_after", time.Now())
+		var job models.JudgeJob
+		db.First(&job, "submission_id = ?", "sub1")
+		return job.Status == models.JudgeJobDone
+	}, 2*time.Second, 20*time.Millisecond)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestJudgeQueue_GivesUpAfterMaxAttempts(t *testing.T) {
+	db := setupQueueDB(t)
+	q := NewJudgeQueue(db, nil, JudgeQueueOptions{Workers: 1, MaxAttempts: 1, PollInterval: 10 * time.Millisecond})
+
+	gaveUp := make(chan string, 1)
+	q.Handle(models.JudgeJobContest, JudgeHandler{
+		Judge: func(job models.JudgeJob) error { return errors.New("boom") },
+		GiveUp: func(job models.JudgeJob, err error) {
+			gaveUp <- job.SubmissionID
+		},
+	})
+
+	assert.NoError(t, q.Enqueue(models.JudgeJobContest, "sub2"))
+	q.Start()
+	defer q.Stop(t.Context())
+
+	select {
+	case id := <-gaveUp:
+		assert.Equal(t, "sub2", id)
+	case <-time.After(2 * time.Second):
+		t.Fatal("GiveUp was not called")
+	}
+}
+
+func TestJudgeQueue_RecoversPendingSubmissions(t *testing.T) {
+	db := setupQueueDB(t)
+
+	// Simulate a crash: one PENDING submission with no job, one job whose worker stopped
+	// renewing its lease, and one still held by a live process on the same host
+	now := time.Now()
+	expired := now.Add(-6 * time.Minute)
+	db.Create(&models.Submission{ID: "orphan", Status: models.SubStatusPending, CreatedAt: now.Add(-time.Minute)})
+	db.Create(&models.Submission{ID: "enqueuing", Status: models.SubStatusPending, CreatedAt: now}) // Its handler is about to Enqueue
+	q := NewJudgeQueue(db, nil, JudgeQueueOptions{Workers: 1})
+	db.Create(&models.JudgeJob{ID: "stuck", Kind: models.JudgeJobPractice, SubmissionID: "p1",
+		Status: models.JudgeJobRunning, LockedBy: "dead:1:aaaa", LockedAt: &expired})
+	live := NewJudgeQueue(db, nil, JudgeQueueOptions{Workers: 1})
+	db.Create(&models.JudgeJob{ID: "held", Kind: models.JudgeJobPractice, SubmissionID: "p2",
+		Status: models.JudgeJobRunning, LockedBy: live.host, LockedAt: &now})
+
+	assert.NoError(t, q.Recover())
+
+	var orphanJobs int64
+	db.Model(&models.JudgeJob{}).Where("submission_id = ? AND status = ?", "orphan", models.JudgeJobQueued).Count(&orphanJobs)
+	assert.Equal(t, int64(1), orphanJobs)
+	var fresh int64
+	db.Model(&models.JudgeJob{}).Where("submission_id = ?", "enqueuing").Count(&fresh)
+	assert.Zero(t, fresh)
+
+	var stuck models.JudgeJob
+	db.First(&stuck, "id = ?", "stuck")
+	assert.Equal(t, models.JudgeJobQueued, stuck.Status)
+	var held models.JudgeJob
+	db.First(&held, "id = ?", "held")
+	assert.Equal(t, models.JudgeJobRunning, held.Status, "another process on this host still owns it")
+	assert.NotEqual(t, q.host, live.host)
+
+	// Running recovery again must not duplicate jobs
+	assert.NoError(t, q.Recover())
+	db.Model(&models.JudgeJob{}).Where("submission_id = ?", "orphan").Count(&orphanJobs)
+	assert.Equal(t, int64(1), orphanJobs)
+
+	// A job whose lease lapsed and was re-queued isn't overwritten by its old worker
+	q.finish(models.JudgeJob{ID: "stuck"}, models.JudgeJobDone, "", time.Time{})
+	db.First(&stuck, "id = ?", "stuck")
+	assert.Equal(t, models.JudgeJobQueued, stuck.Status)
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// ErrLanguageDisabled is returned for registry languages an admin has switched off
+var ErrLanguageDisabled = errors.New("language is disabled")
+
+// languageRefreshInterval bounds how stale another replica's admin edits can be here
+const languageRefreshInterval = 1 * time.Minute
+
+// DefaultLanguages is the registry seed, matching what the executor supported before
+// languages were stored in the database
+func DefaultLanguages() []models.Language {
+	heavyDataLibs := pq.StringArray{"import pandas", "import numpy", "from pandas", "from numpy"}
+	return []models.Language{
+		{ID: "typescript", DisplayName: "TypeScript", Runtime: "typescript", FileName: "index.ts"},
+		{ID: "javascript", DisplayName: "JavaScript", Runtime: "javascript", Aliases: pq.StringArray{"node"}, FileName: "index.js"},
+		{ID: "python", DisplayName: "Python", Runtime: "python", Aliases: pq.StringArray{"python3"}, FileName: "main.py", BlockedImports: heavyDataLibs},
+		{ID: "go", DisplayName: "Go", Runtime: "go", Aliases: pq.StringArray{"golang"}, FileName: "main.go"},
+		{ID: "cpp", DisplayName: "C++", Runtime: "c++", Aliases: pq.StringArray{"c++"}, FileName: "main.cpp"},
+		{ID: "java", DisplayName: "Java", Runtime: "java", FileName: "Main.java"},
+		{ID: "rust", DisplayName: "Rust", Runtime: "rust", FileName: "main.rs"},
+		{ID: "c", DisplayName: "C", Runtime: "c", FileName: "main.c"},
+		{ID: "php", DisplayName: "PHP", Runtime: "php", FileName: "index.php"},
+		{ID: "ruby", DisplayName: "Ruby", Runtime: "ruby", FileName: "main.rb"},
+
+		// Rendered on the client
+		{ID: "html", DisplayName: "HTML", Runtime: "html", FileName: "index.html", ClientSide: true},
+		{ID: "react", DisplayName: "React", Runtime: "react", FileName: "App.jsx", ClientSide: true},
+		{ID: "markdown", DisplayName: "Markdown", Runtime: "markdown", FileName: "README.md", ClientSide: true},
+		{ID: "mermaid", DisplayName: "Mermaid", Runtime: "mermaid", FileName: "diagram.mmd", ClientSide: true},
+	}
+}
+
+// languageRegistry is the in-memory copy of the languages table.
+// It starts with the defaults so execution works before (or without) a database.
+var languageRegistry = struct {
+	sync.RWMutex
+	db       *gorm.DB
+	loadedAt time.Time
+	list     []models.Language
+	byName   map[string]*models.Language // ID, runtime and aliases
+}{}
+
+func init() {
+	setLanguages(withRegistryDefaults(DefaultLanguages()))
+}
+
+// withRegistryDefaults fills the values the table defaults would, for in-memory seeds
+func withRegistryDefaults(langs []models.Language) []models.Language {
+	for i := range langs {
+		if langs[i].Version == "" {
+			langs[i].Version = "*"
+		}
+		if langs[i].TimeLimit == 0 {
+			langs[i].TimeLimit = 5
+		}
+		langs[i].Enabled = true
+	}
+	return langs
+}
+
+func setLanguages(langs []models.Language) {
+	sort.Slice(langs, func(i, j int) bool { return langs[i].ID < langs[j].ID })
+
+	byName := make(map[string]*models.Language, len(langs)*2)
+	for i := range langs {
+		byName[strings.ToLower(langs[i].ID)] = &langs[i]
+	}
+	// IDs win over runtime names and aliases when they collide
+	for i := range langs {
+		names := append([]string{langs[i].Runtime}, langs[i].Aliases...)
+		for _, name := range names {
+			name = strings.ToLower(name)
+			if _, taken := byName[name]; !taken && name != "" {
+				byName[name] = &langs[i]
+			}
+		}
+	}
+
+	languageRegistry.Lock()
+	languageRegistry.list = langs
+	languageRegistry.byName = byName
+	languageRegistry.loadedAt = time.Now()
+	languageRegistry.Unlock()
+}
+
+// LoadLanguages seeds the languages table on first boot and loads it into the registry.
+// Later lookups re-read the table every languageRefreshInterval.
+func LoadLanguages(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&models.Language{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		if err := db.Create(withRegistryDefaults(DefaultLanguages())).Error; err != nil {
+			return err
+		}
+	}
+
+	languageRegistry.Lock()
+	languageRegistry.db = db
+	languageRegistry.Unlock()
+	return ReloadLanguages()
+}
+
+// ReloadLanguages re-reads the languages table; admin handlers call it after edits
+func ReloadLanguages() error {
+	languageRegistry.RLock()
+	db := languageRegistry.db
+	languageRegistry.RUnlock()
+	if db == nil {
+		return nil
+	}
+
+	var langs []models.Language
+	if err := db.Find(&langs).Error; err != nil {
+		return err
+	}
+	setLanguages(langs)
+	return nil
+}
+
+func refreshLanguagesIfStale() {
+	languageRegistry.RLock()
+	stale := languageRegistry.db != nil && time.Since(languageRegistry.loadedAt) > languageRefreshInterval
+	languageRegistry.RUnlock()
+	if !stale {
+		return
+	}
+	if err := ReloadLanguages(); err != nil {
+		logger.Warn().Err(err).Msg("Failed to refresh language registry, keeping previous copy")
+		languageRegistry.Lock()
+		languageRegistry.loadedAt = time.Now() // Don't retry on every lookup
+		languageRegistry.Unlock()
+	}
+}
+
+// LookupLanguage resolves a client name, backend runtime name or alias (case-insensitive)
+func LookupLanguage(name string) (*models.Language, bool) {
+	refreshLanguagesIfStale()
+
+	languageRegistry.RLock()
+	defer languageRegistry.RUnlock()
+	lang, ok := languageRegistry.byName[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, false
+	}
+	copied := *lang
+	return &copied, true
+}
+
+// ListLanguages returns every registry entry, enabled or not, sorted by ID
+func ListLanguages() []models.Language {
+	refreshLanguagesIfStale()
+
+	languageRegistry.RLock()
+	defer languageRegistry.RUnlock()
+	out := make([]models.Language, len(languageRegistry.list))
+	copy(out, languageRegistry.list)
+	return out
+}
+
+// CanonicalLanguage maps any accepted spelling to the registry ID ("c++" -> "cpp").
+// Unknown names are returned lower-cased so they can still be compared.
+func CanonicalLanguage(name string) string {
+	if lang, ok := LookupLanguage(name); ok {
+		return lang.ID
+	}
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// ValidateLanguage checks that name is a known, enabled language
+func ValidateLanguage(name string) error {
+	lang, ok := LookupLanguage(name)
+	if !ok {
+		return fmt.Errorf("unknown language %q", name)
+	}
+	if !lang.Enabled {
+		return fmt.Errorf("%w: %s", ErrLanguageDisabled, lang.ID)
+	}
+	return nil
+}
+
+// InstalledVersions lists the versions the executor currently has for a language
+// (client name or runtime), using the same memoized runtime list as the execution cache
+func InstalledVersions(exec Executor, language string) []string {
+	if exec == nil {
+		return nil
+	}
+	versions := resolveVersions(exec, normalizePistonLanguage(language))
+	if versions == "" {
+		return nil
+	}
+	return strings.Split(versions, ",")
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// useLanguages swaps the registry for the duration of a test
+func useLanguages(t *testing.T, langs []models.Language) {
+	prev := ListLanguages()
+	setLanguages(langs)
+	t.Cleanup(func() { setLanguages(prev) })
+}
+
+func TestLookupLanguage_AliasesAndRuntimeNames(t *testing.T) {
+	for _, name := range []string{"cpp", "c++", "CPP"} {
+		lang, ok := LookupLanguage(name)
+		if assert.True(t, ok, name) {
+			assert.Equal(t, "cpp", lang.ID)
+		}
+	}
+	assert.Equal(t, "javascript", CanonicalLanguage("node"))
+	assert.Equal(t, "c++", normalizePistonLanguage("cpp"))
+	assert.Equal(t, "main.cpp", getFileExtension("c++"))
+	assert.Equal(t, "code.txt", getFileExtension("brainfuck"))
+	assert.True(t, isWebLanguage("mermaid"))
+}
+
+func TestBuildRequest_UsesRegistryDefaults(t *testing.T) {
+	langs := withRegistryDefaults(DefaultLanguages())
+	for i := range langs {
+		if langs[i].ID == "python" {
+			langs[i].Version = "3.10.0"
+			langs[i].TimeLimit = 3
+			langs[i].MemoryLimit = 256
+		}
+	}
+	useLanguages(t, langs)
+
+	req := buildRequest("python3", "print(1)", "", ExecuteOptions{})
+	assert.Equal(t, "python", req.Language)
+	assert.Equal(t, "3.10.0", req.Version)
+	assert.Equal(t, 3000, req.RunTimeout)
+	assert.Equal(t, 256*1024*1024, req.MemoryLimit)
+
+	// Explicit limits win over the defaults
+	req = buildRequest("python", "print(1)", "", ExecuteOptions{TimeLimit: 1, MemoryLimit: 64})
+	assert.Equal(t, 1000, req.RunTimeout)
+	assert.Equal(t, 64*1024*1024, req.MemoryLimit)
+}
+
+func TestExecuteCode_LanguageGuards(t *testing.T) {
+	langs := withRegistryDefaults(DefaultLanguages())
+	for i := range langs {
+		if langs[i].ID == "ruby" {
+			langs[i].Enabled = false
+		}
+	}
+	useLanguages(t, langs)
+	exec := NewFakeExecutor()
+
+	_, err := ExecuteCode(exec, "ruby", "puts 1", "", ExecuteOptions{NoCache: true})
+	assert.True(t, errors.Is(err, ErrLanguageDisabled))
+
+	_, err = ExecuteCode(exec, "python", "import numpy as np", "", ExecuteOptions{NoCache: true})
+	assert.Error(t, err)
+	assert.Empty(t, exec.Requests())
+}
+
+func TestLoadLanguages_SeedsOnce(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:languages?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Language{}))
+
+	prev := ListLanguages()
+	t.Cleanup(func() {
+		languageRegistry.Lock()
+		languageRegistry.db = nil
+		languageRegistry.Unlock()
+		setLanguages(prev)
+	})
+
+	require.NoError(t, LoadLanguages(db))
+	var count int64
+	db.Model(&models.Language{}).Count(&count)
+	assert.Equal(t, int64(len(DefaultLanguages())), count)
+
+	// Admin edits are picked up on reload, and a second load doesn't re-seed
+	db.Model(&models.Language{}).Where("id = ?", "go").Update("enabled", false)
+	require.NoError(t, LoadLanguages(db))
+	db.Model(&models.Language{}).Count(&count)
+	assert.Equal(t, int64(len(DefaultLanguages())), count)
+	assert.ErrorIs(t, ValidateLanguage("golang"), ErrLanguageDisabled)
+
+	lang, ok := LookupLanguage("python")
+	require.True(t, ok)
+	assert.Equal(t, []string{"python3"}, []string(lang.Aliases))
+	assert.Len(t, lang.BlockedImports, 4)
+}
@@ -1,7 +1,10 @@
 package services
 
 import (
+	"math"
+	"reflect"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,7 +14,9 @@ import (
 
 type LeaderboardEntry struct {
 	Rank         int                    `json:"rank"`
-	UserID       string                 `json:"userId"`
+	UserID       string                 `json:"userId"` // Row key: the team's ID in team events
+	TeamID       string                 `json:"teamId,omitempty"`
+	Virtual      bool                   `json:"virtual,omitempty"` // Ghost leaderboards: the virtual participant's row
 	Username     string                 `json:"username"`
 	Name         string                 `json:"name"`
 	Avatar       string                 `json:"avatar"`
@@ -26,10 +31,13 @@ type LeaderboardEntry struct {
 }
 
 type ProblemStat struct {
-	Status    string  `json:"status"` // AC, WA, PENDING
+	Status    string  `json:"status"` // AC, PARTIAL (IOI), WA, PENDING
 	Attempts  int     `json:"attempts"`
+	Score     int     `json:"score"`     // Points earned under the event's scoring mode
 	TimeTaken float64 `json:"timeTaken"` // Minutes (including penalty)
 	Penalty   int     `json:"penalty"`
+	Memory    int     `json:"memory,omitempty"`  // Peak KB of the accepted submission
+	Pending   int     `json:"pending,omitempty"` // Submissions hidden by the freeze (public view only)
 }
 
 // In-memory cache: EventID -> {Entries, Expiry}
@@ -72,58 +80,104 @@ func GetLeaderboard(eventID string, asAdmin bool) ([]LeaderboardEntry, error) {
 		return nil, err
 	}
 
-	// Freeze Check
+	// Upsolving after the end never counts (the judge doesn't score it either)
 	cutoffTime := time.Now()
-	// If not admin, and frozen, set cutoff to FreezeTime
-	if !asAdmin && event.FreezeTime != nil && time.Now().After(*event.FreezeTime) {
-		cutoffTime = *event.FreezeTime
+	if event.ID != "practice-arena-mvp" && !event.EndTime.IsZero() && cutoffTime.After(event.EndTime) {
+		cutoffTime = event.EndTime
+	}
+	// Freeze Check: the public view only scores what was submitted before FreezeTime,
+	// until the resolver has unfrozen the board
+	var leaderboard []LeaderboardEntry
+	if !asAdmin && event.FreezeTime != nil && event.UnfrozenAt == nil && cutoffTime.After(*event.FreezeTime) {
+		visible, hidden, err := frozenSubmissions(event, cutoffTime)
+		if err != nil {
+			return nil, err
+		}
+		// Cells the resolver already revealed stay revealed
+		_, leaderboard = Resolve(event, visible, hidden, event.ResolverStep)
+	} else {
+		// Fetch all submissions for this event up to cutoff
+		var submissions []models.Submission
+		if err := database.DB.Preload("User").Preload("Team").Preload("Flags").
+			Where("event_id = ? AND created_at <= ? AND virtual_id IS NULL", eventID, cutoffTime).
+			Order("created_at asc"). // Process chronological
+			Find(&submissions).Error; err != nil {
+			return nil, err
+		}
+		leaderboard = ComputeStandings(event, submissions)
 	}
 
-	// Fetch all submissions for this event up to cutoff
-	var submissions []models.Submission
-	if err := database.DB.Preload("User").Preload("Flags").
-		Where("event_id = ? AND created_at <= ?", eventID, cutoffTime).
-		Order("created_at asc"). // Process chronological
-		Find(&submissions).Error; err != nil {
-		return nil, err
+	// 3. Cache (Only cache if public view, or if frozen it is static anyway)
+	if !asAdmin {
+		lbMutex.Lock()
+		leaderboardCache[eventID] = cachedLeaderboard{
+			Entries:   leaderboard,
+			ExpiresAt: time.Now().Add(lbTTL),
+		}
+		lbMutex.Unlock()
 	}
 
-	// Map to aggregated stats
-	// UserID -> Entry
-	userMap := make(map[string]*LeaderboardEntry)
+	return leaderboard, nil
+}
+
+// Codeforces-style decay: a problem loses MaxPoints/cfDecayDivisor per minute and
+// cfWrongPenalty per rejected attempt, but never drops below cfMinFraction of its value
+const (
+	cfDecayDivisor = 250
+	cfWrongPenalty = 50
+	cfMinFraction  = 0.3
+)
+
+// CodeforcesPoints is the score for solving a problem worth maxPoints after the given
+// minutes with wrong rejected attempts before the accepted one
+func CodeforcesPoints(maxPoints int, minutes float64, wrong int) int {
+	if maxPoints <= 0 {
+		return 0
+	}
+	decayed := float64(maxPoints) - float64(maxPoints)/cfDecayDivisor*math.Floor(minutes) - float64(cfWrongPenalty*wrong)
+	floor := float64(maxPoints) * cfMinFraction
+	return int(math.Round(math.Max(decayed, floor)))
+}
 
-	// Pre-fill registered users? Or just those who submitted?
-	// Usually leaderboard shows only those who submitted.
+// ComputeStandings ranks submissions (chronological, with User and Flags preloaded)
+// under the event's scoring mode. event.Problems must be loaded.
+func ComputeStandings(event models.Event, submissions []models.Submission) []LeaderboardEntry {
+	mode := event.ScoringMode
+	if !mode.Valid() {
+		mode = models.ScoringICPC
+	}
 
-	// Helper to get penalty for problem
+	// Helper to get penalty and points for a problem
 	problemPenalty := make(map[string]int)
+	problemPoints := make(map[string]int)
 	for _, p := range event.Problems {
 		penalty := p.Penalty
 		if penalty == 0 {
 			penalty = 10 // Default
 		}
 		problemPenalty[p.ID] = penalty
+		problemPoints[p.ID] = p.Points
 	}
 
+	// Map to aggregated stats
+	// Participant (user, or team in team events) -> Entry
+	userMap := make(map[string]*LeaderboardEntry)
+
 	for _, sub := range submissions {
-		if userMap[sub.UserID] == nil {
-			status := "NORMAL"
-			// Check user trust/flags
-			if sub.User.TrustScore < 50 {
-				status = "UNDER_REVIEW"
-			}
-			userMap[sub.UserID] = &LeaderboardEntry{
-				UserID:     sub.UserID,
-				Username:   sub.User.Username,
-				Name:       sub.User.Name,
-				Avatar:     sub.User.Image,
-				TrustScore: sub.User.TrustScore,
-				Problems:   make(map[string]ProblemStat),
-				Status:     status,
-			}
+		key := participantKey(sub)
+		if userMap[key] == nil {
+			entry := newLeaderboardEntry(sub)
+			userMap[key] = &entry
 		}
 
-		entry := userMap[sub.UserID]
+		entry := userMap[key]
+		if entry.TeamID != "" && sub.User.TrustScore < entry.TrustScore {
+			// A team is as trusted as its least trusted member
+			entry.TrustScore = sub.User.TrustScore
+			if entry.TrustScore < 50 && entry.Status == "NORMAL" {
+				entry.Status = "UNDER_REVIEW"
+			}
+		}
 
 		// Anti-Cheat: If user has unresolved flags, mark under review
 		if len(sub.Flags) > 0 {
@@ -141,37 +195,66 @@ func GetLeaderboard(eventID string, asAdmin bool) ([]LeaderboardEntry, error) {
 			continue
 		}
 
-		probStat.Attempts++
+		// Judge failures aren't the participant's doing, so they're left out entirely
+		if strings.HasPrefix(sub.Verdict, models.JudgeErrorVerdict) {
+			continue
+		}
+		// Compilation errors show on the board but, as in ICPC, cost no penalty
+		if sub.Status != models.SubStatusCE {
+			probStat.Attempts++
+		}
 
-		if sub.Status == models.SubStatusAC {
-			probStat.Status = string(models.SubStatusAC)
+		// Calculate Time: (SubmitTime - StartTime) in minutes
+		submissionTime := sub.CreatedAt.Sub(event.StartTime).Minutes()
+		if submissionTime < 0 {
+			submissionTime = 0
+		}
 
-			// Calculate Time: (SubmitTime - StartTime) in minutes
-			submissionTime := sub.CreatedAt.Sub(event.StartTime).Minutes()
-			if submissionTime < 0 {
-				submissionTime = 0
+		if mode == models.ScoringIOI {
+			// Best score counts; time is when it was reached, no penalty
+			score := sub.Score
+			if sub.Status == models.SubStatusAC && score == 0 {
+				score = problemPoints[sub.ProblemID] // Judged before partial scoring
+			}
+			if score > probStat.Score {
+				entry.TotalScore += score - probStat.Score
+				entry.TotalTime += submissionTime - probStat.TimeTaken
+				entry.LastSubmitAt = sub.CreatedAt
+				probStat.Score = score
+				probStat.TimeTaken = submissionTime
+				probStat.Memory = sub.Memory
+			}
+			switch {
+			case sub.Status == models.SubStatusAC:
+				probStat.Status = string(models.SubStatusAC)
+				entry.SolvedCount++
+			case probStat.Score > 0:
+				probStat.Status = "PARTIAL"
+			default:
+				probStat.Status = string(sub.Status)
 			}
+			entry.Problems[sub.ProblemID] = probStat
+			continue
+		}
+
+		if sub.Status == models.SubStatusAC {
+			probStat.Status = string(models.SubStatusAC)
 
 			// Calculate Penalty Time
 			penaltyMinutes := float64((probStat.Attempts - 1) * problemPenalty[sub.ProblemID])
 
 			probStat.TimeTaken = submissionTime + penaltyMinutes
 			probStat.Penalty = int(penaltyMinutes)
+			probStat.Memory = sub.Memory
+
+			points := problemPoints[sub.ProblemID]
+			if mode == models.ScoringCodeforces {
+				points = CodeforcesPoints(points, submissionTime, probStat.Attempts-1)
+			}
+			probStat.Score = points
 
 			// Update User Totals
 			entry.SolvedCount++
-
-			// Points? Need to look up problem points.
-			// Ideally we fetch problems and map ID to Points.
-			// For MVP efficiency let's do a lookup or include points in problemPenalty map struct
-			// Let's assume we have it. Re-looping event.Problems is fast.
-			points := 0
-			for _, p := range event.Problems {
-				if p.ID == sub.ProblemID {
-					points = p.Points
-					break
-				}
-			}
 			entry.TotalScore += points
 			entry.TotalTime += probStat.TimeTaken
 			entry.LastSubmitAt = sub.CreatedAt
@@ -184,28 +267,30 @@ func GetLeaderboard(eventID string, asAdmin bool) ([]LeaderboardEntry, error) {
 		entry.Problems[sub.ProblemID] = probStat
 	}
 
-	// Convers map to slice
-	var leaderboard []LeaderboardEntry
+	// Convert map to slice
+	leaderboard := make([]LeaderboardEntry, 0, len(userMap))
 	for _, entry := range userMap {
 		leaderboard = append(leaderboard, *entry)
 	}
 
-	// 3. Sort
 	sort.Slice(leaderboard, func(i, j int) bool {
-		// 1. Solved Count DESC
-		if leaderboard[i].SolvedCount != leaderboard[j].SolvedCount {
-			return leaderboard[i].SolvedCount > leaderboard[j].SolvedCount
+		a, b := leaderboard[i], leaderboard[j]
+		if mode == models.ScoringICPC {
+			// 1. Solved Count DESC
+			if a.SolvedCount != b.SolvedCount {
+				return a.SolvedCount > b.SolvedCount
+			}
 		}
-		// 2. Total Score DESC
-		if leaderboard[i].TotalScore != leaderboard[j].TotalScore {
-			return leaderboard[i].TotalScore > leaderboard[j].TotalScore
+		// Total Score DESC (primary for IOI and Codeforces)
+		if a.TotalScore != b.TotalScore {
+			return a.TotalScore > b.TotalScore
 		}
-		// 3. Total Time ASC
-		if leaderboard[i].TotalTime != leaderboard[j].TotalTime {
-			return leaderboard[i].TotalTime < leaderboard[j].TotalTime
+		// Total Time ASC
+		if a.TotalTime != b.TotalTime {
+			return a.TotalTime < b.TotalTime
 		}
-		// 4. Last Submit Time ASC (Earliest best submission wins tie)
-		return leaderboard[i].LastSubmitAt.Before(leaderboard[j].LastSubmitAt)
+		// Last Submit Time ASC (Earliest best submission wins tie)
+		return a.LastSubmitAt.Before(b.LastSubmitAt)
 	})
 
 	// Assign Ranks
@@ -213,15 +298,129 @@ func GetLeaderboard(eventID string, asAdmin bool) ([]LeaderboardEntry, error) {
 		leaderboard[i].Rank = i + 1
 	}
 
-	// 4. Cache (Only cache if public view, or if frozen it is static anyway)
-	if !asAdmin {
-		lbMutex.Lock()
-		leaderboardCache[eventID] = cachedLeaderboard{
-			Entries:   leaderboard,
-			ExpiresAt: time.Now().Add(lbTTL),
+	return leaderboard
+}
+
+// participantKey is who a submission counts for: its team in team events, else its user
+func participantKey(sub models.Submission) string {
+	if sub.TeamID != nil && *sub.TeamID != "" {
+		return *sub.TeamID
+	}
+	return sub.UserID
+}
+
+// newLeaderboardEntry starts an empty row for a submission's participant (User and,
+// for team submissions, Team preloaded)
+func newLeaderboardEntry(sub models.Submission) LeaderboardEntry {
+	status := "NORMAL"
+	// Check user trust/flags
+	if sub.User.TrustScore < 50 {
+		status = "UNDER_REVIEW"
+	}
+	entry := LeaderboardEntry{
+		UserID:     sub.UserID,
+		Username:   sub.User.Username,
+		Name:       sub.User.Name,
+		Avatar:     sub.User.Image,
+		TrustScore: sub.User.TrustScore,
+		Problems:   make(map[string]ProblemStat),
+		Status:     status,
+	}
+	if key := participantKey(sub); key != sub.UserID {
+		entry.UserID, entry.TeamID = key, key
+		entry.Username, entry.Name, entry.Avatar = "", "", ""
+		if sub.Team != nil {
+			entry.Name = sub.Team.Name
 		}
-		lbMutex.Unlock()
 	}
+	return entry
+}
 
-	return leaderboard, nil
+// markFrozen shows submissions made during the freeze as PENDING cells without revealing
+// their verdicts. Cells already accepted before the freeze are left as they were.
+func markFrozen(standings []LeaderboardEntry, hidden []models.Submission) []LeaderboardEntry {
+	index := make(map[string]int, len(standings))
+	for i, entry := range standings {
+		index[entry.UserID] = i
+	}
+
+	for _, sub := range hidden {
+		key := participantKey(sub)
+		i, ok := index[key]
+		if !ok {
+			// First submission after the freeze: the row appears, unranked by anything hidden
+			entry := newLeaderboardEntry(sub)
+			entry.Rank = len(standings) + 1
+			standings = append(standings, entry)
+			i = len(standings) - 1
+			index[key] = i
+		}
+
+		stat := standings[i].Problems[sub.ProblemID]
+		if stat.Status == string(models.SubStatusAC) {
+			continue
+		}
+		stat.Status = string(models.SubStatusPending)
+		stat.Pending++
+		standings[i].Problems[sub.ProblemID] = stat
+	}
+	return standings
+}
+
+// DiffStandings returns what a client holding prev needs to catch up to next: the rows
+// that are new or changed (rank shifts included) and the row keys (user or team IDs)
+// that are gone, e.g. after a ban
+func DiffStandings(prev, next []LeaderboardEntry) (changed []LeaderboardEntry, removed []string) {
+	before := make(map[string]LeaderboardEntry, len(prev))
+	for _, entry := range prev {
+		before[entry.UserID] = entry
+	}
+
+	changed = []LeaderboardEntry{}
+	for _, entry := range next {
+		old, ok := before[entry.UserID]
+		delete(before, entry.UserID)
+		if ok && reflect.DeepEqual(old, entry) {
+			continue
+		}
+		changed = append(changed, entry)
+	}
+
+	removed = []string{}
+	for _, entry := range prev {
+		if _, ok := before[entry.UserID]; ok {
+			removed = append(removed, entry.UserID)
+		}
+	}
+	return changed, removed
+}
+
+// ParticipantScore is a user's (or, given a teamID, a team's) current contest score under
+// the event's scoring mode, counting only submissions made before the end. It backs
+// Registration.Score.
+func ParticipantScore(eventID, userID string, teamID *string) (int, error) {
+	var event models.Event
+	if err := database.DB.Preload("Problems").First(&event, "id = ?", eventID).Error; err != nil {
+		return 0, err
+	}
+
+	query := database.DB.Where("event_id = ? AND virtual_id IS NULL", eventID)
+	if teamID != nil {
+		query = query.Where("team_id = ?", *teamID)
+	} else {
+		query = query.Where("user_id = ?", userID)
+	}
+	if event.ID != "practice-arena-mvp" && !event.EndTime.IsZero() {
+		query = query.Where("created_at <= ?", event.EndTime)
+	}
+	var submissions []models.Submission
+	if err := query.Order("created_at asc").Find(&submissions).Error; err != nil {
+		return 0, err
+	}
+
+	standings := ComputeStandings(event, submissions)
+	if len(standings) == 0 {
+		return 0, nil
+	}
+	return standings[0].TotalScore, nil
 }
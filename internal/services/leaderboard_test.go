@@ -0,0 +1,182 @@
+package services
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func standingsFixture(mode models.ScoringMode) (models.Event, []models.Submission) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	event := models.Event{
+		ID:          "ev",
+		StartTime:   start,
+		EndTime:     start.Add(2 * time.Hour),
+		ScoringMode: mode,
+		Problems: []models.Problem{
+			{ID: "A", Points: 500},
+			{ID: "B", Points: 1000},
+		},
+	}
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	subs := []models.Submission{
+		// alice: A at 10 min; B partially (40) at 30, fully at 100
+		{UserID: "alice", ProblemID: "A", Status: models.SubStatusAC, Score: 500, CreatedAt: at(10)},
+		{UserID: "alice", ProblemID: "B", Status: models.SubStatusWA, Score: 400, CreatedAt: at(30)},
+		{UserID: "alice", ProblemID: "B", Status: models.SubStatusAC, Score: 1000, CreatedAt: at(100)},
+		// bob: B first try at 20, A never solved but partial 450
+		{UserID: "bob", ProblemID: "B", Status: models.SubStatusAC, Score: 1000, CreatedAt: at(20)},
+		{UserID: "bob", ProblemID: "A", Status: models.SubStatusWA, Score: 450, CreatedAt: at(25)},
+	}
+	return event, subs
+}
+
+func TestComputeStandings_ICPC(t *testing.T) {
+	event, subs := standingsFixture(models.ScoringICPC)
+	board := ComputeStandings(event, subs)
+
+	// alice solves two problems, bob one
+	assert.Equal(t, "alice", board[0].UserID)
+	assert.Equal(t, 2, board[0].SolvedCount)
+	assert.Equal(t, 1500, board[0].TotalScore)
+	assert.Equal(t, 10.0+100+10, board[0].TotalTime, "one wrong attempt on B costs the default 10 minute penalty")
+	assert.Equal(t, 1000, board[1].TotalScore)
+}
+
+func TestComputeStandings_IOI(t *testing.T) {
+	event, subs := standingsFixture(models.ScoringIOI)
+	board := ComputeStandings(event, subs)
+
+	assert.Equal(t, "alice", board[0].UserID)
+	assert.Equal(t, 1500, board[0].TotalScore)
+	assert.Equal(t, "bob", board[1].UserID)
+	assert.Equal(t, 1450, board[1].TotalScore)
+	assert.Equal(t, "PARTIAL", board[1].Problems["A"].Status)
+	assert.Equal(t, 450, board[1].Problems["A"].Score)
+	assert.Equal(t, 1, board[1].SolvedCount)
+}
+
+func TestComputeStandings_Codeforces(t *testing.T) {
+	event, subs := standingsFixture(models.ScoringCodeforces)
+	board := ComputeStandings(event, subs)
+
+	// alice: A = 500-2*10 = 480, B = 1000-4*100-50 = 550 -> 1030; bob: B = 1000-4*20 = 920
+	assert.Equal(t, "alice", board[0].UserID)
+	assert.Equal(t, 1030, board[0].TotalScore)
+	assert.Equal(t, 920, board[1].TotalScore)
+}
+
+func TestComputeStandings_Teams(t *testing.T) {
+	event, subs := standingsFixture(models.ScoringICPC)
+	team := "team-1"
+	for i := range subs {
+		// alice and bob play as one team
+		subs[i].TeamID = &team
+		subs[i].User = models.User{TrustScore: 100}
+	}
+	subs[3].User.TrustScore = 40
+	sort.SliceStable(subs, func(i, j int) bool { return subs[i].CreatedAt.Before(subs[j].CreatedAt) })
+
+	board := ComputeStandings(event, subs)
+	require.Len(t, board, 1)
+	assert.Equal(t, team, board[0].UserID)
+	assert.Equal(t, team, board[0].TeamID)
+	assert.Equal(t, 2, board[0].SolvedCount)
+	assert.Equal(t, 1500, board[0].TotalScore)
+	assert.Equal(t, 10.0+20, board[0].TotalTime, "attempts after a teammate's solve don't count")
+	assert.Equal(t, 40, board[0].TrustScore, "a team is as trusted as its least trusted member")
+	assert.Equal(t, "UNDER_REVIEW", board[0].Status)
+}
+
+func TestCodeforcesPoints(t *testing.T) {
+	assert.Equal(t, 1000, CodeforcesPoints(1000, 0, 0))
+	assert.Equal(t, 952, CodeforcesPoints(1000, 12.9, 0), "decay is per whole minute")
+	assert.Equal(t, 300, CodeforcesPoints(1000, 500, 3), "never below 30%")
+	assert.Equal(t, 0, CodeforcesPoints(0, 5, 0))
+}
+
+func TestGetLeaderboard_FrozenShowsPending(t *testing.T) {
+	db := setupTestDB(t)
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	freeze := start.Add(30 * time.Minute)
+	db.Create(&models.Event{ID: "frz", Slug: "frz", Status: models.EventStatusFrozen,
+		StartTime: start, FreezeTime: &freeze, EndTime: start.Add(2 * time.Hour)})
+	db.Create(&models.Problem{ID: "frz-a", EventID: "frz", Points: 100})
+	db.Create(&models.Problem{ID: "frz-b", EventID: "frz", Points: 100})
+	for _, id := range []string{"alice", "bob"} {
+		db.Create(&models.User{ID: id, Username: id, Email: id + "@example.com", TrustScore: 100})
+	}
+	db.Create(&models.Submission{ID: "frz-1", UserID: "alice", EventID: "frz", ProblemID: "frz-a",
+		Status: models.SubStatusAC, Score: 100, CreatedAt: start.Add(10 * time.Minute)})
+	db.Create(&models.Submission{ID: "frz-2", UserID: "alice", EventID: "frz", ProblemID: "frz-b",
+		Status: models.SubStatusAC, Score: 100, CreatedAt: freeze.Add(5 * time.Minute)})
+	db.Create(&models.Submission{ID: "frz-3", UserID: "bob", EventID: "frz", ProblemID: "frz-a",
+		Status: models.SubStatusWA, CreatedAt: freeze.Add(6 * time.Minute)})
+	InvalidateLeaderboardCache("frz")
+
+	public, err := GetLeaderboard("frz", false)
+	require.NoError(t, err)
+	require.Len(t, public, 2, "bob appears although his only submission is frozen")
+	assert.Equal(t, 100, public[0].TotalScore, "B's verdict stays hidden")
+	assert.Equal(t, "PENDING", public[0].Problems["frz-b"].Status)
+	assert.Equal(t, 1, public[0].Problems["frz-b"].Pending)
+	assert.Equal(t, "bob", public[1].UserID)
+	assert.Equal(t, "PENDING", public[1].Problems["frz-a"].Status)
+
+	admin, err := GetLeaderboard("frz", true)
+	require.NoError(t, err)
+	assert.Equal(t, 200, admin[0].TotalScore)
+	assert.Equal(t, string(models.SubStatusWA), admin[1].Problems["frz-a"].Status)
+}
+
+func TestDiffStandings(t *testing.T) {
+	event, subs := standingsFixture(models.ScoringICPC)
+	before := ComputeStandings(event, subs[:4])
+	after := ComputeStandings(event, subs)
+
+	changed, removed := DiffStandings(nil, after)
+	assert.Len(t, changed, 2, "no previous board sends everything")
+	assert.Empty(t, removed)
+	changed, removed = DiffStandings(after, after)
+	assert.Empty(t, changed)
+	assert.Empty(t, removed)
+
+	// bob's new WA on A only changes his row
+	changed, _ = DiffStandings(before, after)
+	if assert.Len(t, changed, 1) {
+		assert.Equal(t, "bob", changed[0].UserID)
+	}
+
+	// A banned participant's row disappears
+	changed, removed = DiffStandings(after, after[1:])
+	assert.Equal(t, []string{after[0].UserID}, removed)
+	assert.Empty(t, changed)
+}
+
+func TestComputeStandings_SkipsCompileAndJudgeErrors(t *testing.T) {
+	event, subs := standingsFixture(models.ScoringICPC)
+	at := func(minutes int) time.Time { return event.StartTime.Add(time.Duration(minutes) * time.Minute) }
+	// carol: a compile error and a judge failure before solving A at 15
+	subs = append(subs,
+		models.Submission{UserID: "carol", ProblemID: "A", Status: models.SubStatusCE, CreatedAt: at(5)},
+		models.Submission{UserID: "carol", ProblemID: "A", Status: models.SubStatusRE, Verdict: models.JudgeErrorVerdict + "executor unavailable", CreatedAt: at(8)},
+		models.Submission{UserID: "carol", ProblemID: "A", Status: models.SubStatusAC, Score: 500, CreatedAt: at(15)},
+	)
+	sort.SliceStable(subs, func(i, j int) bool { return subs[i].CreatedAt.Before(subs[j].CreatedAt) })
+
+	board := ComputeStandings(event, subs)
+	var carol LeaderboardEntry
+	for _, entry := range board {
+		if entry.UserID == "carol" {
+			carol = entry
+		}
+	}
+	require.Equal(t, 1, carol.SolvedCount)
+	assert.Equal(t, 1, carol.Problems["A"].Attempts)
+	assert.Equal(t, 15.0, carol.TotalTime, "neither failure costs a penalty")
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// localLanguage describes how to compile and run one language with host toolchains
+type localLanguage struct {
+	Binary  string   // Looked up on PATH to decide availability
+	Compile []string // Optional compile command, run inside the work dir
+	Run     []string // Run command, run inside the work dir
+}
+
+var localLanguages = map[string]localLanguage{
+	"python":     {Binary: "python3", Run: []string{"python3", "main.py"}},
+	"javascript": {Binary: "node", Run: []string{"node", "index.js"}},
+	"go":         {Binary: "go", Compile: []string{"go", "build", "-o", "main", "main.go"}, Run: []string{"./main"}},
+	"c++":        {Binary: "g++", Compile: []string{"g++", "-O2", "-o", "main", "main.cpp"}, Run: []string{"./main"}},
+	"c":          {Binary: "gcc", Compile: []string{"gcc", "-O2", "-o", "main", "main.c"}, Run: []string{"./main"}},
+	"java":       {Binary: "javac", Compile: []string{"javac", "Main.java"}, Run: []string{"java", "Main"}},
+	"rust":       {Binary: "rustc", Compile: []string{"rustc", "-O", "-o", "main", "main.rs"}, Run: []string{"./main"}},
+	"php":        {Binary: "php", Run: []string{"php", "index.php"}},
+	"ruby":       {Binary: "ruby", Run: []string{"ruby", "main.rb"}},
+}
+
+// LocalExecutor runs code as child processes of the server.
+// It is intended for offline judging and development only: there is no sandbox,
+// and memory limits are not capped but enforced after the fact from the reported peak RSS.
+type LocalExecutor struct {
+	WorkDir string // Parent directory for per-run temp dirs ("" = os.TempDir)
+}
+
+func NewLocalExecutor() *LocalExecutor {
+	return &LocalExecutor{}
+}
+
+// Execute writes the source to a temp dir, compiles it if needed and runs it with stdin
+func (l *LocalExecutor) Execute(ctx context.Context, req ExecutionRequest) (*ExecutionResult, error) {
+	prog, err := l.Compile(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer prog.Close()
+	return prog.Run(ctx, req.Stdin)
+}
+
+// Compile builds the source once so it can be run against every test case
+func (l *LocalExecutor) Compile(ctx context.Context, req ExecutionRequest) (CompiledProgram, error) {
+	lang, ok := localLanguages[req.Language]
+	if !ok {
+		return nil, fmt.Errorf("language %s is not supported by the local executor", req.Language)
+	}
+	if _, err := exec.LookPath(lang.Binary); err != nil {
+		return nil, fmt.Errorf("%w: %s not installed", ErrExecutorUnavailable, lang.Binary)
+	}
+
+	dir, err := os.MkdirTemp(l.WorkDir, "exec-")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, req.FileName), []byte(req.Code), 0o600); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	prog := &localProgram{dir: dir, lang: lang, req: req}
+	if len(lang.Compile) > 0 {
+		compile := runLocal(ctx, dir, lang.Compile, "", req.CompileTimeout)
+		prog.compile = &compile
+	}
+	return prog, nil
+}
+
+// localProgram is a compiled program living in its temp dir until Close
+type localProgram struct {
+	dir     string
+	lang    localLanguage
+	req     ExecutionRequest
+	compile *StageResult
+}
+
+func (p *localProgram) Compile() *StageResult {
+	return p.compile
+}
+
+func (p *localProgram) Run(ctx context.Context, stdin string) (*ExecutionResult, error) {
+	result := &ExecutionResult{Language: p.req.Language, Version: "local", Compile: p.compile}
+	if p.compile != nil && (p.compile.Code != 0 || p.compile.Signal != "") {
+		// Like Piston, a failed compile has no run stage
+		return result, nil
+	}
+	result.Run = runLocal(ctx, p.dir, p.lang.Run, stdin, p.req.RunTimeout)
+	return result, nil
+}
+
+func (p *localProgram) Close() error {
+	return os.RemoveAll(p.dir)
+}
+
+// runLocal executes one command with a timeout, reporting a timeout as SIGKILL like Piston
+func runLocal(ctx context.Context, dir string, argv []string, stdin string, timeoutMs int) StageResult {
+	if timeoutMs <= 0 {
+		timeoutMs = 5000
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	res := StageResult{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Memory:   peakMemory(cmd.ProcessState),
+		WallTime: int(time.Since(start).Milliseconds()),
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		res.Code = 137
+		res.Signal = "SIGKILL"
+		return res
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		res.Code = exitErr.ExitCode()
+	} else if err != nil {
+		res.Code = 1
+		res.Stderr += err.Error()
+	}
+	return res
+}
+
+// Runtimes lists languages whose toolchain is installed on this host
+func (l *LocalExecutor) Runtimes(ctx context.Context) ([]Runtime, error) {
+	var runtimes []Runtime
+	for name, lang := range localLanguages {
+		if _, err := exec.LookPath(lang.Binary); err == nil {
+			runtimes = append(runtimes, Runtime{Language: name, Version: "local"})
+		}
+	}
+	return runtimes, nil
+}
+
+// Health fails if no toolchain at all is available
+func (l *LocalExecutor) Health(ctx context.Context) error {
+	runtimes, _ := l.Runtimes(ctx)
+	if len(runtimes) == 0 {
+		return fmt.Errorf("no local runtimes installed")
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build linux
+
+package services
+
+import (
+	"os"
+	"syscall"
+)
+
+// peakMemory returns the peak resident set size of a finished process in bytes
+func peakMemory(state *os.ProcessState) int {
+	if state == nil {
+		return 0
+	}
+	if usage, ok := state.SysUsage().(*syscall.Rusage); ok {
+		return int(usage.Maxrss) * 1024 // Linux reports KB
+	}
+	return 0
+}
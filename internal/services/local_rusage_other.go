@@ -0,0 +1,10 @@
+//go:build !linux
+
+package services
+
+import "os"
+
+// peakMemory is not measured outside Linux; memory limits are then left to the backend
+func peakMemory(state *os.ProcessState) int {
+	return 0
+}
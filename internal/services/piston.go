@@ -2,13 +2,13 @@ package services
 
 import (
 	"bytes"
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
-	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pushp314/devconnect-backend/pkg/logger"
@@ -30,172 +30,49 @@ type File struct {
 	Content string `json:"content"`
 }
 
-type PistonExecuteResponse struct {
-	Language string `json:"language"`
-	Version  string `json:"version"`
-	Run      struct {
-		Stdout string `json:"stdout"`
-		Stderr string `json:"stderr"`
-		Code   int    `json:"code"`
-		Signal string `json:"signal"`
-	} `json:"run"`
-}
-
-const PistonAPIURL = "https://emkc.org/api/v2/piston/execute"
-
-// Cache implementation
-type cacheEntry struct {
-	Response  *PistonExecuteResponse
-	Timestamp time.Time
-}
+// DefaultPistonURL is the public emkc.org instance, used when PISTON_URL is unset
+const DefaultPistonURL = "https://emkc.org/api/v2/piston"
 
-var (
-	executionCache = make(map[string]cacheEntry)
-	cacheMutex     sync.RWMutex
-	cacheTTL       = 1 * time.Hour
-)
+// PistonExecutor talks to a Piston API (public or self-hosted)
+type PistonExecutor struct {
+	BaseURL string
+	Client  *http.Client
 
-func init() {
-	// Cleanup routine
-	go func() {
-		for {
-			time.Sleep(10 * time.Minute)
-			cacheMutex.Lock()
-			for key, entry := range executionCache {
-				if time.Since(entry.Timestamp) > cacheTTL {
-					delete(executionCache, key)
-				}
-			}
-			cacheMutex.Unlock()
-		}
-	}()
-}
+	// The server's run_timeout (ms) and output_max_size (bytes) limits, which bound how
+	// many test inputs fit in one batched request (see ExecuteBatch).
+	// 0 = one time limit per batch and Piston's default 1024 bytes of output.
+	MaxRunTimeout int
+	MaxOutput     int
 
-func getCacheKey(language, code, stdin string) string {
-	hash := sha256.Sum256([]byte(language + ":" + code + ":" + stdin))
-	return hex.EncodeToString(hash[:])
+	batchOff atomic.Bool // The server refused a batch's run_timeout
 }
 
-// normalizePistonLanguage converts frontend language names to Piston-compatible names
-func normalizePistonLanguage(lang string) string {
-	// Map of frontend language names to Piston language names
-	langMap := map[string]string{
-		"typescript": "typescript",
-		"javascript": "javascript",
-		"node":       "javascript",
-		"python":     "python",
-		"python3":    "python",
-		"go":         "go",
-		"golang":     "go",
-		"cpp":        "c++",
-		"c++":        "c++",
-		"java":       "java",
-		"rust":       "rust",
-		"c":          "c",
-		"php":        "php",
-		"ruby":       "ruby",
+// NewPistonExecutor creates a Piston-backed executor. baseURL is the API root, e.g. http://piston:2000/api/v2
+func NewPistonExecutor(baseURL string) *PistonExecutor {
+	if baseURL == "" {
+		baseURL = DefaultPistonURL
 	}
-
-	if pistonLang, ok := langMap[lang]; ok {
-		return pistonLang
+	return &PistonExecutor{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Client:  &http.Client{Timeout: 30 * time.Second},
 	}
-	// Return as-is if not in map
-	return lang
 }
 
-// getFileExtension returns the appropriate file extension for a language
-func getFileExtension(lang string) string {
-	extMap := map[string]string{
-		"typescript": "index.ts",
-		"javascript": "index.js",
-		"python":     "main.py",
-		"go":         "main.go",
-		"c++":        "main.cpp",
-		"cpp":        "main.cpp",
-		"java":       "Main.java",
-		"rust":       "main.rs",
-		"c":          "main.c",
-		"php":        "index.php",
-		"ruby":       "main.rb",
-	}
-
-	if ext, ok := extMap[lang]; ok {
-		return ext
-	}
-	return "code.txt"
+// Execute runs code via Piston
+func (p *PistonExecutor) Execute(ctx context.Context, req ExecutionRequest) (*ExecutionResult, error) {
+	return p.execute(ctx, req, []File{{Name: req.FileName, Content: req.Code}})
 }
 
-// ExecuteCode runs code via Piston with optional constraints
-func ExecuteCode(language, code, stdin string, timeLimit float64, memoryLimit int) (*PistonExecuteResponse, error) {
-	// 1. Bypass for Web/Visual Languages
-	// These are rendered on the client, but we mock a "success" execution for correctness/storage.
-	if language == "html" || language == "react" || language == "markdown" || language == "mermaid" {
-		return &PistonExecuteResponse{
-			Language: language,
-			Version:  "web-n/a",
-			Run: struct {
-				Stdout string `json:"stdout"`
-				Stderr string `json:"stderr"`
-				Code   int    `json:"code"`
-				Signal string `json:"signal"`
-			}{
-				Stdout: "Pre-check passed. Rendering preview on client.",
-				Stderr: "",
-				Code:   0,
-			},
-		}, nil
-	}
-
-	// 2. Language Guards (MVP Restrictions)
-	if language == "python" {
-		if strings.Contains(code, "import pandas") || strings.Contains(code, "import numpy") ||
-			strings.Contains(code, "from pandas") || strings.Contains(code, "from numpy") {
-			return nil, fmt.Errorf("this environment does not support heavy data libraries")
-		}
-	}
-
-	// Check cache
-	cacheKey := getCacheKey(language, code, stdin)
-	cacheMutex.RLock()
-	if entry, ok := executionCache[cacheKey]; ok {
-		// If using cache, we assume standard limits or that limits don't change result enough to invalidate in this context?
-		// For contest safety, maybe skip cache? Or include limits in key?
-		// Let's keep cache for now but it's risky if limits change.
-		if time.Since(entry.Timestamp) < cacheTTL {
-			cacheMutex.RUnlock()
-			logger.Debug().Str("lang", language).Msg("Cache hit for code execution")
-			return entry.Response, nil
-		}
-	}
-	cacheMutex.RUnlock()
-
-	version := "*"
-
-	// Convert limits
-	// timeLimit is seconds (float). Piston wants ms (int).
-	runTimeout := 5000 // Default 5s
-	if timeLimit > 0 {
-		runTimeout = int(timeLimit * 1000)
-	}
-
-	runMemory := 0 // Set to 0 to omit from JSON (Piston will use its own safe defaults)
-
-	// Normalize language name for Piston API
-	pistonLang := normalizePistonLanguage(language)
-	fileName := getFileExtension(pistonLang) // Use normalized lang for consistent file extension
-	workingCode := code
-
+func (p *PistonExecutor) execute(ctx context.Context, req ExecutionRequest, files []File) (*ExecutionResult, error) {
 	// Build request
 	reqBody := PistonExecuteRequest{
-		Language: pistonLang,
-		Version:  version,
-		Files: []File{
-			{Name: fileName, Content: workingCode},
-		},
-		Stdin:          stdin,
-		RunTimeout:     runTimeout,
-		CompileTimeout: 10000,
-		RunMemoryLimit: runMemory,
+		Language:       req.Language,
+		Version:        req.Version,
+		Files:          files,
+		Stdin:          req.Stdin,
+		RunTimeout:     req.RunTimeout,
+		CompileTimeout: req.CompileTimeout,
+		RunMemoryLimit: req.MemoryLimit,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -203,44 +80,89 @@ func ExecuteCode(language, code, stdin string, timeLimit float64, memoryLimit in
 		return nil, err
 	}
 
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.BaseURL+"/execute", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
 	start := time.Now()
-	resp, err := http.Post(PistonAPIURL, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := p.Client.Do(httpReq)
 	if err != nil {
-		logger.Error().Err(err).Str("lang", language).Msg("Failed to connect to Piston API")
-		return nil, fmt.Errorf("execution service unavailable: %v", err)
+		logger.Error().Err(err).Str("lang", req.Language).Msg("Failed to connect to Piston API")
+		return nil, fmt.Errorf("%w: %v", ErrExecutorUnavailable, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		// Try to read error body for more details
-		body := make([]byte, 1024)
-		n, _ := resp.Body.Read(body)
-		logger.Error().
-			Str("lang", language).
+		err := pistonError(resp)
+		logger.Error().Err(err).
+			Str("lang", req.Language).
 			Int("status", resp.StatusCode).
-			Str("body", string(body[:n])).
 			Msg("Piston API returned error")
-		return nil, fmt.Errorf("piston api failed with status: %d", resp.StatusCode)
+		return nil, err
 	}
 
-	var result PistonExecuteResponse
+	var result ExecutionResult
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		logger.Error().Err(err).Str("lang", language).Msg("Failed to decode Piston response")
-		return nil, fmt.Errorf("failed to parse execution result: %v", err)
+		logger.Error().Err(err).Str("lang", req.Language).Msg("Failed to decode Piston response")
+		return nil, fmt.Errorf("%w: failed to parse execution result: %v", ErrExecutorUnavailable, err)
 	}
 
 	logger.Info().
-		Str("lang", language).
+		Str("lang", req.Language).
 		Dur("latency", time.Since(start)).
 		Msg("Executed code via Piston")
 
-	// Save to cache
-	cacheMutex.Lock()
-	executionCache[cacheKey] = cacheEntry{
-		Response:  &result,
-		Timestamp: time.Now(),
+	return &result, nil
+}
+
+// Runtimes lists the runtimes installed on the Piston instance
+func (p *PistonExecutor) Runtimes(ctx context.Context) ([]Runtime, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.BaseURL+"/runtimes", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExecutorUnavailable, err)
 	}
-	cacheMutex.Unlock()
+	defer resp.Body.Close()
 
-	return &result, nil
+	if resp.StatusCode != http.StatusOK {
+		return nil, pistonError(resp)
+	}
+
+	var runtimes []Runtime
+	if err := json.NewDecoder(resp.Body).Decode(&runtimes); err != nil {
+		return nil, fmt.Errorf("failed to parse runtimes: %v", err)
+	}
+	return runtimes, nil
+}
+
+// pistonError classifies a non-200 response: Piston answers 400 with a message for
+// requests it won't run (ErrExecutionRejected); anything else, including rate limiting,
+// is the service being unavailable
+func pistonError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	var parsed struct {
+		Message string `json:"message"`
+	}
+	message := strings.TrimSpace(string(body))
+	if json.Unmarshal(body, &parsed) == nil && parsed.Message != "" {
+		message = parsed.Message
+	}
+
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %s", ErrExecutionRejected, message)
+	}
+	return fmt.Errorf("%w: piston api failed with status: %d", ErrExecutorUnavailable, resp.StatusCode)
+}
+
+// Health checks that the Piston API is reachable
+func (p *PistonExecutor) Health(ctx context.Context) error {
+	_, err := p.Runtimes(ctx)
+	return err
 }
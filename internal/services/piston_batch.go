@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pushp314/devconnect-backend/pkg/logger"
+)
+
+// pistonBatchHarness is linked into batched C and C++ submissions; it runs every input
+// in one request by forking the compiled program once per input
+//
+//go:embed piston_harness/batch.c
+var pistonBatchHarness string
+
+// pistonBatchFiles names the harness for the runtimes whose Piston compile step builds
+// every file of the request together (gcc compiles *.c / *.cpp)
+var pistonBatchFiles = map[string]string{
+	"c":   "batch.c",
+	"c++": "batch.cpp",
+}
+
+const (
+	// pistonBatchSlack is the run time kept back for the harness itself (ms)
+	pistonBatchSlack = 250
+	// pistonDefaultOutput is Piston's default output_max_size (bytes)
+	pistonDefaultOutput = 1024
+	// pistonBatchInput caps the stdin sent in one batch (bytes)
+	pistonBatchInput = 1 << 20
+)
+
+// CanBatch reports whether req's runtime has a batch harness
+func (p *PistonExecutor) CanBatch(req ExecutionRequest) bool {
+	_, ok := pistonBatchFiles[req.Language]
+	return ok && !p.batchOff.Load()
+}
+
+// ExecuteBatch compiles req once and runs it on as many of stdins as fit in the
+// server's time and output limits, each run with req's own time limit
+func (p *PistonExecutor) ExecuteBatch(ctx context.Context, req ExecutionRequest, stdins []string) (*ExecutionResult, []StageResult, error) {
+	runTimeout := req.RunTimeout + pistonBatchSlack
+	if p.MaxRunTimeout > runTimeout {
+		runTimeout = p.MaxRunTimeout
+	}
+	maxOutput := p.MaxOutput
+	if maxOutput <= 0 {
+		maxOutput = pistonDefaultOutput
+	}
+
+	size := 0
+	for i, stdin := range stdins {
+		if size += len(stdin); i > 0 && size > pistonBatchInput {
+			stdins = stdins[:i]
+			break
+		}
+	}
+	var input strings.Builder
+	fmt.Fprintf(&input, "%d %d %d %d\n", req.RunTimeout, runTimeout-pistonBatchSlack, maxOutput, len(stdins))
+	for _, stdin := range stdins {
+		fmt.Fprintf(&input, "%d\n%s", len(stdin), stdin)
+	}
+
+	batch := req
+	batch.Stdin = input.String()
+	batch.RunTimeout = runTimeout
+	res, err := p.execute(ctx, batch, []File{
+		{Name: req.FileName, Content: req.Code},
+		{Name: pistonBatchFiles[req.Language], Content: pistonBatchHarness},
+	})
+	if errors.Is(err, ErrExecutionRejected) && strings.Contains(err.Error(), "run_timeout") {
+		p.batchOff.Store(true)
+		logger.Warn().Err(err).Int("run_timeout", runTimeout).
+			Msg("Piston refused a batched run, judging one test per request; set PISTON_MAX_RUN_TIMEOUT to the server's run_timeout")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if stageFailed(res.Compile) {
+		return res, nil, nil
+	}
+	return res, parseBatchRuns(res.Run.Stdout), nil
+}
+
+// parseBatchRuns decodes the harness's run records, stopping at the first incomplete
+// one (Piston cuts output off at output_max_size)
+func parseBatchRuns(out string) []StageResult {
+	var runs []StageResult
+	for strings.HasPrefix(out, "@@run ") {
+		nl := strings.IndexByte(out, '\n')
+		if nl < 0 {
+			break
+		}
+		var code, signal, wall, peakKB, outLen, errLen int
+		if _, err := fmt.Sscanf(out[:nl], "@@run %d %d %d %d %d %d", &code, &signal, &wall, &peakKB, &outLen, &errLen); err != nil {
+			break
+		}
+		body := out[nl+1:]
+		if len(body) < outLen+errLen {
+			break
+		}
+		stdout, err := base64.StdEncoding.DecodeString(body[:outLen])
+		if err != nil {
+			break
+		}
+		stderr, err := base64.StdEncoding.DecodeString(body[outLen : outLen+errLen])
+		if err != nil {
+			break
+		}
+		runs = append(runs, StageResult{
+			Stdout:   string(stdout),
+			Stderr:   string(stderr),
+			Code:     code,
+			Signal:   signalName(signal),
+			Memory:   peakKB * 1024,
+			WallTime: wall,
+		})
+		out = body[outLen+errLen:]
+	}
+	return runs
+}
+
+func signalName(signal int) string {
+	if signal == 0 {
+		return ""
+	}
+	if name, ok := signalNames[signal]; ok {
+		return name
+	}
+	return fmt.Sprintf("SIG%d", signal)
+}
+
+// signalNames maps Linux signal numbers to the names Piston reports
+var signalNames = map[int]string{
+	1: "SIGHUP", 2: "SIGINT", 3: "SIGQUIT", 4: "SIGILL", 5: "SIGTRAP", 6: "SIGABRT",
+	7: "SIGBUS", 8: "SIGFPE", 9: "SIGKILL", 11: "SIGSEGV", 13: "SIGPIPE", 14: "SIGALRM",
+	15: "SIGTERM", 24: "SIGXCPU", 25: "SIGXFSZ", 31: "SIGSYS",
+}
@@ -0,0 +1,185 @@
+// Batch harness for C and C++ submissions on Piston (see piston_batch.go).
+//
+// Piston compiles every file of a request together, so this file is linked into the
+// submission. Its constructor runs before main: it reads the batch from stdin, forks
+// once per input with stdin/stdout/stderr redirected, and lets each child return into
+// the submission's main. Results are written as one record per finished run:
+//
+//   @@run <exit code> <signal> <wall ms> <peak KB> <stdout b64 len> <stderr b64 len>\n
+//   <stdout base64><stderr base64>
+//
+// Input: "<limit ms> <budget ms> <output budget> <count>\n" then "<len>\n<bytes>" per run.
+// A run that would overflow the output budget, or that the time budget cut short, isn't
+// reported; the caller sends it again.
+#ifndef _GNU_SOURCE
+#define _GNU_SOURCE
+#endif
+#include <fcntl.h>
+#include <signal.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include <sys/resource.h>
+#include <sys/stat.h>
+#include <sys/types.h>
+#include <sys/wait.h>
+#include <time.h>
+#include <unistd.h>
+
+static long bh_now_ms(void) {
+    struct timespec ts;
+    clock_gettime(CLOCK_MONOTONIC, &ts);
+    return ts.tv_sec * 1000L + ts.tv_nsec / 1000000L;
+}
+
+static char *bh_read_all(int fd, size_t *len) {
+    size_t cap = 1 << 16, n = 0;
+    char *buf = (char *)malloc(cap + 1);
+    for (;;) {
+        if (n == cap) {
+            cap *= 2;
+            buf = (char *)realloc(buf, cap + 1);
+        }
+        ssize_t r = read(fd, buf + n, cap - n);
+        if (r <= 0) {
+            break;
+        }
+        n += (size_t)r;
+    }
+    buf[n] = 0;
+    *len = n;
+    return buf;
+}
+
+static void bh_write_all(int fd, const char *buf, size_t len) {
+    while (len > 0) {
+        ssize_t w = write(fd, buf, len);
+        if (w <= 0) {
+            return;
+        }
+        buf += w;
+        len -= (size_t)w;
+    }
+}
+
+static char *bh_read_file(const char *path, size_t *len) {
+    int fd = open(path, O_RDONLY);
+    if (fd < 0) {
+        *len = 0;
+        return (char *)calloc(1, 1);
+    }
+    char *buf = bh_read_all(fd, len);
+    close(fd);
+    return buf;
+}
+
+static char *bh_base64(const char *src, size_t len, size_t *out_len) {
+    static const char table[] = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/";
+    char *out = (char *)malloc(4 * ((len + 2) / 3) + 1);
+    size_t o = 0;
+    for (size_t i = 0; i < len; i += 3) {
+        unsigned v = (unsigned char)src[i] << 16;
+        if (i + 1 < len) v |= (unsigned char)src[i + 1] << 8;
+        if (i + 2 < len) v |= (unsigned char)src[i + 2];
+        out[o++] = table[(v >> 18) & 63];
+        out[o++] = table[(v >> 12) & 63];
+        out[o++] = i + 1 < len ? table[(v >> 6) & 63] : '=';
+        out[o++] = i + 2 < len ? table[v & 63] : '=';
+    }
+    out[o] = 0;
+    *out_len = o;
+    return out;
+}
+
+__attribute__((constructor(101))) static void bh_run_batch(void) {
+    size_t in_len, pos = 0;
+    char *in = bh_read_all(0, &in_len);
+    long limit, budget, out_budget;
+    int count, n;
+    if (sscanf(in, "%ld %ld %ld %d\n%n", &limit, &budget, &out_budget, &count, &n) < 4) {
+        _exit(70);
+    }
+    pos = (size_t)n;
+
+    long start = bh_now_ms();
+    long written = 0;
+    for (int i = 0; i < count; i++) {
+        long len;
+        if (sscanf(in + pos, "%ld\n%n", &len, &n) < 1 || pos + n + len > in_len) {
+            _exit(71);
+        }
+        const char *data = in + pos + n;
+        pos += (size_t)n + (size_t)len;
+
+        long remaining = budget - (bh_now_ms() - start);
+        if (i > 0 && remaining <= 0) {
+            break;
+        }
+        long deadline = limit < remaining || i == 0 ? limit : remaining;
+
+        int fd = open(".batch_in", O_WRONLY | O_CREAT | O_TRUNC, 0600);
+        bh_write_all(fd, data, (size_t)len);
+        close(fd);
+
+        pid_t pid = fork();
+        if (pid < 0) {
+            _exit(72);
+        }
+        if (pid == 0) {
+            // The child becomes one plain run of the submission
+            dup2(open(".batch_in", O_RDONLY), 0);
+            dup2(open(".batch_out", O_WRONLY | O_CREAT | O_TRUNC, 0600), 1);
+            dup2(open(".batch_err", O_WRONLY | O_CREAT | O_TRUNC, 0600), 2);
+            free(in);
+            return;
+        }
+
+        long began = bh_now_ms();
+        int status = 0, cut = 0;
+        struct rusage usage;
+        memset(&usage, 0, sizeof usage);
+        for (;;) {
+            if (wait4(pid, &status, WNOHANG, &usage) == pid) {
+                break;
+            }
+            if (bh_now_ms() - began > deadline) {
+                kill(pid, SIGKILL);
+                wait4(pid, &status, 0, &usage);
+                cut = deadline < limit; // Out of budget, not over the limit: rerun it
+                break;
+            }
+            struct timespec pause = {0, 1000000};
+            nanosleep(&pause, NULL);
+        }
+        long wall = bh_now_ms() - began;
+        if (cut) {
+            break;
+        }
+
+        size_t out_len, err_len, out64_len, err64_len;
+        char *out = bh_read_file(".batch_out", &out_len);
+        char *err = bh_read_file(".batch_err", &err_len);
+        char *out64 = bh_base64(out, out_len, &out64_len);
+        char *err64 = bh_base64(err, err_len, &err64_len);
+
+        char header[160];
+        int header_len = snprintf(header, sizeof header, "@@run %d %d %ld %ld %zu %zu\n",
+                                  WIFEXITED(status) ? WEXITSTATUS(status) : 0,
+                                  WIFSIGNALED(status) ? WTERMSIG(status) : 0,
+                                  wall, (long)usage.ru_maxrss, out64_len, err64_len);
+        long record = header_len + (long)out64_len + (long)err64_len;
+        if (i > 0 && written + record > out_budget) {
+            break;
+        }
+        bh_write_all(1, header, (size_t)header_len);
+        bh_write_all(1, out64, out64_len);
+        bh_write_all(1, err64, err64_len);
+        written += record;
+
+        free(out);
+        free(err);
+        free(out64);
+        free(err64);
+    }
+    _exit(0);
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPistonExecutor_ClassifiesErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{"unknown runtime", http.StatusBadRequest, `{"message":"rust-9.9.9 runtime is unknown"}`, ErrExecutionRejected},
+		{"rate limited", http.StatusTooManyRequests, `{"message":"Requests limited to 5 per second"}`, ErrExecutorUnavailable},
+		{"server error", http.StatusInternalServerError, ``, ErrExecutorUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := NewPistonExecutor(srv.URL).Execute(context.Background(), ExecutionRequest{Language: "rust", Version: "9.9.9"})
+			assert.ErrorIs(t, err, tt.want)
+			if tt.want == ErrExecutionRejected {
+				assert.Contains(t, err.Error(), "runtime is unknown")
+			}
+		})
+	}
+}
+
+func TestPistonExecutor_ExecuteBatch(t *testing.T) {
+	var got PistonExecuteRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		// Two runs, then a record Piston cut off at its output limit
+		json.NewEncoder(w).Encode(ExecutionResult{Language: "c++", Version: "10.2.0", Compile: &StageResult{}, Run: StageResult{
+			Stdout: "@@run 0 0 12 2048 4 0\nMwo=@@run 0 9 2001 1024 0 8\nS2lsbGVk@@run 0 0 3 2048 8 0\nNw",
+		}})
+	}))
+	defer srv.Close()
+
+	p := NewPistonExecutor(srv.URL)
+	p.MaxRunTimeout = 10000
+	req := buildRequest("cpp", "int main() {}", "", ExecuteOptions{TimeLimit: 2})
+	require.True(t, p.CanBatch(req))
+	assert.False(t, p.CanBatch(buildRequest("python", "print(1)", "", ExecuteOptions{})), "only gcc runtimes have a harness")
+
+	base, runs, err := p.ExecuteBatch(context.Background(), req, []string{"1 2", "3 4", "5 6"})
+	require.NoError(t, err)
+	assert.Equal(t, "10.2.0", base.Version)
+	assert.Len(t, got.Files, 2, "the harness is compiled with the submission")
+	assert.Equal(t, 10000, got.RunTimeout)
+	assert.Equal(t, "2000 9750 1024 3\n3\n1 23\n3 43\n5 6", got.Stdin)
+
+	require.Len(t, runs, 2)
+	assert.Equal(t, StageResult{Stdout: "3\n", Memory: 2048 * 1024, WallTime: 12}, runs[0])
+	assert.Equal(t, "SIGKILL", runs[1].Signal)
+	assert.Equal(t, "Killed", runs[1].Stderr)
+}
+
+func TestPistonExecutor_BatchRefusedRunTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"run_timeout cannot exceed the configured limit of 3000"}`))
+	}))
+	defer srv.Close()
+
+	p := NewPistonExecutor(srv.URL)
+	req := buildRequest("c", "int main() {}", "", ExecuteOptions{TimeLimit: 3})
+	_, _, err := p.ExecuteBatch(context.Background(), req, []string{"1"})
+	assert.ErrorIs(t, err, ErrExecutionRejected)
+	assert.False(t, p.CanBatch(req), "batching stops once the server refuses its run_timeout")
+}
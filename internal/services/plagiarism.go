@@ -0,0 +1,202 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/pushp314/devconnect-backend/internal/plagiarism"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultPlagiarismThreshold is the similarity at which both submissions are flagged
+	DefaultPlagiarismThreshold = 0.8
+	// plagiarismReportFloor is the similarity below which pairs aren't worth a report
+	plagiarismReportFloor = 0.5
+)
+
+// PlagiarismSummary describes one plagiarism check of a contest
+type PlagiarismSummary struct {
+	EventID   string  `json:"eventId"`
+	Threshold float64 `json:"threshold"`
+	Compared  int     `json:"compared"` // Submissions fingerprinted
+	Reports   int     `json:"reports"`
+	Flagged   int     `json:"flagged"` // Pairs at or above the threshold
+}
+
+// CheckPlagiarism compares, per problem and language, every participant's final
+// submission (their last accepted one, else their last) with everyone else's. Pairs
+// from the same team aren't compared. The event's reports are replaced, and pairs at
+// least threshold alike get a FlagTypeSuspicious flag on both submissions. The problem's
+// starter code is left out of the comparison, since everyone was given it.
+func CheckPlagiarism(db *gorm.DB, eventID string, threshold float64) (*PlagiarismSummary, error) {
+	var event models.Event
+	if err := db.First(&event, "id = ?", eventID).Error; err != nil {
+		return nil, err
+	}
+
+	query := db.Preload("User").Where("event_id = ? AND virtual_id IS NULL", eventID)
+	if event.ID != "practice-arena-mvp" && !event.EndTime.IsZero() {
+		query = query.Where("created_at <= ?", event.EndTime)
+	}
+	var submissions []models.Submission
+	if err := query.Order("created_at asc").Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+
+	// problemID/userID -> final submission
+	final := make(map[string]models.Submission)
+	var order []string
+	for _, sub := range submissions {
+		key := sub.ProblemID + "/" + sub.UserID
+		prev, seen := final[key]
+		if !seen {
+			order = append(order, key)
+		}
+		if !seen || sub.Status == models.SubStatusAC || prev.Status != models.SubStatusAC {
+			final[key] = sub
+		}
+	}
+
+	// problemID/language -> candidates
+	groups := make(map[string][]models.Submission)
+	var groupOrder []string
+	for _, key := range order {
+		sub := final[key]
+		group := sub.ProblemID + "/" + CanonicalLanguage(sub.Language)
+		if _, ok := groups[group]; !ok {
+			groupOrder = append(groupOrder, group)
+		}
+		groups[group] = append(groups[group], sub)
+	}
+
+	// problemID -> starter code
+	var problems []models.Problem
+	if err := db.Select("id, starter_code").Where("event_id = ?", eventID).Find(&problems).Error; err != nil {
+		return nil, err
+	}
+	starters := make(map[string]string, len(problems))
+	for _, p := range problems {
+		starters[p.ID] = p.StarterCode
+	}
+
+	floor := plagiarismReportFloor
+	if threshold < floor {
+		floor = threshold
+	}
+	summary := &PlagiarismSummary{EventID: eventID, Threshold: threshold}
+	now := time.Now()
+	var reports []models.PlagiarismReport
+	for _, group := range groupOrder {
+		subs := groups[group]
+		language := CanonicalLanguage(subs[0].Language)
+		var base *plagiarism.Document
+		if starter := starterCodeFor(starters[subs[0].ProblemID], language); starter != "" {
+			base = plagiarism.NewDocument("starter", language, starter)
+		}
+		docs := make([]*plagiarism.Document, len(subs))
+		for i, sub := range subs {
+			docs[i] = plagiarism.NewDocument(sub.ID, language, sub.Code)
+			docs[i].Exclude(base)
+			if docs[i].Comparable() {
+				summary.Compared++
+			}
+		}
+
+		sameTeam := func(i, j int) bool {
+			return subs[i].TeamID != nil && subs[j].TeamID != nil && *subs[i].TeamID == *subs[j].TeamID
+		}
+		for _, pair := range plagiarism.ComparePairs(docs, floor, sameTeam) {
+			a, b := subs[pair.A], subs[pair.B]
+			matches := make([]models.PlagiarismMatch, len(pair.Matches))
+			for i, m := range pair.Matches {
+				matches[i] = models.PlagiarismMatch{AStart: m.AStart, AEnd: m.AEnd, BStart: m.BStart, BEnd: m.BEnd}
+			}
+			reports = append(reports, models.PlagiarismReport{
+				ID:            uuid.New().String(),
+				EventID:       eventID,
+				ProblemID:     a.ProblemID,
+				SubmissionAID: a.ID,
+				SubmissionBID: b.ID,
+				UserAID:       a.UserID,
+				UserBID:       b.UserID,
+				Similarity:    pair.Similarity,
+				Flagged:       pair.Similarity >= threshold,
+				Matches:       matches,
+				CreatedAt:     now,
+			})
+		}
+	}
+
+	byID := make(map[string]models.Submission, len(final))
+	for _, sub := range final {
+		byID[sub.ID] = sub
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("event_id = ?", eventID).Delete(&models.PlagiarismReport{}).Error; err != nil {
+			return err
+		}
+		if len(reports) > 0 {
+			if err := tx.CreateInBatches(&reports, 200).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, report := range reports {
+			if !report.Flagged {
+				continue
+			}
+			summary.Flagged++
+			a, b := byID[report.SubmissionAID], byID[report.SubmissionBID]
+			if err := flagPlagiarism(tx, a, b, report.Similarity); err != nil {
+				return err
+			}
+			if err := flagPlagiarism(tx, b, a, report.Similarity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	summary.Reports = len(reports)
+	return summary, nil
+}
+
+// starterCodeFor picks the language's template out of a problem's starter code, which
+// is either a JSON map of language to code or the same code for every language
+func starterCodeFor(raw, language string) string {
+	var byLanguage map[string]string
+	if err := json.Unmarshal([]byte(raw), &byLanguage); err != nil {
+		return raw
+	}
+	for lang, code := range byLanguage {
+		if CanonicalLanguage(lang) == language {
+			return code
+		}
+	}
+	return ""
+}
+
+// flagPlagiarism flags sub as similar to other, once per pair across re-checks
+func flagPlagiarism(tx *gorm.DB, sub, other models.Submission, similarity float64) error {
+	var existing int64
+	tx.Model(&models.SubmissionFlag{}).
+		Where("submission_id = ? AND type = ? AND details LIKE ?", sub.ID, models.FlagTypeSuspicious, "Plagiarism:%submission "+other.ID+" %").
+		Count(&existing)
+	if existing > 0 {
+		return nil
+	}
+	return tx.Create(&models.SubmissionFlag{
+		ID:           uuid.New().String(),
+		SubmissionID: sub.ID,
+		Type:         models.FlagTypeSuspicious,
+		Details:      fmt.Sprintf("Plagiarism: %.0f%% similar to submission %s by %s", similarity*100, other.ID, other.User.Username),
+		CreatedAt:    time.Now(),
+	}).Error
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const plagiarismSource = `def solve():
+    n = int(input())
+    values = list(map(int, input().split()))
+    best = values[0]
+    current = 0
+    for v in values:
+        current = max(v, current + v)
+        best = max(best, current)
+    print(best)
+
+solve()
+`
+
+func TestCheckPlagiarism_FlagsSimilarPairs(t *testing.T) {
+	db := setupTestDB(t)
+
+	start := time.Now().Add(-3 * time.Hour)
+	db.Create(&models.Event{ID: "pl", Slug: "pl", Status: models.EventStatusEnded,
+		StartTime: start, EndTime: start.Add(2 * time.Hour)})
+	db.Create(&models.Problem{ID: "pl-a", EventID: "pl", Points: 100})
+
+	renamed := strings.NewReplacer("values", "arr", "best", "ans", "current", "run", "v in", "x in", "(v,", "(x,", "+ v", "+ x").
+		Replace(plagiarismSource)
+	codes := map[string]string{
+		"alice": plagiarismSource,
+		"bob":   "# my own work\n" + renamed,
+		"carol": "import sys\nprint(sum(sorted(int(x) for x in sys.stdin.read().split()[1:])[-1:]))\nfor line in open(0): pass\nwhile False: break\n",
+	}
+	for id, code := range codes {
+		db.Create(&models.User{ID: id, Username: id, Email: id + "@example.com", TrustScore: 100})
+		db.Create(&models.Submission{ID: "pl-" + id, UserID: id, EventID: "pl", ProblemID: "pl-a", Language: "python",
+			Code: code, Status: models.SubStatusAC, Score: 100, CreatedAt: start.Add(30 * time.Minute)})
+	}
+
+	summary, err := CheckPlagiarism(db, "pl", DefaultPlagiarismThreshold)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Reports)
+	assert.Equal(t, 1, summary.Flagged)
+
+	var report models.PlagiarismReport
+	require.NoError(t, db.First(&report, "event_id = ?", "pl").Error)
+	assert.ElementsMatch(t, []string{"alice", "bob"}, []string{report.UserAID, report.UserBID})
+	assert.NotEmpty(t, report.Matches)
+
+	var flags []models.SubmissionFlag
+	db.Where("type = ?", models.FlagTypeSuspicious).Find(&flags)
+	require.Len(t, flags, 2)
+
+	// Checking again replaces the reports without flagging the pair twice
+	_, err = CheckPlagiarism(db, "pl", DefaultPlagiarismThreshold)
+	require.NoError(t, err)
+	var reports, flagCount int64
+	db.Model(&models.PlagiarismReport{}).Count(&reports)
+	db.Model(&models.SubmissionFlag{}).Count(&flagCount)
+	assert.Equal(t, int64(1), reports)
+	assert.Equal(t, int64(2), flagCount)
+}
+
+func TestCheckPlagiarism_IgnoresStarterCode(t *testing.T) {
+	db := setupTestDB(t)
+
+	template := `import sys
+input = sys.stdin.readline
+
+def read_ints():
+    return list(map(int, input().split()))
+
+def write(values):
+    sys.stdout.write(" ".join(map(str, values)) + "\n")
+
+`
+	start := time.Now().Add(-3 * time.Hour)
+	db.Create(&models.Event{ID: "pl", Slug: "pl", Status: models.EventStatusEnded,
+		StartTime: start, EndTime: start.Add(2 * time.Hour)})
+	starter, _ := json.Marshal(map[string]string{"Python": template})
+	db.Create(&models.Problem{ID: "pl-a", EventID: "pl", Points: 100, StarterCode: string(starter)})
+
+	codes := map[string]string{
+		"alice": template + "n = read_ints()[0]\nwrite(sorted(read_ints())[::-1])\n",
+		"bob":   template + "total = 0\nfor x in read_ints()[1:]:\n    total += x * x\nwrite([total])\n",
+	}
+	for id, code := range codes {
+		db.Create(&models.User{ID: id, Username: id, Email: id + "@example.com", TrustScore: 100})
+		db.Create(&models.Submission{ID: "pl-" + id, UserID: id, EventID: "pl", ProblemID: "pl-a", Language: "python",
+			Code: code, Status: models.SubStatusAC, Score: 100, CreatedAt: start.Add(30 * time.Minute)})
+	}
+
+	summary, err := CheckPlagiarism(db, "pl", DefaultPlagiarismThreshold)
+	require.NoError(t, err)
+	assert.Equal(t, 0, summary.Flagged)
+	assert.Equal(t, 0, summary.Reports)
+}
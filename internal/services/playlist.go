@@ -0,0 +1,147 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CompletePlaylistItem marks one item of a track done for a user; the first completion wins
+func CompletePlaylistItem(db *gorm.DB, userID, playlistID, snippetID string, source models.PlaylistProgressSource) error {
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.PlaylistProgress{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		PlaylistID:  playlistID,
+		SnippetID:   snippetID,
+		Source:      source,
+		CompletedAt: time.Now(),
+	}).Error
+}
+
+// CompleteSnippetInPlaylists marks a snippet done in every track that contains it, for
+// progress driven by viewing or running the snippet
+func CompleteSnippetInPlaylists(db *gorm.DB, userID, snippetID string, source models.PlaylistProgressSource) error {
+	var playlistIDs []string
+	if err := db.Model(&models.PlaylistSnippet{}).Where("snippet_id = ?", snippetID).
+		Distinct().Pluck("playlist_id", &playlistIDs).Error; err != nil {
+		return err
+	}
+	for _, playlistID := range playlistIDs {
+		if err := CompletePlaylistItem(db, userID, playlistID, snippetID, source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PlaylistCompletions returns when the user completed each item, by playlist then snippet
+func PlaylistCompletions(db *gorm.DB, userID string, playlistIDs []string) (map[string]map[string]time.Time, error) {
+	done := make(map[string]map[string]time.Time, len(playlistIDs))
+	if len(playlistIDs) == 0 {
+		return done, nil
+	}
+	var progress []models.PlaylistProgress
+	if err := db.Where("user_id = ? AND playlist_id IN ?", userID, playlistIDs).Find(&progress).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range progress {
+		if done[p.PlaylistID] == nil {
+			done[p.PlaylistID] = map[string]time.Time{}
+		}
+		done[p.PlaylistID][p.SnippetID] = p.CompletedAt
+	}
+	return done, nil
+}
+
+// SummarizePlaylistProgress counts the user's completed items of each track. Only items
+// still in the track count, so removing one can finish a track and adding one reopens it.
+func SummarizePlaylistProgress(db *gorm.DB, userID string, playlistIDs []string) (map[string]models.PlaylistProgressSummary, error) {
+	summaries := make(map[string]models.PlaylistProgressSummary, len(playlistIDs))
+	if len(playlistIDs) == 0 {
+		return summaries, nil
+	}
+	var items []models.PlaylistSnippet
+	if err := db.Select("playlist_id", "snippet_id").Where("playlist_id IN ?", playlistIDs).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	done, err := PlaylistCompletions(db, userID, playlistIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	completed := map[string]int{}
+	total := map[string]int{}
+	for _, item := range items {
+		total[item.PlaylistID]++
+		if _, ok := done[item.PlaylistID][item.SnippetID]; ok {
+			completed[item.PlaylistID]++
+		}
+	}
+	for _, id := range playlistIDs {
+		summaries[id] = NewPlaylistProgressSummary(completed[id], total[id])
+	}
+	return summaries, nil
+}
+
+// NewPlaylistProgressSummary summarizes completed of total items
+func NewPlaylistProgressSummary(completed, total int) models.PlaylistProgressSummary {
+	summary := models.PlaylistProgressSummary{Completed: completed, Total: total}
+	if total > 0 {
+		summary.Percent = completed * 100 / total
+		summary.Done = completed >= total
+	}
+	return summary
+}
+
+// BackfillPlaylistProgress turns snippet views recorded before progress was persisted into
+// completed items, once, on the first boot with the progress table
+func BackfillPlaylistProgress(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&models.PlaylistProgress{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var items []models.PlaylistSnippet
+	if err := db.Select("playlist_id", "snippet_id").Find(&items).Error; err != nil {
+		return err
+	}
+	bySnippet := map[string][]string{}
+	for _, item := range items {
+		bySnippet[item.SnippetID] = append(bySnippet[item.SnippetID], item.PlaylistID)
+	}
+	if len(bySnippet) == 0 {
+		return nil
+	}
+	snippetIDs := make([]string, 0, len(bySnippet))
+	for id := range bySnippet {
+		snippetIDs = append(snippetIDs, id)
+	}
+
+	var views []models.EntityView
+	if err := db.Where("entity_type = ? AND entity_id IN ?", models.EntityTypeSnippet, snippetIDs).Find(&views).Error; err != nil {
+		return err
+	}
+	var progress []models.PlaylistProgress
+	for _, view := range views {
+		for _, playlistID := range bySnippet[view.EntityID] {
+			progress = append(progress, models.PlaylistProgress{
+				ID:          uuid.New().String(),
+				UserID:      view.UserID,
+				PlaylistID:  playlistID,
+				SnippetID:   view.EntityID,
+				Source:      models.ProgressSourceView,
+				CompletedAt: view.ViewedAt,
+			})
+		}
+	}
+	if len(progress) == 0 {
+		return nil
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&progress, 500).Error
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUnratedContest  = errors.New("contest is not rated")
+	ErrResultsNotFinal = errors.New("contest results are not final yet")
+	ErrRatingsApplied  = errors.New("rating changes were already applied")
+)
+
+// RatingParticipant is one ranked participant going into a rating update
+type RatingParticipant struct {
+	UserID string `json:"userId"`
+	Rank   int    `json:"rank"`
+	Rating int    `json:"rating"` // Before the contest
+}
+
+// RatingChange is the outcome of a rated contest for one participant
+type RatingChange struct {
+	UserID    string `json:"userId"`
+	Username  string `json:"username,omitempty"`
+	Rank      int    `json:"rank"`
+	OldRating int    `json:"oldRating"`
+	NewRating int    `json:"newRating"`
+	Delta     int    `json:"delta"`
+}
+
+// winProbability is the Elo chance that a player rated a beats one rated b
+func winProbability(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// ComputeRatingChanges applies the Codeforces rating formula: each participant's
+// expected rank (seed) is compared with their actual rank, the rating that would have
+// made the geometric mean of the two their seed becomes the target, and they move half
+// way towards it. Deltas are then shifted so the total is slightly negative and the
+// top of the field can't inflate, keeping the rating pool stable over time.
+func ComputeRatingChanges(participants []RatingParticipant) []RatingChange {
+	n := len(participants)
+	if n == 0 {
+		return []RatingChange{}
+	}
+
+	ratings := make([]float64, n)
+	for i, p := range participants {
+		ratings[i] = float64(p.Rating)
+	}
+
+	// seed is the expected rank of someone rated r against everyone but `self`
+	seed := func(r float64, self int) float64 {
+		s := 1.0
+		for j, other := range ratings {
+			if j != self {
+				s += winProbability(other, r)
+			}
+		}
+		return s
+	}
+
+	deltas := make([]float64, n)
+	for i, p := range participants {
+		target := math.Sqrt(seed(ratings[i], i) * float64(p.Rank))
+
+		// The rating whose seed equals the target; seed falls as rating rises
+		lo, hi := 1.0, 8000.0
+		for hi-lo > 1 {
+			mid := (lo + hi) / 2
+			if seed(mid, i) < target {
+				hi = mid
+			} else {
+				lo = mid
+			}
+		}
+		deltas[i] = (lo - ratings[i]) / 2
+	}
+
+	// 1. Keep the sum of changes just below zero
+	var sum float64
+	for _, d := range deltas {
+		sum += d
+	}
+	inc := -sum/float64(n) - 1
+	for i := range deltas {
+		deltas[i] += inc
+	}
+
+	// 2. The highest rated shouldn't gain on average
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return ratings[order[a]] > ratings[order[b]] })
+	top := int(math.Min(float64(n), 4*math.Round(math.Sqrt(float64(n)))))
+	var topSum float64
+	for _, i := range order[:top] {
+		topSum += deltas[i]
+	}
+	inc = math.Min(math.Max(-topSum/float64(top), -10), 0)
+
+	changes := make([]RatingChange, n)
+	for i, p := range participants {
+		delta := int(math.Round(deltas[i] + inc))
+		newRating := p.Rating + delta
+		if newRating < 1 {
+			newRating = 1
+			delta = newRating - p.Rating
+		}
+		changes[i] = RatingChange{
+			UserID:    p.UserID,
+			Rank:      p.Rank,
+			OldRating: p.Rating,
+			NewRating: newRating,
+			Delta:     delta,
+		}
+	}
+	return changes
+}
+
+// PreviewRatingChanges computes a rated contest's rating changes from its results
+// snapshot and the participants' current ratings, without saving anything
+func PreviewRatingChanges(db *gorm.DB, eventID string) ([]RatingChange, error) {
+	var event models.Event
+	if err := db.First(&event, "id = ?", eventID).Error; err != nil {
+		return nil, err
+	}
+	if !event.Rated {
+		return nil, ErrUnratedContest
+	}
+	if event.FinalizedAt == nil {
+		return nil, ErrResultsNotFinal
+	}
+
+	results, err := ContestResults(db, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	participants := make([]RatingParticipant, 0, len(results))
+	usernames := make(map[string]string, len(results))
+	for _, r := range results {
+		participants = append(participants, RatingParticipant{UserID: r.UserID, Rank: r.Rank, Rating: r.User.Rating})
+		usernames[r.UserID] = r.User.Username
+	}
+
+	changes := ComputeRatingChanges(participants)
+	for i := range changes {
+		changes[i].Username = usernames[changes[i].UserID]
+	}
+	return changes, nil
+}
+
+// ApplyRatingChanges commits a rated contest's rating changes exactly once, recording
+// a RatingHistory row per participant
+func ApplyRatingChanges(db *gorm.DB, eventID string) ([]RatingChange, error) {
+	var changes []RatingChange
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Lock the event so two admins can't apply concurrently
+		var event models.Event
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, "id = ?", eventID).Error; err != nil {
+			return err
+		}
+		if event.RatedAt != nil {
+			return ErrRatingsApplied
+		}
+
+		var err error
+		if changes, err = PreviewRatingChanges(tx, eventID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, change := range changes {
+			if err := tx.Create(&models.RatingHistory{
+				ID:        uuid.New().String(),
+				UserID:    change.UserID,
+				EventID:   eventID,
+				Rank:      change.Rank,
+				OldRating: change.OldRating,
+				NewRating: change.NewRating,
+				Delta:     change.Delta,
+				CreatedAt: now,
+			}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.User{}).Where("id = ?", change.UserID).Updates(map[string]interface{}{
+				"rating":         change.NewRating,
+				"max_rating":     gorm.Expr("CASE WHEN rated_contests > 0 AND max_rating > ? THEN max_rating ELSE ? END", change.NewRating, change.NewRating),
+				"rated_contests": gorm.Expr("rated_contests + 1"),
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.Event{}).Where("id = ?", eventID).Update("rated_at", now).Error
+	})
+	return changes, err
+}
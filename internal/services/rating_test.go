@@ -0,0 +1,79 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeRatingChanges(t *testing.T) {
+	changes := ComputeRatingChanges([]RatingParticipant{
+		{UserID: "a", Rank: 1, Rating: 1500},
+		{UserID: "b", Rank: 2, Rating: 1500},
+		{UserID: "c", Rank: 3, Rating: 1500},
+		{UserID: "d", Rank: 4, Rating: 1500},
+	})
+	require.Len(t, changes, 4)
+
+	sum := 0
+	for i, change := range changes {
+		assert.Equal(t, change.OldRating+change.Delta, change.NewRating)
+		if i > 0 {
+			assert.Less(t, change.Delta, changes[i-1].Delta, "a better rank gains more")
+		}
+		sum += change.Delta
+	}
+	assert.Greater(t, changes[0].Delta, 0)
+	assert.Less(t, changes[3].Delta, 0)
+	assert.LessOrEqual(t, sum, 0, "the pool never inflates")
+
+	// Beating a much stronger field is worth more than beating equals
+	upset := ComputeRatingChanges([]RatingParticipant{
+		{UserID: "a", Rank: 1, Rating: 1200},
+		{UserID: "b", Rank: 2, Rating: 1800},
+	})
+	assert.Greater(t, upset[0].Delta, changes[0].Delta)
+	assert.Empty(t, ComputeRatingChanges(nil))
+}
+
+func TestApplyRatingChanges_Once(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.RatingHistory{}))
+
+	finalized := time.Now()
+	db.Create(&models.Event{ID: "rated", Slug: "rated", Status: models.EventStatusEnded, Rated: true, FinalizedAt: &finalized})
+	db.Create(&models.Event{ID: "unrated", Slug: "unrated", Status: models.EventStatusEnded, FinalizedAt: &finalized})
+	for i, id := range []string{"alice", "bob"} {
+		db.Create(&models.User{ID: id, Username: id, Email: id + "@example.com"})
+		db.Create(&models.ContestResult{ID: "cr-" + id, EventID: "rated", UserID: id, Rank: i + 1, Version: 1})
+	}
+
+	preview, err := PreviewRatingChanges(db, "rated")
+	require.NoError(t, err)
+	require.Len(t, preview, 2)
+	assert.Equal(t, models.InitialRating, preview[0].OldRating)
+	assert.Equal(t, "alice", preview[0].Username)
+
+	applied, err := ApplyRatingChanges(db, "rated")
+	require.NoError(t, err)
+	assert.Equal(t, preview, applied)
+
+	_, err = ApplyRatingChanges(db, "rated")
+	assert.ErrorIs(t, err, ErrRatingsApplied)
+
+	var alice models.User
+	db.First(&alice, "id = ?", "alice")
+	assert.Equal(t, applied[0].NewRating, alice.Rating)
+	assert.Equal(t, applied[0].NewRating, alice.MaxRating)
+	assert.Equal(t, 1, alice.RatedContests)
+
+	var history int64
+	db.Model(&models.RatingHistory{}).Where("event_id = ?", "rated").Count(&history)
+	assert.Equal(t, int64(2), history)
+
+	_, err = PreviewRatingChanges(db, "unrated")
+	assert.ErrorIs(t, err, ErrUnratedContest)
+}
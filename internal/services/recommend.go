@@ -0,0 +1,327 @@
+package services
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Practice difficulties in order; anything else is treated as MEDIUM
+var practiceDifficulties = []string{"EASY", "MEDIUM", "HARD"}
+
+func difficultyRank(difficulty string) int {
+	for i, d := range practiceDifficulties {
+		if strings.EqualFold(d, difficulty) {
+			return i
+		}
+	}
+	return 1
+}
+
+const (
+	// levelUpAfter is how many problems of the user's top difficulty they solve before
+	// the next one up is recommended
+	levelUpAfter = 3
+	// planFocusSize is how many problems each focus area of a practice plan holds
+	planFocusSize = 3
+)
+
+// CategoryStat is a user's practice record in one category
+type CategoryStat struct {
+	Category  string `json:"category"`
+	Attempted int    `json:"attempted"` // Distinct problems with a judged submission
+	Solved    int    `json:"solved"`
+}
+
+// SolveRate is the share of attempted problems the user solved
+func (s CategoryStat) SolveRate() float64 {
+	if s.Attempted == 0 {
+		return 0
+	}
+	return float64(s.Solved) / float64(s.Attempted)
+}
+
+func (s CategoryStat) strong() bool { return s.Solved >= 2 && s.SolveRate() >= 0.6 }
+func (s CategoryStat) weak() bool   { return s.Attempted > s.Solved && s.SolveRate() < 0.5 }
+
+// Recommendation is a suggested practice problem and why it was picked
+type Recommendation struct {
+	Problem models.PracticeProblem `json:"problem"`
+	Score   float64                `json:"score"`
+	Reasons []string               `json:"reasons"`
+}
+
+// Practice plan focus areas
+const (
+	FocusStrengthen = "STRENGTHEN"
+	FocusLevel      = "LEVEL"
+	FocusExplore    = "EXPLORE"
+)
+
+// PracticeFocus is one area of a practice plan with the problems to work on there
+type PracticeFocus struct {
+	Kind     string           `json:"kind"`
+	Title    string           `json:"title"`
+	Problems []Recommendation `json:"problems"`
+}
+
+// PracticePlan is a user's personalized practice overview
+type PracticePlan struct {
+	TargetDifficulty string           `json:"targetDifficulty"`
+	Solved           int              `json:"solved"`
+	Categories       []CategoryStat   `json:"categories"`
+	Strengths        []string         `json:"strengths"`
+	Weaknesses       []string         `json:"weaknesses"`
+	Recommendations  []Recommendation `json:"recommendations"`
+	Focus            []PracticeFocus  `json:"focus"`
+}
+
+// practiceProfile is what the recommender knows about a user
+type practiceProfile struct {
+	solved       map[string]bool // Problem IDs
+	attempted    map[string]bool
+	categories   map[string]*CategoryStat
+	solvedByDiff [3]int
+	target       int // Difficulty rank
+	interests    map[string]bool
+	languages    map[string]bool
+}
+
+// loadPracticeProfile builds the profile from the user's judged practice submissions
+func loadPracticeProfile(db *gorm.DB, user *models.User) (*practiceProfile, error) {
+	var rows []struct {
+		ProblemID  string
+		Category   string
+		Difficulty string
+		Accepted   int
+	}
+	if err := db.Table("practice_submissions AS s").
+		Select(`s."problemId" AS problem_id, p.category, p.difficulty, SUM(CASE WHEN s.status = ? THEN 1 ELSE 0 END) AS accepted`, models.SubStatusAC).
+		Joins(`JOIN practice_problems p ON p.id = s."problemId" AND p."deletedAt" IS NULL`).
+		Where(`s."userId" = ? AND s.status NOT IN ?`, user.ID, []string{"PENDING", "RUNNING", "ERROR"}).
+		Group("problem_id, p.category, p.difficulty").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	p := &practiceProfile{
+		solved:     map[string]bool{},
+		attempted:  map[string]bool{},
+		categories: map[string]*CategoryStat{},
+		interests:  lowerSet(user.Interests),
+		languages:  lowerSet(user.PreferredLanguages),
+	}
+	for _, r := range rows {
+		p.attempted[r.ProblemID] = true
+		stat := p.category(r.Category)
+		stat.Attempted++
+		if r.Accepted > 0 {
+			p.solved[r.ProblemID] = true
+			p.solvedByDiff[difficultyRank(r.Difficulty)]++
+			stat.Solved++
+		}
+	}
+
+	// Aim at the hardest difficulty solved so far, one step up once it's comfortable
+	for rank := len(practiceDifficulties) - 1; rank >= 0; rank-- {
+		if p.solvedByDiff[rank] == 0 {
+			continue
+		}
+		p.target = rank
+		if p.solvedByDiff[rank] >= levelUpAfter && rank < len(practiceDifficulties)-1 {
+			p.target++
+		}
+		break
+	}
+	return p, nil
+}
+
+func (p *practiceProfile) category(name string) *CategoryStat {
+	key := strings.ToLower(name)
+	stat, ok := p.categories[key]
+	if !ok {
+		stat = &CategoryStat{Category: name}
+		p.categories[key] = stat
+	}
+	return stat
+}
+
+func lowerSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			set[strings.ToLower(v)] = true
+		}
+	}
+	return set
+}
+
+// score rates an unsolved problem for the user, highest first
+func (p *practiceProfile) score(problem *models.PracticeProblem) Recommendation {
+	rec := Recommendation{Problem: *problem}
+	add := func(points float64, reason string) {
+		rec.Score += points
+		if reason != "" {
+			rec.Reasons = append(rec.Reasons, reason)
+		}
+	}
+
+	switch gap := difficultyRank(problem.Difficulty) - p.target; {
+	case gap == 0:
+		add(3, "Matches your level ("+practiceDifficulties[p.target]+")")
+	case gap == -1:
+		add(1, "")
+	case gap == 1:
+		add(0.5, "")
+	}
+
+	category := strings.ToLower(problem.Category)
+	if category != "" {
+		stat, tried := p.categories[category]
+		switch {
+		case tried && stat.weak():
+			add(2*(1-stat.SolveRate()), "Builds up "+problem.Category+", one of your weaker topics")
+		case tried && stat.strong() && difficultyRank(problem.Difficulty) > p.target:
+			add(1, "A harder "+problem.Category+" problem, one of your strengths")
+		case !tried:
+			add(0.75, "A topic you haven't tried yet")
+		}
+		if p.interests[category] {
+			add(1.5, "Matches your interest in "+problem.Category)
+		}
+	}
+
+	if p.attempted[problem.ID] {
+		add(1.5, "You've attempted this one before")
+	}
+	if p.languages[strings.ToLower(problem.Language)] {
+		add(1, "Uses "+problem.Language+", one of your preferred languages")
+	}
+	if problem.AttemptCount > 0 {
+		add(0.5*float64(problem.SolveCount)/float64(problem.AttemptCount), "")
+	}
+	return rec
+}
+
+// rankPracticeProblems scores every problem the user hasn't solved. Ties go to the more
+// solved, then older problem, so the order is stable.
+func rankPracticeProblems(db *gorm.DB, user *models.User) (*practiceProfile, []Recommendation, error) {
+	profile, err := loadPracticeProfile(db, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var problems []models.PracticeProblem
+	if err := db.Select(`id, "createdAt", title, difficulty, category, language, solve_count, attempt_count, is_daily_problem`).
+		Find(&problems).Error; err != nil {
+		return nil, nil, err
+	}
+	ranked := make([]Recommendation, 0, len(problems))
+	for i := range problems {
+		if !profile.solved[problems[i].ID] {
+			ranked = append(ranked, profile.score(&problems[i]))
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		switch {
+		case a.Score != b.Score:
+			return a.Score > b.Score
+		case a.Problem.SolveCount != b.Problem.SolveCount:
+			return a.Problem.SolveCount > b.Problem.SolveCount
+		case !a.Problem.CreatedAt.Equal(b.Problem.CreatedAt):
+			return a.Problem.CreatedAt.Before(b.Problem.CreatedAt)
+		default:
+			return a.Problem.ID < b.Problem.ID
+		}
+	})
+	return profile, ranked, nil
+}
+
+// RecommendPracticeProblems returns up to limit unsolved problems for the user, best first
+func RecommendPracticeProblems(db *gorm.DB, user *models.User, limit int) ([]Recommendation, error) {
+	_, ranked, err := rankPracticeProblems(db, user)
+	if err != nil {
+		return nil, err
+	}
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked, nil
+}
+
+// PracticePlanFor summarizes the user's strengths and weaknesses and groups their
+// recommendations into focus areas: their weakest topic, the next difficulty, and
+// interests they haven't practiced yet
+func PracticePlanFor(db *gorm.DB, user *models.User, limit int) (*PracticePlan, error) {
+	profile, ranked, err := rankPracticeProblems(db, user)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &PracticePlan{
+		TargetDifficulty: practiceDifficulties[profile.target],
+		Solved:           len(profile.solved),
+		Categories:       []CategoryStat{},
+		Strengths:        []string{},
+		Weaknesses:       []string{},
+		Recommendations:  ranked[:min(limit, len(ranked))],
+		Focus:            []PracticeFocus{},
+	}
+	for _, stat := range profile.categories {
+		if stat.Category != "" {
+			plan.Categories = append(plan.Categories, *stat)
+		}
+	}
+	sort.Slice(plan.Categories, func(i, j int) bool {
+		a, b := plan.Categories[i], plan.Categories[j]
+		if a.Solved != b.Solved {
+			return a.Solved > b.Solved
+		}
+		return a.Category < b.Category
+	})
+	var weakest *CategoryStat
+	for i := range plan.Categories {
+		stat := &plan.Categories[i]
+		if stat.strong() {
+			plan.Strengths = append(plan.Strengths, stat.Category)
+		}
+		if stat.weak() {
+			plan.Weaknesses = append(plan.Weaknesses, stat.Category)
+			if weakest == nil || stat.SolveRate() < weakest.SolveRate() {
+				weakest = stat
+			}
+		}
+	}
+
+	focus := func(kind, title string, keep func(*models.PracticeProblem) bool) {
+		f := PracticeFocus{Kind: kind, Title: title, Problems: []Recommendation{}}
+		for _, rec := range ranked {
+			if len(f.Problems) == planFocusSize {
+				break
+			}
+			if keep(&rec.Problem) {
+				f.Problems = append(f.Problems, rec)
+			}
+		}
+		if len(f.Problems) > 0 {
+			plan.Focus = append(plan.Focus, f)
+		}
+	}
+	if weakest != nil {
+		focus(FocusStrengthen, "Strengthen "+weakest.Category, func(p *models.PracticeProblem) bool {
+			return strings.EqualFold(p.Category, weakest.Category)
+		})
+	}
+	focus(FocusLevel, "Problems at your level ("+plan.TargetDifficulty+")", func(p *models.PracticeProblem) bool {
+		return difficultyRank(p.Difficulty) == profile.target
+	})
+	focus(FocusExplore, "Explore your interests", func(p *models.PracticeProblem) bool {
+		category := strings.ToLower(p.Category)
+		_, tried := profile.categories[category]
+		return profile.interests[category] && !tried
+	})
+	return plan, nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecommendPracticeProblems(t *testing.T) {
+	db := setupTestDB(t)
+	db.Create(&[]models.PracticeProblem{
+		{ID: "arr-e1", Title: "A1", Difficulty: "EASY", Category: "Arrays", Language: "python", SolveCount: 40},
+		{ID: "arr-e2", Title: "A2", Difficulty: "EASY", Category: "Arrays", Language: "python", SolveCount: 30},
+		{ID: "arr-e3", Title: "A3", Difficulty: "EASY", Category: "Arrays", Language: "python", SolveCount: 20},
+		{ID: "arr-m1", Title: "A4", Difficulty: "MEDIUM", Category: "Arrays", Language: "python", SolveCount: 10},
+		{ID: "tree-e1", Title: "T1", Difficulty: "EASY", Category: "Trees", Language: "python", SolveCount: 50},
+		{ID: "tree-m1", Title: "T2", Difficulty: "MEDIUM", Category: "Trees", Language: "cpp", SolveCount: 5},
+		{ID: "graph-m1", Title: "G1", Difficulty: "MEDIUM", Category: "Graphs", Language: "cpp", SolveCount: 5},
+		{ID: "dp-h1", Title: "D1", Difficulty: "HARD", Category: "DP", Language: "python", SolveCount: 1},
+	})
+	user := models.User{ID: "learner", Interests: []string{"graphs"}, PreferredLanguages: []string{"Python"}}
+	submit := func(problemID string, status models.SubmissionStatus) {
+		db.Create(&models.PracticeSubmission{ID: problemID + "-" + string(status), UserID: user.ID, ProblemID: problemID, Status: string(status)})
+	}
+
+	// A newcomer starts on easy problems in a preferred language
+	recs, err := RecommendPracticeProblems(db, &user, 3)
+	require.NoError(t, err)
+	require.Len(t, recs, 3)
+	assert.Equal(t, "EASY", recs[0].Problem.Difficulty)
+	assert.Equal(t, "python", recs[0].Problem.Language)
+
+	// Three easy arrays solved: level up to medium; the failed tree problem is a weakness
+	submit("arr-e1", models.SubStatusAC)
+	submit("arr-e2", models.SubStatusWA)
+	submit("arr-e2", models.SubStatusAC)
+	submit("arr-e3", models.SubStatusAC)
+	submit("tree-e1", models.SubStatusWA)
+	submit("dp-h1", "PENDING") // Not judged yet, so not an attempt
+
+	recs, err = RecommendPracticeProblems(db, &user, 10)
+	require.NoError(t, err)
+	require.Len(t, recs, 5) // The solved problems are never recommended
+	for _, rec := range recs {
+		assert.NotContains(t, []string{"arr-e1", "arr-e2", "arr-e3"}, rec.Problem.ID)
+	}
+	assert.Equal(t, "tree-e1", recs[0].Problem.ID) // Retry the failed problem in the weak category
+
+	plan, err := PracticePlanFor(db, &user, 10)
+	require.NoError(t, err)
+	assert.Equal(t, "MEDIUM", plan.TargetDifficulty)
+	assert.Equal(t, 3, plan.Solved)
+	assert.Equal(t, []string{"Arrays"}, plan.Strengths)
+	assert.Equal(t, []string{"Trees"}, plan.Weaknesses)
+
+	kinds := map[string][]string{}
+	for _, f := range plan.Focus {
+		for _, rec := range f.Problems {
+			kinds[f.Kind] = append(kinds[f.Kind], rec.Problem.ID)
+		}
+	}
+	assert.Equal(t, []string{"tree-e1", "tree-m1"}, kinds[FocusStrengthen])
+	assert.Equal(t, []string{"graph-m1"}, kinds[FocusExplore])
+	assert.Equal(t, []string{"graph-m1", "tree-m1", "arr-m1"}, kinds[FocusLevel])
+}
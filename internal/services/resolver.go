@@ -0,0 +1,152 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
+)
+
+var (
+	ErrContestNotEnded = errors.New("contest has not ended")
+	ErrNoFreeze        = errors.New("contest has no freeze")
+)
+
+// ResolverStep reveals one frozen cell of the public scoreboard
+type ResolverStep struct {
+	Index      int              `json:"index"` // 1-based position in the reveal
+	UserID     string           `json:"userId"`
+	ProblemID  string           `json:"problemId"`
+	Before     ProblemStat      `json:"before"`
+	After      ProblemStat      `json:"after"`
+	RankBefore int              `json:"rankBefore"`
+	RankAfter  int              `json:"rankAfter"`
+	Entry      LeaderboardEntry `json:"entry"` // The row once revealed
+}
+
+// Resolution is the complete reveal of an ended contest's frozen submissions
+type Resolution struct {
+	EventID string             `json:"eventId"`
+	Initial []LeaderboardEntry `json:"initial"` // Frozen public board before any reveal
+	Steps   []ResolverStep     `json:"steps"`
+	Final   []LeaderboardEntry `json:"final"` // Equal to the unfrozen standings
+}
+
+// Resolve replays the reveal of frozen submissions in ICPC resolver order: the
+// lowest-ranked row with a pending cell reveals its first pending problem (by problem
+// order), then the board is re-ranked. It stops after limit steps (negative = all)
+// and returns the steps taken and the board at that point.
+func Resolve(event models.Event, visible, hidden []models.Submission, limit int) ([]ResolverStep, []LeaderboardEntry) {
+	problemOrder := make(map[string]int, len(event.Problems))
+	for _, p := range event.Problems {
+		problemOrder[p.ID] = p.Order
+	}
+	before := func(a, b string) bool {
+		if problemOrder[a] != problemOrder[b] {
+			return problemOrder[a] < problemOrder[b]
+		}
+		return a < b
+	}
+
+	// userID/problemID -> verdicts shown
+	revealed := make(map[string]bool)
+	cell := func(userID, problemID string) string { return userID + "/" + problemID }
+
+	board := func() []LeaderboardEntry {
+		counted := append([]models.Submission{}, visible...)
+		var pending []models.Submission
+		for _, sub := range hidden {
+			if revealed[cell(participantKey(sub), sub.ProblemID)] {
+				counted = append(counted, sub)
+			} else {
+				pending = append(pending, sub)
+			}
+		}
+		sort.SliceStable(counted, func(i, j int) bool { return counted[i].CreatedAt.Before(counted[j].CreatedAt) })
+		return markFrozen(ComputeStandings(event, counted), pending)
+	}
+
+	current := board()
+	steps := []ResolverStep{}
+	for limit < 0 || len(steps) < limit {
+		// Bottom-up: the lowest row that still has something hidden
+		row, problemID := -1, ""
+		for i := len(current) - 1; i >= 0 && row < 0; i-- {
+			for pid, stat := range current[i].Problems {
+				if stat.Pending > 0 && (problemID == "" || before(pid, problemID)) {
+					problemID = pid
+				}
+			}
+			if problemID != "" {
+				row = i
+			}
+		}
+		if row < 0 {
+			break
+		}
+
+		prev := current[row]
+		revealed[cell(prev.UserID, problemID)] = true
+		current = board()
+
+		for _, entry := range current {
+			if entry.UserID == prev.UserID {
+				steps = append(steps, ResolverStep{
+					Index:      len(steps) + 1,
+					UserID:     entry.UserID,
+					ProblemID:  problemID,
+					Before:     prev.Problems[problemID],
+					After:      entry.Problems[problemID],
+					RankBefore: prev.Rank,
+					RankAfter:  entry.Rank,
+					Entry:      entry,
+				})
+				break
+			}
+		}
+	}
+	return steps, current
+}
+
+// frozenSubmissions splits an event's submissions up to `until` at its FreezeTime:
+// visible ones count on the public board, hidden ones are shown as PENDING
+func frozenSubmissions(event models.Event, until time.Time) (visible, hidden []models.Submission, err error) {
+	if err = database.DB.Preload("User").Preload("Team").Preload("Flags").
+		Where("event_id = ? AND created_at <= ? AND virtual_id IS NULL", event.ID, *event.FreezeTime).
+		Order("created_at asc").
+		Find(&visible).Error; err != nil {
+		return nil, nil, err
+	}
+	if err = database.DB.Preload("User").Preload("Team").Preload("Flags").
+		Where("event_id = ? AND created_at > ? AND created_at <= ? AND virtual_id IS NULL", event.ID, *event.FreezeTime, until).
+		Order("created_at asc").
+		Find(&hidden).Error; err != nil {
+		return nil, nil, err
+	}
+	return visible, hidden, nil
+}
+
+// ResolveContest builds the full reveal of an ended, frozen contest
+func ResolveContest(eventID string) (*Resolution, error) {
+	var event models.Event
+	if err := database.DB.Preload("Problems").First(&event, "id = ?", eventID).Error; err != nil {
+		return nil, err
+	}
+	if event.FreezeTime == nil {
+		return nil, ErrNoFreeze
+	}
+	if event.Status != models.EventStatusEnded && time.Now().Before(event.EndTime) {
+		return nil, ErrContestNotEnded
+	}
+
+	visible, hidden, err := frozenSubmissions(event, event.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	_, initial := Resolve(event, visible, hidden, 0)
+	steps, final := Resolve(event, visible, hidden, -1)
+	return &Resolution{EventID: eventID, Initial: initial, Steps: steps, Final: final}, nil
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve_BottomUpOrder(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	event := models.Event{
+		ID:        "res",
+		StartTime: start,
+		EndTime:   at(120),
+		Problems:  []models.Problem{{ID: "B", Order: 2, Points: 100}, {ID: "A", Order: 1, Points: 100}},
+	}
+	visible := []models.Submission{
+		{UserID: "alice", ProblemID: "A", Status: models.SubStatusAC, CreatedAt: at(10)},
+	}
+	hidden := []models.Submission{
+		{UserID: "bob", ProblemID: "B", Status: models.SubStatusAC, CreatedAt: at(95)},
+		{UserID: "alice", ProblemID: "B", Status: models.SubStatusWA, CreatedAt: at(100)},
+		{UserID: "bob", ProblemID: "A", Status: models.SubStatusAC, CreatedAt: at(105)},
+	}
+
+	_, initial := Resolve(event, visible, hidden, 0)
+	require.Len(t, initial, 2)
+	assert.Equal(t, "bob", initial[1].UserID)
+	assert.Equal(t, 0, initial[1].SolvedCount)
+
+	steps, final := Resolve(event, visible, hidden, -1)
+	require.Len(t, steps, 3)
+
+	// bob is last, so his cells go first, in problem order
+	assert.Equal(t, "bob", steps[0].UserID)
+	assert.Equal(t, "A", steps[0].ProblemID)
+	assert.Equal(t, "PENDING", steps[0].Before.Status)
+	assert.Equal(t, string(models.SubStatusAC), steps[0].After.Status)
+	assert.Equal(t, 2, steps[0].RankAfter, "one solve at a later time keeps bob second")
+
+	assert.Equal(t, "B", steps[1].ProblemID)
+	assert.Equal(t, 2, steps[1].RankBefore)
+	assert.Equal(t, 1, steps[1].RankAfter)
+
+	// alice has dropped to the bottom and is revealed last
+	assert.Equal(t, "alice", steps[2].UserID)
+	assert.Equal(t, string(models.SubStatusWA), steps[2].After.Status)
+
+	all := append(append([]models.Submission{}, visible...), hidden...)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].CreatedAt.Before(all[j].CreatedAt) })
+	assert.Equal(t, ComputeStandings(event, all), final, "the reveal ends on the unfrozen standings")
+
+	partial, board := Resolve(event, visible, hidden, 2)
+	assert.Len(t, partial, 2)
+	assert.Equal(t, "PENDING", board[1].Problems["B"].Status, "alice's cell is still hidden")
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrItemUnavailable = errors.New("item is not for sale")
+	ErrItemLevelLocked = errors.New("your level is too low for this item")
+	ErrItemOwned       = errors.New("item already unlocked")
+)
+
+// PurchaseStoreItem unlocks a catalog item for the user, charging its catalog price
+// through the XP ledger. Power-ups are consumed rather than owned and can be bought again.
+func PurchaseStoreItem(db *gorm.DB, userID, itemID string, now time.Time) (*models.User, *models.StoreItem, error) {
+	var user models.User
+	var item models.StoreItem
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&item, "id = ?", itemID).Error; err != nil {
+			return err
+		}
+		if !item.ForSale(now) {
+			return ErrItemUnavailable
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+		if user.Level < item.MinLevel {
+			return ErrItemLevelLocked
+		}
+		if item.Category == models.StoreCategoryPowerup {
+			if err := buyPowerup(tx, user.ID, &item); err != nil {
+				return err
+			}
+			return tx.First(&user, "id = ?", userID).Error
+		}
+		if slices.Contains(user.PurchasedComponentIds, item.ID) {
+			return ErrItemOwned
+		}
+
+		if item.Price > 0 {
+			// Keyed per purchase, so buying again after a revoke or refund is charged
+			var bought int64
+			if err := tx.Model(&models.XPLedgerEntry{}).
+				Where("user_id = ? AND source = ? AND reference_type = ? AND reference_id = ?",
+					user.ID, models.XPSourcePurchase, "store_item", item.ID).
+				Count(&bought).Error; err != nil {
+				return err
+			}
+			entry, err := ApplyXP(tx, XPChange{
+				UserID:         user.ID,
+				Amount:         -item.Price,
+				Source:         models.XPSourcePurchase,
+				ReferenceType:  "store_item",
+				ReferenceID:    item.ID,
+				IdempotencyKey: "purchase:" + user.ID + ":" + item.ID + ":" + strconv.FormatInt(bought+1, 10),
+			})
+			if err != nil {
+				return err
+			}
+			user.XP, user.Level = entry.BalanceAfter, entry.LevelAfter
+		}
+		user.PurchasedComponentIds = append(user.PurchasedComponentIds, item.ID)
+		return tx.Model(&user).Update("purchased_component_ids", user.PurchasedComponentIds).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &user, &item, nil
+}
+
+// buyPowerup grants one consumable item
+func buyPowerup(tx *gorm.DB, userID string, item *models.StoreItem) error {
+	switch item.ID {
+	case models.StreakFreezeItemID:
+		return buyStreakFreeze(tx, userID, item.Price)
+	default:
+		return ErrItemUnavailable // A power-up nothing knows how to grant
+	}
+}
+
+// revokeStoreItem takes back an item whose purchase was refunded: an unlock is removed
+// and unequipped, a power-up loses one unused charge if any are left
+func revokeStoreItem(tx *gorm.DB, userID, itemID string) error {
+	if itemID == models.StreakFreezeItemID {
+		return tx.Model(&models.DailyStreak{}).Where("user_id = ? AND freezes > 0", userID).
+			Update("freezes", gorm.Expr("freezes - 1")).Error
+	}
+
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "purchased_component_ids", "equippedAura", "equippedTheme").
+		First(&user, "id = ?", userID).Error; err != nil {
+		return err
+	}
+	updates := map[string]interface{}{
+		"purchased_component_ids": slices.DeleteFunc(user.PurchasedComponentIds, func(id string) bool { return id == itemID }),
+	}
+	if user.EquippedAura == itemID {
+		updates["equippedAura"] = ""
+	}
+	if user.EquippedTheme == itemID {
+		updates["equippedTheme"] = ""
+	}
+	return tx.Model(&user).Updates(updates).Error
+}
+
+// SeedStoreCatalog creates the items the backend itself grants, if missing
+func SeedStoreCatalog(db *gorm.DB) error {
+	now := time.Now()
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.StoreItem{
+		ID:          models.StreakFreezeItemID,
+		Name:        "Streak Freeze",
+		Description: "Keeps your daily problem streak alive through one missed day",
+		Category:    models.StoreCategoryPowerup,
+		Price:       200,
+		Enabled:     true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}).Error
+}
+
+// OwnsStoreItem reports whether the user unlocked a catalog item of the given category.
+// Legacy items only have a guessed category, so any category matches them.
+func OwnsStoreItem(db *gorm.DB, user *models.User, itemID string, category models.StoreCategory) bool {
+	if !slices.Contains(user.PurchasedComponentIds, itemID) {
+		return false
+	}
+	var item models.StoreItem
+	if err := db.Select("id", "category", "legacy").First(&item, "id = ?", itemID).Error; err != nil {
+		return false
+	}
+	return item.Category == category || item.Legacy
+}
+
+// BackfillStoreCatalog adds a catalog entry, not for sale, for every item users unlocked
+// before the catalog existed, so their purchases stay equippable. Items worn as an aura
+// or theme get that category; the rest are components until an admin says otherwise, and
+// stay equippable as any category meanwhile (see StoreItem.Legacy).
+func BackfillStoreCatalog(db *gorm.DB) error {
+	var users []models.User
+	if err := db.Select("id", "purchased_component_ids", "equippedAura", "equippedTheme").
+		Where("purchased_component_ids IS NOT NULL").Find(&users).Error; err != nil {
+		return err
+	}
+
+	categories := map[string]models.StoreCategory{}
+	for _, u := range users {
+		for _, id := range u.PurchasedComponentIds {
+			if _, seen := categories[id]; !seen {
+				categories[id] = models.StoreCategoryComponent
+			}
+		}
+	}
+	for _, u := range users {
+		if _, ok := categories[u.EquippedAura]; ok {
+			categories[u.EquippedAura] = models.StoreCategoryAura
+		}
+		if _, ok := categories[u.EquippedTheme]; ok {
+			categories[u.EquippedTheme] = models.StoreCategoryTheme
+		}
+	}
+	if len(categories) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(categories))
+	for id := range categories {
+		ids = append(ids, id)
+	}
+	var known []string
+	if err := db.Model(&models.StoreItem{}).Where("id IN ?", ids).Pluck("id", &known).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var items []models.StoreItem
+	for _, id := range ids {
+		if slices.Contains(known, id) || id == "" {
+			continue
+		}
+		items = append(items, models.StoreItem{
+			ID: id, Name: id, Category: categories[id], Enabled: false, Legacy: true, CreatedAt: now, UpdatedAt: now,
+		})
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return db.Create(&items).Error
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurchaseStoreItem_ChargesCatalogPrice(t *testing.T) {
+	db := setupTestDB(t)
+	db.Create(&models.User{ID: "buyer", Username: "buyer", Email: "buyer@example.com", XP: 1500, Level: 2})
+
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	db.Create(&[]models.StoreItem{
+		{ID: "aura-neon", Name: "Neon", Category: models.StoreCategoryAura, Price: 600, Enabled: true},
+		{ID: "aura-gold", Name: "Gold", Category: models.StoreCategoryAura, Price: 100, MinLevel: 5, Enabled: true},
+		{ID: "aura-summer", Name: "Summer", Category: models.StoreCategoryAura, Price: 100, AvailableUntil: &past, Enabled: true},
+		{ID: "theme-dark", Name: "Dark", Category: models.StoreCategoryTheme, Price: 2000, Enabled: true},
+	})
+
+	user, item, err := PurchaseStoreItem(db, "buyer", "aura-neon", now)
+	require.NoError(t, err)
+	assert.Equal(t, 600, item.Price)
+	assert.Equal(t, 900, user.XP)
+	assert.Contains(t, []string(user.PurchasedComponentIds), "aura-neon")
+	assert.True(t, OwnsStoreItem(db, user, "aura-neon", models.StoreCategoryAura))
+	assert.False(t, OwnsStoreItem(db, user, "aura-neon", models.StoreCategoryTheme))
+
+	_, _, err = PurchaseStoreItem(db, "buyer", "aura-neon", now)
+	assert.ErrorIs(t, err, ErrItemOwned)
+	_, _, err = PurchaseStoreItem(db, "buyer", "aura-gold", now)
+	assert.ErrorIs(t, err, ErrItemLevelLocked)
+	_, _, err = PurchaseStoreItem(db, "buyer", "aura-summer", now)
+	assert.ErrorIs(t, err, ErrItemUnavailable)
+	_, _, err = PurchaseStoreItem(db, "buyer", "theme-dark", now)
+	assert.ErrorIs(t, err, ErrInsufficientXP)
+
+	var stored models.User
+	db.First(&stored, "id = ?", "buyer")
+	assert.Equal(t, 900, stored.XP)
+	assert.Equal(t, []string{"aura-neon"}, []string(stored.PurchasedComponentIds))
+}
+
+func TestReverseXP_RevokesPurchase(t *testing.T) {
+	db := setupTestDB(t)
+	db.Create(&models.User{ID: "buyer", Username: "buyer", Email: "buyer@example.com", XP: 1500, Level: 2})
+	db.Create(&models.StoreItem{ID: "aura-neon", Name: "Neon", Category: models.StoreCategoryAura, Price: 600, Enabled: true})
+
+	now := time.Now()
+	_, _, err := PurchaseStoreItem(db, "buyer", "aura-neon", now)
+	require.NoError(t, err)
+	db.Model(&models.User{}).Where("id = ?", "buyer").Update("equippedAura", "aura-neon")
+
+	var purchase models.XPLedgerEntry
+	require.NoError(t, db.First(&purchase, "user_id = ? AND source = ?", "buyer", models.XPSourcePurchase).Error)
+	_, err = ReverseXP(db, purchase.ID, "admin", "refund")
+	require.NoError(t, err)
+
+	// Refunded: the item is gone, not kept for free
+	var user models.User
+	db.First(&user, "id = ?", "buyer")
+	assert.Equal(t, 1500, user.XP)
+	assert.Empty(t, user.PurchasedComponentIds)
+	assert.Empty(t, user.EquippedAura)
+
+	// Buying it again is a new purchase, charged again
+	again, _, err := PurchaseStoreItem(db, "buyer", "aura-neon", now)
+	require.NoError(t, err)
+	assert.Equal(t, 900, again.XP)
+}
+
+func TestBackfillStoreCatalog(t *testing.T) {
+	db := setupTestDB(t)
+	db.Create(&models.User{ID: "owner", Username: "owner", Email: "owner@example.com",
+		PurchasedComponentIds: []string{"legacy-aura", "legacy-widget"}, EquippedAura: "legacy-aura"})
+
+	require.NoError(t, BackfillStoreCatalog(db))
+	require.NoError(t, BackfillStoreCatalog(db))
+
+	var items []models.StoreItem
+	db.Order("id asc").Find(&items)
+	require.Len(t, items, 2)
+	assert.Equal(t, models.StoreCategoryAura, items[0].Category)
+	assert.Equal(t, models.StoreCategoryComponent, items[1].Category)
+	assert.False(t, items[0].Enabled)
+
+	// Never equipped, so filed as a component, but it's still the aura they paid for
+	var owner models.User
+	db.First(&owner, "id = ?", "owner")
+	assert.True(t, OwnsStoreItem(db, &owner, "legacy-widget", models.StoreCategoryAura))
+
+	// A catalog item an admin made free and took off sale keeps its category
+	db.Create(&models.StoreItem{ID: "retired-theme", Name: "Retired", Category: models.StoreCategoryTheme, Enabled: false})
+	owner.PurchasedComponentIds = append(owner.PurchasedComponentIds, "retired-theme")
+	assert.True(t, OwnsStoreItem(db, &owner, "retired-theme", models.StoreCategoryTheme))
+	assert.False(t, OwnsStoreItem(db, &owner, "retired-theme", models.StoreCategoryAura))
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/database"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestDB opens a fresh in-memory database with every model the services touch and
+// installs it as database.DB for the duration of the test
+func setupTestDB(t *testing.T) *gorm.DB {
+	dsn := fmt.Sprintf("file:%s_%d?mode=memory&cache=shared", t.Name(), time.Now().UnixNano()) // Fresh DB per run
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&models.User{}, &models.Event{}, &models.Problem{}, &models.Submission{}, &models.SubmissionFlag{},
+		&models.Registration{}, &models.AdminAuditLog{}, &models.SchedulerLease{}, &models.ContestResult{}, &models.JudgeJob{},
+		&models.Team{}, &models.VirtualParticipation{}, &models.PlagiarismReport{},
+		&models.SubmissionMetrics{}, &models.AntiCheatRule{}, &models.EventAntiCheatRule{},
+		&models.XPLedgerEntry{}, &models.SystemSettings{}, &models.StoreItem{},
+		&models.Badge{}, &models.UserBadge{}, &models.UserMetric{}, &models.BadgeEvent{},
+		&models.PracticeProblem{}, &models.PracticeSubmission{}, &models.DailyProblem{}, &models.DailySolve{}, &models.DailyStreak{},
+	))
+
+	prev := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = prev })
+	return db
+}
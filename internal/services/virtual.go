@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrVirtualUnavailable  = errors.New("virtual participation is only open for ended contests")
+	ErrVirtualParticipated = errors.New("you took part in this contest")
+	ErrVirtualStarted      = errors.New("virtual participation already started")
+	ErrVirtualFrozen       = errors.New("virtual participation opens once the scoreboard is unfrozen")
+)
+
+// StartVirtual starts a user's personal clock for an ended contest. Each user gets one
+// virtual run per contest, and none if they submitted during the real one. Frozen
+// contests wait for the resolver: the ghost board would reveal the final standings.
+func StartVirtual(db *gorm.DB, eventID, userID string, now time.Time) (*models.VirtualParticipation, error) {
+	var event models.Event
+	if err := db.First(&event, "id = ?", eventID).Error; err != nil {
+		return nil, err
+	}
+	if event.ID == "practice-arena-mvp" || event.IsExternal || event.Status != models.EventStatusEnded {
+		return nil, ErrVirtualUnavailable
+	}
+	if event.FreezeTime != nil && event.UnfrozenAt == nil {
+		return nil, ErrVirtualFrozen
+	}
+
+	var official int64
+	db.Model(&models.Submission{}).
+		Where("event_id = ? AND user_id = ? AND created_at <= ? AND virtual_id IS NULL", eventID, userID, event.EndTime).
+		Count(&official)
+	if official > 0 {
+		return nil, ErrVirtualParticipated
+	}
+
+	var existing int64
+	db.Model(&models.VirtualParticipation{}).Where("event_id = ? AND user_id = ?", eventID, userID).Count(&existing)
+	if existing > 0 {
+		return nil, ErrVirtualStarted
+	}
+
+	vp := models.VirtualParticipation{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		EventID:   eventID,
+		StartedAt: now,
+		EndsAt:    now.Add(event.EndTime.Sub(event.StartTime)),
+		CreatedAt: now,
+	}
+	if err := db.Create(&vp).Error; err != nil {
+		return nil, err
+	}
+	return &vp, nil
+}
+
+// GhostLeaderboard ranks a virtual participant among the original participants as they
+// stood at the same elapsed time (now, or the end of the run). The virtual submissions
+// are moved onto the contest's clock; the row is marked Virtual.
+func GhostLeaderboard(db *gorm.DB, vp *models.VirtualParticipation, now time.Time) ([]LeaderboardEntry, time.Duration, error) {
+	var event models.Event
+	if err := db.Preload("Problems").First(&event, "id = ?", vp.EventID).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if now.After(vp.EndsAt) {
+		now = vp.EndsAt
+	}
+	elapsed := now.Sub(vp.StartedAt)
+	cutoff := vp.ContestTime(&event, now)
+
+	// The original field, without participants banned since
+	banned := db.Model(&models.Registration{}).Select("user_id").
+		Where("event_id = ? AND status = ?", event.ID, models.RegStatusBanned)
+	var submissions []models.Submission
+	if err := db.Preload("User").Preload("Team").Preload("Flags").
+		Where("event_id = ? AND created_at <= ? AND virtual_id IS NULL AND user_id NOT IN (?)", event.ID, cutoff, banned).
+		Find(&submissions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var virtual []models.Submission
+	if err := db.Preload("User").Preload("Flags").
+		Where("virtual_id = ? AND created_at <= ?", vp.ID, now).
+		Find(&virtual).Error; err != nil {
+		return nil, 0, err
+	}
+	for _, sub := range virtual {
+		sub.CreatedAt = vp.ContestTime(&event, sub.CreatedAt)
+		submissions = append(submissions, sub)
+	}
+	sort.SliceStable(submissions, func(i, j int) bool { return submissions[i].CreatedAt.Before(submissions[j].CreatedAt) })
+
+	standings := ComputeStandings(event, submissions)
+	for i := range standings {
+		if standings[i].UserID == vp.UserID && standings[i].TeamID == "" {
+			standings[i].Virtual = true
+		}
+	}
+	return standings, elapsed, nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGhostLeaderboard_SameElapsedTime(t *testing.T) {
+	db := setupTestDB(t)
+
+	now := time.Now()
+	start := now.Add(-5 * time.Hour)
+	db.Create(&models.Event{ID: "vc", Slug: "vc", Status: models.EventStatusEnded,
+		StartTime: start, EndTime: start.Add(2 * time.Hour)})
+	db.Create(&models.Problem{ID: "vc-a", EventID: "vc", Points: 100})
+	for _, id := range []string{"alice", "bob", "carol"} {
+		db.Create(&models.User{ID: id, Username: id, Email: id + "@example.com", TrustScore: 100})
+	}
+	for id, minutes := range map[string]int{"alice": 10, "bob": 90} {
+		db.Create(&models.Submission{ID: "vc-" + id, UserID: id, EventID: "vc", ProblemID: "vc-a",
+			Status: models.SubStatusAC, Score: 100, CreatedAt: start.Add(time.Duration(minutes) * time.Minute)})
+	}
+
+	_, err := StartVirtual(db, "vc", "alice", now)
+	assert.ErrorIs(t, err, ErrVirtualParticipated)
+
+	vp, err := StartVirtual(db, "vc", "carol", now.Add(-30*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, vp.EndsAt.Sub(vp.StartedAt))
+	_, err = StartVirtual(db, "vc", "carol", now)
+	assert.ErrorIs(t, err, ErrVirtualStarted)
+
+	db.Create(&models.Submission{ID: "vc-carol", UserID: "carol", EventID: "vc", ProblemID: "vc-a", VirtualID: &vp.ID,
+		Status: models.SubStatusAC, Score: 100, CreatedAt: vp.StartedAt.Add(20 * time.Minute)})
+
+	// 30 minutes in: alice (10') is ahead, bob hasn't solved yet
+	board, elapsed, err := GhostLeaderboard(db, vp, now)
+	require.NoError(t, err)
+	assert.InDelta(t, 30, elapsed.Minutes(), 0.01)
+	require.Len(t, board, 2)
+	assert.Equal(t, "alice", board[0].UserID)
+	assert.Equal(t, "carol", board[1].UserID)
+	assert.True(t, board[1].Virtual)
+	assert.InDelta(t, 20, board[1].TotalTime, 0.01)
+
+	// After the run everyone is in
+	board, _, err = GhostLeaderboard(db, vp, now.Add(3*time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, board, 3)
+
+	// The official standings never include virtual submissions
+	official, err := GetLeaderboard("vc", true)
+	require.NoError(t, err)
+	for _, entry := range official {
+		assert.NotEqual(t, "carol", entry.UserID)
+	}
+}
+
+func TestStartVirtual_WaitsForUnfreeze(t *testing.T) {
+	db := setupTestDB(t)
+
+	now := time.Now()
+	start := now.Add(-5 * time.Hour)
+	freeze := start.Add(90 * time.Minute)
+	db.Create(&models.Event{ID: "vf", Slug: "vf", Status: models.EventStatusEnded,
+		StartTime: start, FreezeTime: &freeze, EndTime: start.Add(2 * time.Hour)})
+
+	_, err := StartVirtual(db, "vf", "dave", now)
+	assert.ErrorIs(t, err, ErrVirtualFrozen)
+
+	db.Model(&models.Event{}).Where("id = ?", "vf").Update("unfrozen_at", now)
+	_, err = StartVirtual(db, "vf", "dave", now)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInsufficientXP    = errors.New("insufficient XP balance")
+	ErrXPAlreadyReversed = errors.New("entry already reversed")
+	ErrXPNotReversible   = errors.New("reversal entries cannot be reversed")
+)
+
+// XPChange describes one ledger entry to apply
+type XPChange struct {
+	UserID        string
+	Amount        int // Positive to credit, negative to debit
+	Source        models.XPSource
+	ReferenceType string
+	ReferenceID   string
+	Reason        string
+	CreatedBy     string
+
+	// IdempotencyKey makes retries safe: a key seen before returns its entry unchanged
+	IdempotencyKey string
+
+	// Clamp turns a debit beyond the balance into one that empties it, instead of
+	// failing with ErrInsufficientXP (admin deductions and reversals)
+	Clamp bool
+}
+
+// LevelCurveFromSettings returns the level curve configured in system settings, or the
+// default curve when none (or an unusable one) is set
+func LevelCurveFromSettings(db *gorm.DB) models.LevelCurve {
+	var setting models.SystemSettings
+	if err := db.Where("key = ?", models.SettingXPLevelCurve).Limit(1).Find(&setting).Error; err != nil || setting.Value == "" {
+		return models.DefaultLevelCurve
+	}
+	curve, err := ParseLevelCurve(setting.Value)
+	if err != nil {
+		return models.DefaultLevelCurve
+	}
+	return curve
+}
+
+// ParseLevelCurve parses and validates a level curve setting
+func ParseLevelCurve(value string) (models.LevelCurve, error) {
+	var curve models.LevelCurve
+	if err := json.Unmarshal([]byte(value), &curve); err != nil {
+		return curve, err
+	}
+	if !curve.Valid() {
+		return curve, errors.New("level curve needs base > 0 and growth between 1 and 3")
+	}
+	return curve, nil
+}
+
+// ApplyXP appends a ledger entry and moves the user's XP and level with it in one
+// transaction, holding the user's row so concurrent changes can't overspend
+func ApplyXP(db *gorm.DB, change XPChange) (*models.XPLedgerEntry, error) {
+	var key *string
+	if change.IdempotencyKey != "" {
+		key = &change.IdempotencyKey
+	}
+
+	var entry models.XPLedgerEntry
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if key != nil {
+			err := tx.Where("idempotency_key = ?", *key).First(&entry).Error
+			if err == nil {
+				return nil
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "xp", "level").
+			First(&user, "id = ?", change.UserID).Error; err != nil {
+			return err
+		}
+
+		amount := change.Amount
+		balance := user.XP + amount
+		if balance < 0 {
+			if !change.Clamp {
+				return ErrInsufficientXP
+			}
+			amount, balance = -user.XP, 0
+		}
+		level := LevelCurveFromSettings(tx).LevelFor(balance)
+
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
+			Updates(map[string]interface{}{"xp": balance, "level": level}).Error; err != nil {
+			return err
+		}
+
+		entry = models.XPLedgerEntry{
+			ID:             uuid.New().String(),
+			UserID:         user.ID,
+			Source:         change.Source,
+			Amount:         amount,
+			BalanceAfter:   balance,
+			LevelAfter:     level,
+			ReferenceType:  change.ReferenceType,
+			ReferenceID:    change.ReferenceID,
+			Reason:         change.Reason,
+			IdempotencyKey: key,
+			CreatedBy:      change.CreatedBy,
+			CreatedAt:      time.Now(),
+		}
+		return tx.Create(&entry).Error
+	})
+	if err != nil && key != nil {
+		// Lost a race on the same key: the winner's entry is the result
+		var existing models.XPLedgerEntry
+		if db.Where("idempotency_key = ?", *key).First(&existing).Error == nil {
+			return &existing, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// ReverseXP undoes a ledger entry with an opposite one. Reversing a credit the user has
+// already spent takes what is left; reversing a store purchase takes the item back.
+func ReverseXP(db *gorm.DB, entryID, adminID, reason string) (*models.XPLedgerEntry, error) {
+	var reversal *models.XPLedgerEntry
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var original models.XPLedgerEntry
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&original, "id = ?", entryID).Error; err != nil {
+			return err
+		}
+		if original.ReversedByID != nil {
+			return ErrXPAlreadyReversed
+		}
+		if original.Source == models.XPSourceReversal {
+			return ErrXPNotReversible
+		}
+
+		var err error
+		reversal, err = ApplyXP(tx, XPChange{
+			UserID:         original.UserID,
+			Amount:         -original.Amount,
+			Source:         models.XPSourceReversal,
+			ReferenceType:  "xp_entry",
+			ReferenceID:    original.ID,
+			Reason:         reason,
+			CreatedBy:      adminID,
+			IdempotencyKey: "reversal:" + original.ID,
+			Clamp:          true,
+		})
+		if err != nil {
+			return err
+		}
+		if original.Source == models.XPSourcePurchase && original.ReferenceType == "store_item" {
+			if err := revokeStoreItem(tx, original.UserID, original.ReferenceID); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&original).Update("reversed_by_id", reversal.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reversal, nil
+}
+
+// RecomputeLevels re-derives every user's level after the level curve changes
+func RecomputeLevels(db *gorm.DB) (int, error) {
+	curve := LevelCurveFromSettings(db)
+	changed := 0
+	var batch []models.User
+	err := db.Model(&models.User{}).Select("id", "xp", "level").FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+		for _, u := range batch {
+			if level := curve.LevelFor(u.XP); level != u.Level {
+				if err := db.Model(&models.User{}).Where("id = ?", u.ID).Update("level", level).Error; err != nil {
+					return err
+				}
+				changed++
+			}
+		}
+		return nil
+	}).Error
+	return changed, err
+}
+
+// BackfillXPLedger gives every user with XP but no ledger history an opening entry for
+// their balance, so the ledger sums to User.XP from the start
+func BackfillXPLedger(db *gorm.DB) error {
+	withLedger := db.Model(&models.XPLedgerEntry{}).Select("user_id")
+	var users []models.User
+	if err := db.Select("id", "xp", "level").Where("xp > 0 AND id NOT IN (?)", withLedger).Find(&users).Error; err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	entries := make([]models.XPLedgerEntry, len(users))
+	for i, u := range users {
+		key := "opening:" + u.ID
+		entries[i] = models.XPLedgerEntry{
+			ID:             uuid.New().String(),
+			UserID:         u.ID,
+			Source:         models.XPSourceOpeningBalance,
+			Amount:         u.XP,
+			BalanceAfter:   u.XP,
+			LevelAfter:     u.Level,
+			IdempotencyKey: &key,
+			CreatedAt:      now,
+		}
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&entries, 500).Error
+}
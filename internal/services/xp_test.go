@@ -0,0 +1,73 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/pushp314/devconnect-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelCurve(t *testing.T) {
+	flat := models.DefaultLevelCurve
+	assert.Equal(t, 1, flat.LevelFor(0))
+	assert.Equal(t, 1, flat.LevelFor(999))
+	assert.Equal(t, 2, flat.LevelFor(1000))
+	assert.Equal(t, 3000, flat.MinXP(4))
+
+	// 100, 200, 400, ... XP per level
+	steep := models.LevelCurve{Base: 100, Growth: 2}
+	assert.Equal(t, 0, steep.MinXP(1))
+	assert.Equal(t, 700, steep.MinXP(4))
+	assert.Equal(t, 3, steep.LevelFor(699))
+	assert.Equal(t, 4, steep.LevelFor(700))
+	for level := 1; level < 20; level++ {
+		assert.Equal(t, level, steep.LevelFor(steep.MinXP(level)))
+	}
+
+	_, err := ParseLevelCurve(`{"base": 0, "growth": 1}`)
+	assert.Error(t, err)
+}
+
+func TestApplyXP_LedgerAndReversal(t *testing.T) {
+	db := setupTestDB(t)
+	db.Create(&models.User{ID: "xp-user", Username: "xp-user", Email: "xp@example.com", XP: 0, Level: 1})
+	db.Create(&models.SystemSettings{Key: models.SettingXPLevelCurve, Value: `{"base": 100, "growth": 2}`})
+
+	grant := XPChange{UserID: "xp-user", Amount: 350, Source: models.XPSourceSnippet, ReferenceID: "s1", IdempotencyKey: "snippet:s1"}
+	first, err := ApplyXP(db, grant)
+	require.NoError(t, err)
+	assert.Equal(t, 350, first.BalanceAfter)
+	assert.Equal(t, 3, first.LevelAfter)
+
+	// A retry with the same key changes nothing
+	again, err := ApplyXP(db, grant)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, again.ID)
+
+	_, err = ApplyXP(db, XPChange{UserID: "xp-user", Amount: -400, Source: models.XPSourcePurchase})
+	assert.ErrorIs(t, err, ErrInsufficientXP)
+
+	spend, err := ApplyXP(db, XPChange{UserID: "xp-user", Amount: -300, Source: models.XPSourcePurchase})
+	require.NoError(t, err)
+	assert.Equal(t, 50, spend.BalanceAfter)
+	assert.Equal(t, 1, spend.LevelAfter)
+
+	// Reversing the spent grant takes what is left
+	reversal, err := ReverseXP(db, first.ID, "admin", "fraud")
+	require.NoError(t, err)
+	assert.Equal(t, -50, reversal.Amount)
+	_, err = ReverseXP(db, first.ID, "admin", "again")
+	assert.ErrorIs(t, err, ErrXPAlreadyReversed)
+	_, err = ReverseXP(db, reversal.ID, "admin", "undo")
+	assert.ErrorIs(t, err, ErrXPNotReversible)
+
+	var user models.User
+	db.First(&user, "id = ?", "xp-user")
+	assert.Equal(t, 0, user.XP)
+	assert.Equal(t, 1, user.Level)
+
+	var sum int
+	db.Model(&models.XPLedgerEntry{}).Where("user_id = ?", "xp-user").Select("COALESCE(SUM(amount), 0)").Scan(&sum)
+	assert.Equal(t, user.XP, sum)
+}
@@ -76,6 +76,8 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		&models.Snippet{},
 		&models.UserBadge{},
 		&models.Badge{},
+		&models.UserMetric{},
+		&models.BadgeEvent{},
 		&models.SystemSettings{},
 		&models.FeedbackMessage{}, // Added model
 	)